@@ -0,0 +1,20 @@
+// Package apitest holds helpers shared by the handler tests.
+package apitest
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+// Decode unmarshals the JSON body of w, failing the test if it is not
+// a T.
+func Decode[T any](t testing.TB, w *httptest.ResponseRecorder) T {
+	t.Helper()
+
+	var v T
+	if err := json.Unmarshal(w.Body.Bytes(), &v); err != nil {
+		t.Fatalf("decode %q: %v", w.Body.String(), err)
+	}
+	return v
+}
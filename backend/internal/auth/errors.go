@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bugii1995/backend/internal/httperr"
+	"github.com/bugii1995/backend/internal/users"
+)
+
+var (
+	ErrUnauthenticated    = errors.New("authentication required")
+	ErrInvalidToken       = errors.New("invalid or expired token")
+	ErrInvalidCredentials = errors.New("invalid phone number or password")
+	ErrForbidden          = errors.New("you do not have access to this resource")
+)
+
+// HTTPErrors maps auth and account errors for httperr.Middleware.
+var HTTPErrors = []httperr.Rule{
+	{Err: ErrUnauthenticated, Status: http.StatusUnauthorized, Code: "unauthenticated"},
+	{Err: ErrInvalidToken, Status: http.StatusUnauthorized, Code: "invalid_token"},
+	{Err: ErrInvalidCredentials, Status: http.StatusUnauthorized, Code: "invalid_credentials"},
+	{Err: ErrForbidden, Status: http.StatusForbidden, Code: "forbidden"},
+	{Err: users.ErrInvalidPhone, Status: http.StatusUnprocessableEntity, Code: "invalid_phone"},
+	{Err: users.ErrWeakPassword, Status: http.StatusUnprocessableEntity, Code: "weak_password"},
+	{Err: users.ErrPasswordTooLong, Status: http.StatusUnprocessableEntity, Code: "password_too_long"},
+	{Err: users.ErrPhoneTaken, Status: http.StatusConflict, Code: "phone_taken"},
+	{Err: ErrOTPInvalid, Status: http.StatusUnauthorized, Code: "invalid_code"},
+	{Err: ErrOTPExpired, Status: http.StatusUnauthorized, Code: "code_expired"},
+	{Err: ErrOTPAttemptsExceeded, Status: http.StatusTooManyRequests, Code: "too_many_attempts"},
+	{Err: ErrOTPRateLimited, Status: http.StatusTooManyRequests, Code: "rate_limited"},
+}
@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bugii1995/backend/internal/httperr"
+	"github.com/bugii1995/backend/internal/users"
+)
+
+// ---------------- Handler ----------------
+
+// Handler serves the /auth endpoints.
+type Handler struct {
+	Users  users.UserRepository
+	Tokens *TokenIssuer
+
+	// OTP serves /auth/otp/*; nil when no SMS provider is configured,
+	// in which case those routes are not registered.
+	OTP *OTPService
+}
+
+// ---------------- DTOs ----------------
+
+type CredentialsRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required"`
+	Password    string `json:"password" binding:"required"`
+}
+
+type OTPRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required"`
+}
+
+type OTPRequestResponse struct {
+	ExpiresIn int `json:"expires_in"` // seconds
+}
+
+type OTPVerifyRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required"`
+	Code        string `json:"code" binding:"required"`
+}
+
+type TokenResponse struct {
+	Token     string     `json:"token"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	User      users.User `json:"user"`
+}
+
+// dummyHash keeps login timing the same for unknown phone numbers and
+// accounts without a password.
+var dummyHash, _ = users.HashPassword("not-a-real-password")
+
+// checkPassword is users.CheckPassword, but always pays for a bcrypt
+// comparison, so response timing does not reveal which accounts exist
+// or have a password.
+func checkPassword(hash, password string) bool {
+	if hash == "" {
+		users.CheckPassword(dummyHash, password)
+		return false
+	}
+	return users.CheckPassword(hash, password)
+}
+
+// ---------------- Handlers ----------------
+
+// POST /auth/register
+func (h *Handler) Register(c *gin.Context) {
+	var req CredentialsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(httperr.BadRequest(err))
+		return
+	}
+
+	if !users.IsValidMongoliaPhone(req.PhoneNumber) {
+		c.Error(users.ErrInvalidPhone)
+		return
+	}
+
+	hash, err := users.HashPassword(req.Password)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	user := users.NewUser(req.PhoneNumber)
+	user.PasswordHash = hash
+
+	user, err = h.Users.Create(c.Request.Context(), user)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.respondWithToken(c, http.StatusCreated, user)
+}
+
+// POST /auth/login
+func (h *Handler) Login(c *gin.Context) {
+	var req CredentialsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(httperr.BadRequest(err))
+		return
+	}
+
+	user, err := h.Users.GetByPhone(c.Request.Context(), req.PhoneNumber)
+	if errors.Is(err, users.ErrUserNotFound) {
+		checkPassword("", req.Password)
+		c.Error(ErrInvalidCredentials)
+		return
+	}
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if !checkPassword(user.PasswordHash, req.Password) {
+		c.Error(ErrInvalidCredentials)
+		return
+	}
+
+	h.respondWithToken(c, http.StatusOK, user)
+}
+
+// POST /auth/otp/request
+func (h *Handler) RequestOTP(c *gin.Context) {
+	var req OTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(httperr.BadRequest(err))
+		return
+	}
+
+	if !users.IsValidMongoliaPhone(req.PhoneNumber) {
+		c.Error(users.ErrInvalidPhone)
+		return
+	}
+
+	if err := h.OTP.Request(c.Request.Context(), req.PhoneNumber, c.ClientIP()); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, OTPRequestResponse{
+		ExpiresIn: int(OTPTTL.Seconds()),
+	})
+}
+
+// POST /auth/otp/verify
+//
+// A verified phone number without an account gets one, so learners
+// can sign up without ever choosing a password.
+func (h *Handler) VerifyOTP(c *gin.Context) {
+	var req OTPVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(httperr.BadRequest(err))
+		return
+	}
+
+	if err := h.OTP.Verify(c.Request.Context(), req.PhoneNumber, req.Code); err != nil {
+		c.Error(err)
+		return
+	}
+
+	user, err := h.Users.GetByPhone(c.Request.Context(), req.PhoneNumber)
+	if errors.Is(err, users.ErrUserNotFound) {
+		user, err = h.Users.Create(c.Request.Context(), users.NewUser(req.PhoneNumber))
+	}
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.respondWithToken(c, http.StatusOK, user)
+}
+
+func (h *Handler) respondWithToken(c *gin.Context, status int, user users.User) {
+	token, expiresAt, err := h.Tokens.Issue(user.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(status, TokenResponse{
+		Token:     token,
+		ExpiresAt: expiresAt,
+		User:      user,
+	})
+}
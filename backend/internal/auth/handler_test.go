@@ -0,0 +1,215 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bugii1995/backend/internal/apitest"
+	"github.com/bugii1995/backend/internal/httperr"
+	"github.com/bugii1995/backend/internal/users"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+type testServer struct {
+	router  *gin.Engine
+	handler *Handler
+	users   *users.MemoryUserRepository
+	tokens  *TokenIssuer
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	s := &testServer{
+		users:  users.NewMemoryUserRepository(),
+		tokens: NewTokenIssuer([]byte("test-secret"), time.Hour),
+	}
+	h := &Handler{Users: s.users, Tokens: s.tokens}
+	s.handler = h
+
+	r := gin.New()
+	r.Use(httperr.Middleware(HTTPErrors...))
+	r.POST("/auth/register", h.Register)
+	r.POST("/auth/login", h.Login)
+	r.POST("/auth/otp/request", h.RequestOTP)
+	r.POST("/auth/otp/verify", h.VerifyOTP)
+	r.GET("/me", RequireUser(s.tokens, s.users), func(c *gin.Context) {
+		u, _ := CurrentUser(c)
+		c.JSON(http.StatusOK, u)
+	})
+
+	s.router = r
+	return s
+}
+
+func (s *testServer) do(t *testing.T, method, path, token string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	s := newTestServer(t)
+	creds := CredentialsRequest{PhoneNumber: "+97688110001", Password: "hunter2hunter2"}
+
+	w := s.do(t, http.MethodPost, "/auth/register", "", creds)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register: %d %s", w.Code, w.Body.String())
+	}
+	registered := apitest.Decode[TokenResponse](t, w)
+
+	if registered.User.Role != users.RoleStudent || registered.User.AccountType != users.AccountFree {
+		t.Fatalf("expected a free student account, got %+v", registered.User)
+	}
+	if bytes.Contains(w.Body.Bytes(), []byte("$2a$")) {
+		t.Fatal("password hash must not be returned")
+	}
+
+	stored, _ := s.users.GetByPhone(t.Context(), creds.PhoneNumber)
+	if stored.PasswordHash == "" || stored.PasswordHash == creds.Password {
+		t.Fatal("expected a hashed password to be stored")
+	}
+
+	w = s.do(t, http.MethodPost, "/auth/login", "", creds)
+	if w.Code != http.StatusOK {
+		t.Fatalf("login: %d %s", w.Code, w.Body.String())
+	}
+	login := apitest.Decode[TokenResponse](t, w)
+
+	w = s.do(t, http.MethodGet, "/me", login.Token, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("me: %d %s", w.Code, w.Body.String())
+	}
+	if me := apitest.Decode[users.User](t, w); me.ID != registered.User.ID {
+		t.Fatalf("expected user %d, got %d", registered.User.ID, me.ID)
+	}
+}
+
+func TestRegisterValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       any
+		wantStatus int
+		wantCode   string
+	}{
+		{"missing fields", map[string]string{}, http.StatusBadRequest, "invalid_request"},
+		{"foreign phone", CredentialsRequest{PhoneNumber: "+15551234567", Password: "hunter2hunter2"}, http.StatusUnprocessableEntity, "invalid_phone"},
+		{"short phone", CredentialsRequest{PhoneNumber: "+9768811", Password: "hunter2hunter2"}, http.StatusUnprocessableEntity, "invalid_phone"},
+		{"weak password", CredentialsRequest{PhoneNumber: "+97688110001", Password: "short"}, http.StatusUnprocessableEntity, "weak_password"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+
+			w := s.do(t, http.MethodPost, "/auth/register", "", tt.body)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if body := apitest.Decode[httperr.Envelope](t, w); body.Code != tt.wantCode {
+				t.Fatalf("expected code %q, got %+v", tt.wantCode, body)
+			}
+		})
+	}
+}
+
+func TestRegisterDuplicatePhone(t *testing.T) {
+	s := newTestServer(t)
+	creds := CredentialsRequest{PhoneNumber: "+97688110001", Password: "hunter2hunter2"}
+
+	s.do(t, http.MethodPost, "/auth/register", "", creds)
+	w := s.do(t, http.MethodPost, "/auth/register", "", creds)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestLoginRejectsBadCredentials(t *testing.T) {
+	s := newTestServer(t)
+	s.do(t, http.MethodPost, "/auth/register", "", CredentialsRequest{PhoneNumber: "+97688110001", Password: "hunter2hunter2"})
+	// Signed up by OTP, so without a password.
+	s.users.Create(context.Background(), users.NewUser("+97688110003"))
+
+	for _, creds := range []CredentialsRequest{
+		{PhoneNumber: "+97688110001", Password: "wrong-password"},
+		{PhoneNumber: "+97688110002", Password: "hunter2hunter2"},
+		{PhoneNumber: "+97688110003", Password: "hunter2hunter2"},
+	} {
+		w := s.do(t, http.MethodPost, "/auth/login", "", creds)
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("%+v: expected 401, got %d %s", creds, w.Code, w.Body.String())
+		}
+		if body := apitest.Decode[httperr.Envelope](t, w); body.Code != "invalid_credentials" {
+			t.Fatalf("unexpected body %+v", body)
+		}
+	}
+}
+
+func TestRequireUser(t *testing.T) {
+	s := newTestServer(t)
+
+	orphan, _, _ := s.tokens.Issue(999)
+	foreign, _, _ := NewTokenIssuer([]byte("other"), time.Hour).Issue(1)
+
+	tests := []struct {
+		name     string
+		token    string
+		wantCode string
+	}{
+		{"no token", "", "unauthenticated"},
+		{"garbage token", "garbage", "invalid_token"},
+		{"foreign signature", foreign, "invalid_token"},
+		{"deleted user", orphan, "invalid_token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := s.do(t, http.MethodGet, "/me", tt.token, nil)
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("expected 401, got %d %s", w.Code, w.Body.String())
+			}
+			if body := apitest.Decode[httperr.Envelope](t, w); body.Code != tt.wantCode {
+				t.Fatalf("expected code %q, got %+v", tt.wantCode, body)
+			}
+		})
+	}
+}
+
+func TestCheckPasswordWithoutHashStillCompares(t *testing.T) {
+	start := time.Now()
+	if checkPassword("", "hunter2hunter2") {
+		t.Fatal("expected no match without a hash")
+	}
+
+	// A bcrypt comparison at the default cost takes milliseconds; a
+	// skipped one takes nanoseconds.
+	if elapsed := time.Since(start); elapsed < time.Millisecond {
+		t.Fatalf("expected a dummy comparison, returned after %v", elapsed)
+	}
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bugii1995/backend/internal/users"
+)
+
+const currentUserKey = "auth.user"
+
+// RequireUser authenticates the "Authorization: Bearer <token>" header
+// and stores the user for CurrentUser. Requests without a valid token
+// are aborted with 401.
+func RequireUser(tokens *TokenIssuer, repo users.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || token == "" {
+			c.Error(ErrUnauthenticated)
+			c.Abort()
+			return
+		}
+
+		userID, err := tokens.Verify(token)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		user, err := repo.GetByID(c.Request.Context(), userID)
+		if errors.Is(err, users.ErrUserNotFound) {
+			c.Error(ErrInvalidToken)
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		SetCurrentUser(c, user)
+		c.Next()
+	}
+}
+
+// SetCurrentUser records u as the authenticated user of the request.
+func SetCurrentUser(c *gin.Context, u users.User) {
+	c.Set(currentUserKey, u)
+}
+
+// CurrentUser returns the user stored by RequireUser.
+func CurrentUser(c *gin.Context) (users.User, bool) {
+	v, ok := c.Get(currentUserKey)
+	if !ok {
+		return users.User{}, false
+	}
+	u, ok := v.(users.User)
+	return u, ok
+}
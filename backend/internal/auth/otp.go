@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/bugii1995/backend/internal/keylock"
+)
+
+const (
+	OTPLength      = 6
+	OTPTTL         = 5 * time.Minute
+	OTPMaxAttempts = 5
+
+	OTPPerPhoneLimit  = 3 // requests per OTPPerPhoneWindow
+	OTPPerPhoneWindow = 15 * time.Minute
+	OTPPerIPLimit     = 10 // requests per OTPPerIPWindow
+	OTPPerIPWindow    = time.Hour
+)
+
+var (
+	ErrOTPNotFound         = errors.New("no pending code for this phone number")
+	ErrOTPInvalid          = errors.New("invalid code")
+	ErrOTPExpired          = errors.New("code expired")
+	ErrOTPAttemptsExceeded = errors.New("too many attempts; request a new code")
+	ErrOTPRateLimited      = errors.New("too many code requests; try again later")
+)
+
+// ---------------- Storage ----------------
+
+// OTPCode is a pending one-time code. Only its HMAC is kept.
+type OTPCode struct {
+	Phone     string
+	Hash      string
+	ExpiresAt time.Time
+	Attempts  int
+}
+
+// OTPStore keeps at most one pending code per phone number.
+type OTPStore interface {
+	Put(ctx context.Context, code OTPCode) error
+	Get(ctx context.Context, phone string) (OTPCode, error)
+	Delete(ctx context.Context, phone string) error
+}
+
+type MemoryOTPStore struct {
+	mu    sync.Mutex
+	codes map[string]OTPCode
+}
+
+func NewMemoryOTPStore() *MemoryOTPStore {
+	return &MemoryOTPStore{codes: make(map[string]OTPCode)}
+}
+
+func (s *MemoryOTPStore) Put(ctx context.Context, code OTPCode) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.codes[code.Phone] = code
+	return nil
+}
+
+func (s *MemoryOTPStore) Get(ctx context.Context, phone string) (OTPCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	code, ok := s.codes[phone]
+	if !ok {
+		return OTPCode{}, ErrOTPNotFound
+	}
+	return code, nil
+}
+
+func (s *MemoryOTPStore) Delete(ctx context.Context, phone string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.codes, phone)
+	return nil
+}
+
+// ---------------- Service ----------------
+
+// OTPService issues and checks SMS one-time login codes.
+type OTPService struct {
+	// phones runs one request or check per phone number at a time, so
+	// a code's Get → check → Put cycle is atomic.
+	phones keylock.Mutex
+	store  OTPStore
+	sms    SMSSender
+	secret []byte
+
+	perPhone *RateLimiter
+	perIP    *RateLimiter
+
+	now      func() time.Time
+	generate func() (string, error)
+}
+
+// NewOTPService hashes codes with secret before storing them.
+func NewOTPService(store OTPStore, sms SMSSender, secret []byte) *OTPService {
+	return &OTPService{
+		store:    store,
+		sms:      sms,
+		secret:   secret,
+		perPhone: NewRateLimiter(OTPPerPhoneLimit, OTPPerPhoneWindow),
+		perIP:    NewRateLimiter(OTPPerIPLimit, OTPPerIPWindow),
+		now:      time.Now,
+		generate: generateOTP,
+	}
+}
+
+// Request sends a fresh code to phone, replacing any pending one.
+// phone must already be validated.
+//
+// The SMS is sent after the phone's lock is released, so a slow
+// gateway holds up only the caller.
+func (s *OTPService) Request(ctx context.Context, phone, ip string) error {
+	code, err := s.issue(ctx, phone, ip)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Your Bonfire code is %s. It expires in %d minutes.", code, int(OTPTTL.Minutes()))
+	return s.sms.Send(ctx, phone, message)
+}
+
+// issue stores a fresh code for phone and returns it. Both limits are
+// checked before either is charged, so a request refused for the phone
+// does not use up the IP's allowance.
+func (s *OTPService) issue(ctx context.Context, phone, ip string) (string, error) {
+	unlock := s.phones.Lock(phone)
+	defer unlock()
+
+	// The phone's window cannot move while its lock is held, so once the
+	// IP is charged the phone is sure to be allowed.
+	if !s.perPhone.Check(phone) || !s.perIP.Allow(ip) {
+		return "", ErrOTPRateLimited
+	}
+	s.perPhone.Allow(phone)
+
+	code, err := s.generate()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.store.Put(ctx, OTPCode{
+		Phone:     phone,
+		Hash:      s.hash(phone, code),
+		ExpiresAt: s.now().Add(OTPTTL),
+	}); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// Verify consumes the pending code for phone if code matches.
+func (s *OTPService) Verify(ctx context.Context, phone, code string) error {
+	unlock := s.phones.Lock(phone)
+	defer unlock()
+
+	pending, err := s.store.Get(ctx, phone)
+	if errors.Is(err, ErrOTPNotFound) {
+		return ErrOTPInvalid
+	}
+	if err != nil {
+		return err
+	}
+
+	if !s.now().Before(pending.ExpiresAt) {
+		s.store.Delete(ctx, phone)
+		return ErrOTPExpired
+	}
+
+	if !hmac.Equal([]byte(pending.Hash), []byte(s.hash(phone, code))) {
+		pending.Attempts++
+		if pending.Attempts >= OTPMaxAttempts {
+			s.store.Delete(ctx, phone)
+			return ErrOTPAttemptsExceeded
+		}
+		if err := s.store.Put(ctx, pending); err != nil {
+			return err
+		}
+		return ErrOTPInvalid
+	}
+
+	return s.store.Delete(ctx, phone)
+}
+
+func (s *OTPService) hash(phone, code string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(phone + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func generateOTP() (string, error) {
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(OTPLength), nil)
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", OTPLength, n.Int64()), nil
+}
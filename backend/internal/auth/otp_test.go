@@ -0,0 +1,304 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bugii1995/backend/internal/apitest"
+	"github.com/bugii1995/backend/internal/httperr"
+)
+
+const testPhone = "+97688110001"
+
+type otpFixture struct {
+	service *OTPService
+	store   *MemoryOTPStore
+	sms     *FakeSMSSender
+	now     time.Time
+}
+
+func newOTPFixture() *otpFixture {
+	f := &otpFixture{
+		store: NewMemoryOTPStore(),
+		sms:   &FakeSMSSender{},
+		now:   time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+	f.service = NewOTPService(f.store, f.sms, []byte("test-secret"))
+
+	clock := func() time.Time { return f.now }
+	f.service.now = clock
+	f.service.perPhone.now = clock
+	f.service.perIP.now = clock
+	return f
+}
+
+// lastCode extracts the code from the most recent SMS.
+func (f *otpFixture) lastCode(t *testing.T) string {
+	t.Helper()
+
+	messages := f.sms.Messages()
+	if len(messages) == 0 {
+		t.Fatal("no SMS sent")
+	}
+	for _, word := range strings.Fields(messages[len(messages)-1].Message) {
+		word = strings.TrimSuffix(word, ".")
+		if len(word) == OTPLength && strings.Trim(word, "0123456789") == "" {
+			return word
+		}
+	}
+	t.Fatalf("no code in %q", messages[len(messages)-1].Message)
+	return ""
+}
+
+func TestOTPRequestAndVerify(t *testing.T) {
+	ctx := context.Background()
+	f := newOTPFixture()
+
+	if err := f.service.Request(ctx, testPhone, "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	code := f.lastCode(t)
+
+	if msg := f.sms.Messages()[0]; msg.Phone != testPhone {
+		t.Fatalf("expected SMS to %s, got %s", testPhone, msg.Phone)
+	}
+
+	stored, err := f.store.Get(ctx, testPhone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(stored.Hash, code) {
+		t.Fatal("code must be stored hashed")
+	}
+
+	if err := f.service.Verify(ctx, testPhone, code); err != nil {
+		t.Fatalf("expected code to verify, got %v", err)
+	}
+
+	// Codes are single use.
+	if err := f.service.Verify(ctx, testPhone, code); !errors.Is(err, ErrOTPInvalid) {
+		t.Fatalf("expected reused code to fail, got %v", err)
+	}
+}
+
+func TestOTPExpires(t *testing.T) {
+	ctx := context.Background()
+	f := newOTPFixture()
+
+	f.service.Request(ctx, testPhone, "10.0.0.1")
+	code := f.lastCode(t)
+
+	f.now = f.now.Add(OTPTTL)
+	if err := f.service.Verify(ctx, testPhone, code); !errors.Is(err, ErrOTPExpired) {
+		t.Fatalf("expected ErrOTPExpired, got %v", err)
+	}
+}
+
+func TestOTPAttemptsCapped(t *testing.T) {
+	ctx := context.Background()
+	f := newOTPFixture()
+
+	f.service.Request(ctx, testPhone, "10.0.0.1")
+	code := f.lastCode(t)
+
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+
+	for i := 1; i < OTPMaxAttempts; i++ {
+		if err := f.service.Verify(ctx, testPhone, wrong); !errors.Is(err, ErrOTPInvalid) {
+			t.Fatalf("attempt %d: expected ErrOTPInvalid, got %v", i, err)
+		}
+	}
+	if err := f.service.Verify(ctx, testPhone, wrong); !errors.Is(err, ErrOTPAttemptsExceeded) {
+		t.Fatalf("expected ErrOTPAttemptsExceeded, got %v", err)
+	}
+
+	// The right code no longer works once attempts are used up.
+	if err := f.service.Verify(ctx, testPhone, code); !errors.Is(err, ErrOTPInvalid) {
+		t.Fatalf("expected burned code to fail, got %v", err)
+	}
+}
+
+func TestOTPNewRequestReplacesCode(t *testing.T) {
+	ctx := context.Background()
+	f := newOTPFixture()
+
+	codes := []string{"123456", "654321"}
+	f.service.generate = func() (string, error) {
+		code := codes[0]
+		codes = codes[1:]
+		return code, nil
+	}
+
+	f.service.Request(ctx, testPhone, "10.0.0.1")
+	f.service.Request(ctx, testPhone, "10.0.0.1")
+
+	if err := f.service.Verify(ctx, testPhone, "123456"); !errors.Is(err, ErrOTPInvalid) {
+		t.Fatalf("expected replaced code to fail, got %v", err)
+	}
+	if err := f.service.Verify(ctx, testPhone, "654321"); err != nil {
+		t.Fatalf("expected latest code to verify, got %v", err)
+	}
+}
+
+func TestOTPRateLimitPerPhone(t *testing.T) {
+	ctx := context.Background()
+	f := newOTPFixture()
+
+	for i := 0; i < OTPPerPhoneLimit; i++ {
+		ip := "10.0.0." + string(rune('1'+i))
+		if err := f.service.Request(ctx, testPhone, ip); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	if err := f.service.Request(ctx, testPhone, "10.0.0.9"); !errors.Is(err, ErrOTPRateLimited) {
+		t.Fatalf("expected ErrOTPRateLimited, got %v", err)
+	}
+
+	f.now = f.now.Add(OTPPerPhoneWindow)
+	if err := f.service.Request(ctx, testPhone, "10.0.0.9"); err != nil {
+		t.Fatalf("expected limit to reset after the window, got %v", err)
+	}
+}
+
+func TestOTPRateLimitPerIP(t *testing.T) {
+	ctx := context.Background()
+	f := newOTPFixture()
+
+	for i := 0; i < OTPPerIPLimit; i++ {
+		phone := testPhone[:len(testPhone)-2] + string(rune('0'+i/10)) + string(rune('0'+i%10))
+		if err := f.service.Request(ctx, phone, "10.0.0.1"); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	if err := f.service.Request(ctx, "+97699999999", "10.0.0.1"); !errors.Is(err, ErrOTPRateLimited) {
+		t.Fatalf("expected ErrOTPRateLimited, got %v", err)
+	}
+	if err := f.service.Request(ctx, "+97699999999", "10.0.0.2"); err != nil {
+		t.Fatalf("expected other IPs to be unaffected, got %v", err)
+	}
+}
+
+func TestOTPLoginCreatesAccount(t *testing.T) {
+	s := newTestServer(t)
+	f := newOTPFixture()
+	s.handler.OTP = f.service
+
+	w := s.do(t, http.MethodPost, "/auth/otp/request", "", OTPRequest{PhoneNumber: testPhone})
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("request: %d %s", w.Code, w.Body.String())
+	}
+
+	w = s.do(t, http.MethodPost, "/auth/otp/verify", "", OTPVerifyRequest{PhoneNumber: testPhone, Code: f.lastCode(t)})
+	if w.Code != http.StatusOK {
+		t.Fatalf("verify: %d %s", w.Code, w.Body.String())
+	}
+	resp := apitest.Decode[TokenResponse](t, w)
+
+	if resp.User.PhoneNumber != testPhone || resp.User.ID == 0 {
+		t.Fatalf("expected a new account for %s, got %+v", testPhone, resp.User)
+	}
+	if w := s.do(t, http.MethodGet, "/me", resp.Token, nil); w.Code != http.StatusOK {
+		t.Fatalf("expected token to authenticate, got %d", w.Code)
+	}
+}
+
+func TestOTPLoginReusesAccount(t *testing.T) {
+	s := newTestServer(t)
+	f := newOTPFixture()
+	s.handler.OTP = f.service
+
+	registered := apitest.Decode[TokenResponse](t, s.do(t, http.MethodPost, "/auth/register", "",
+		CredentialsRequest{PhoneNumber: testPhone, Password: "hunter2hunter2"}))
+
+	s.do(t, http.MethodPost, "/auth/otp/request", "", OTPRequest{PhoneNumber: testPhone})
+	w := s.do(t, http.MethodPost, "/auth/otp/verify", "", OTPVerifyRequest{PhoneNumber: testPhone, Code: f.lastCode(t)})
+
+	if resp := apitest.Decode[TokenResponse](t, w); resp.User.ID != registered.User.ID {
+		t.Fatalf("expected existing user %d, got %d", registered.User.ID, resp.User.ID)
+	}
+}
+
+func TestOTPHandlerErrors(t *testing.T) {
+	s := newTestServer(t)
+	f := newOTPFixture()
+	s.handler.OTP = f.service
+
+	w := s.do(t, http.MethodPost, "/auth/otp/request", "", OTPRequest{PhoneNumber: "12345"})
+	if body := apitest.Decode[httperr.Envelope](t, w); w.Code != http.StatusUnprocessableEntity || body.Code != "invalid_phone" {
+		t.Fatalf("expected invalid_phone, got %d %+v", w.Code, body)
+	}
+
+	w = s.do(t, http.MethodPost, "/auth/otp/verify", "", OTPVerifyRequest{PhoneNumber: testPhone, Code: "123456"})
+	if body := apitest.Decode[httperr.Envelope](t, w); w.Code != http.StatusUnauthorized || body.Code != "invalid_code" {
+		t.Fatalf("expected invalid_code, got %d %+v", w.Code, body)
+	}
+}
+
+func TestOTPRateLimitChecksBothBeforeCharging(t *testing.T) {
+	ctx := context.Background()
+	f := newOTPFixture()
+
+	// Use up the phone's allowance from other IPs.
+	for i := 0; i < OTPPerPhoneLimit; i++ {
+		if err := f.service.Request(ctx, testPhone, "10.0.1."+string(rune('1'+i))); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+
+	// Refused for the phone, these must not use up the IP.
+	for i := 0; i < OTPPerIPLimit; i++ {
+		if err := f.service.Request(ctx, testPhone, "10.0.0.1"); !errors.Is(err, ErrOTPRateLimited) {
+			t.Fatalf("expected ErrOTPRateLimited, got %v", err)
+		}
+	}
+	if err := f.service.Request(ctx, "+97699999999", "10.0.0.1"); err != nil {
+		t.Fatalf("expected the IP's allowance untouched, got %v", err)
+	}
+}
+
+// blockingSMSSender holds every Send for blocked until release closes.
+type blockingSMSSender struct {
+	blocked string
+	entered chan struct{}
+	release chan struct{}
+	FakeSMSSender
+}
+
+func (b *blockingSMSSender) Send(ctx context.Context, phone, message string) error {
+	if phone == b.blocked {
+		close(b.entered)
+		<-b.release
+	}
+	return b.FakeSMSSender.Send(ctx, phone, message)
+}
+
+func TestOTPRequestSendsOutsideLock(t *testing.T) {
+	ctx := context.Background()
+	sms := &blockingSMSSender{blocked: testPhone, entered: make(chan struct{}), release: make(chan struct{})}
+	service := NewOTPService(NewMemoryOTPStore(), sms, []byte("test-secret"))
+
+	done := make(chan error, 1)
+	go func() { done <- service.Request(ctx, testPhone, "10.0.0.1") }()
+	<-sms.entered
+
+	// While the first SMS hangs, the code is already verifiable and
+	// other phones are served.
+	if err := service.Request(ctx, "+97699999999", "10.0.0.2"); err != nil {
+		t.Fatalf("expected another phone served, got %v", err)
+	}
+	if err := service.Verify(ctx, testPhone, "000000"); !errors.Is(err, ErrOTPInvalid) {
+		t.Fatalf("expected ErrOTPInvalid, got %v", err)
+	}
+
+	close(sms.release)
+	if err := <-done; err != nil {
+		t.Fatalf("blocked request: %v", err)
+	}
+}
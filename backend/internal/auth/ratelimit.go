@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter allows up to limit events per key in a fixed window.
+type RateLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	windows map[string]rateWindow
+
+	nextSweep time.Time
+	now       func() time.Time
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:   limit,
+		window:  window,
+		windows: make(map[string]rateWindow),
+		now:     time.Now,
+	}
+}
+
+// Allow records an event for key and reports whether it is within
+// the limit. Rejected events are not counted.
+func (l *RateLimiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.start.Add(l.window)) {
+		w = rateWindow{start: now}
+	}
+	if w.count >= l.limit {
+		return false
+	}
+
+	w.count++
+	l.windows[key] = w
+	return true
+}
+
+// Check reports whether an event for key would be within the limit,
+// without recording it.
+func (l *RateLimiter) Check(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	w, ok := l.windows[key]
+	return !ok || !now.Before(w.start.Add(l.window)) || w.count < l.limit
+}
+
+// sweep drops finished windows, at most once per window length.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Before(l.nextSweep) {
+		return
+	}
+	for key, w := range l.windows {
+		if !now.Before(w.start.Add(l.window)) {
+			delete(l.windows, key)
+		}
+	}
+	l.nextSweep = now.Add(l.window)
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"slices"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bugii1995/backend/internal/users"
+)
+
+// Area is a group of routes that shares one access rule.
+type Area string
+
+const (
+	AreaLearner Area = "learner" // quiz and own progress
+	AreaTeacher Area = "teacher" // classrooms and dashboards
+	AreaAdmin   Area = "admin"   // question bank and configuration
+)
+
+// Access says who may call an area: users with one of Roles, or with
+// at least Permission.
+type Access struct {
+	Roles      []users.UserRole
+	Permission users.PermissionLevel
+}
+
+// AccessMatrix is the route access policy, enforced by RequireArea.
+var AccessMatrix = map[Area]Access{
+	AreaLearner: {Roles: []users.UserRole{users.RoleStudent, users.RoleTeacher}, Permission: users.PermUser},
+	AreaTeacher: {Roles: []users.UserRole{users.RoleTeacher}, Permission: users.PermAdmin},
+	AreaAdmin:   {Permission: users.PermAdmin},
+}
+
+// permissionRank orders permission levels; unknown levels rank lowest.
+var permissionRank = map[users.PermissionLevel]int{
+	users.PermUser:  1,
+	users.PermAdmin: 2,
+}
+
+// HasPermission reports whether u's permission level is at least level.
+func HasPermission(u users.User, level users.PermissionLevel) bool {
+	return permissionRank[u.Permission] >= permissionRank[level]
+}
+
+// CanAccess reports whether u may call routes in area.
+func CanAccess(u users.User, area Area) bool {
+	access, ok := AccessMatrix[area]
+	if !ok {
+		return false
+	}
+	return slices.Contains(access.Roles, u.Role) ||
+		(access.Permission != "" && HasPermission(u, access.Permission))
+}
+
+// ---------------- Middleware ----------------
+//
+// The guards run after RequireUser. A missing user is 401, a user
+// without access is 403.
+
+// RequireArea allows users that AccessMatrix lets into area.
+func RequireArea(area Area) gin.HandlerFunc {
+	return guard(func(u users.User) bool { return CanAccess(u, area) })
+}
+
+// RequireRole allows users with one of roles.
+func RequireRole(roles ...users.UserRole) gin.HandlerFunc {
+	return guard(func(u users.User) bool { return slices.Contains(roles, u.Role) })
+}
+
+// RequirePermission allows users with at least level.
+func RequirePermission(level users.PermissionLevel) gin.HandlerFunc {
+	return guard(func(u users.User) bool { return HasPermission(u, level) })
+}
+
+func guard(allowed func(users.User) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := CurrentUser(c)
+		if !ok {
+			c.Error(ErrUnauthenticated)
+			c.Abort()
+			return
+		}
+		if !allowed(user) {
+			c.Error(ErrForbidden)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
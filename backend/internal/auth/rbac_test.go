@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bugii1995/backend/internal/apitest"
+	"github.com/bugii1995/backend/internal/httperr"
+	"github.com/bugii1995/backend/internal/users"
+)
+
+var (
+	student = users.User{ID: 1, Role: users.RoleStudent, Permission: users.PermUser}
+	teacher = users.User{ID: 2, Role: users.RoleTeacher, Permission: users.PermUser}
+	admin   = users.User{ID: 3, Role: users.RoleStudent, Permission: users.PermAdmin}
+)
+
+// newGuardRouter serves one route per area and guard, as user.
+func newGuardRouter(user *users.User) *gin.Engine {
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+
+	r := gin.New()
+	r.Use(httperr.Middleware(HTTPErrors...))
+	r.Use(func(c *gin.Context) {
+		if user != nil {
+			SetCurrentUser(c, *user)
+		}
+	})
+	r.GET("/learner", RequireArea(AreaLearner), ok)
+	r.GET("/teacher", RequireArea(AreaTeacher), ok)
+	r.GET("/admin", RequireArea(AreaAdmin), ok)
+	r.GET("/role/teacher", RequireRole(users.RoleTeacher), ok)
+	r.GET("/perm/admin", RequirePermission(users.PermAdmin), ok)
+	return r
+}
+
+func get(r http.Handler, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestAccessMatrix(t *testing.T) {
+	tests := []struct {
+		name string
+		user users.User
+		want map[string]int
+	}{
+		{"student", student, map[string]int{
+			"/learner": http.StatusNoContent,
+			"/teacher": http.StatusForbidden,
+			"/admin":   http.StatusForbidden,
+		}},
+		{"teacher", teacher, map[string]int{
+			"/learner": http.StatusNoContent,
+			"/teacher": http.StatusNoContent,
+			"/admin":   http.StatusForbidden,
+		}},
+		{"admin", admin, map[string]int{
+			"/learner": http.StatusNoContent,
+			"/teacher": http.StatusNoContent,
+			"/admin":   http.StatusNoContent,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newGuardRouter(&tt.user)
+			for path, want := range tt.want {
+				w := get(r, path)
+				if w.Code != want {
+					t.Fatalf("%s: expected %d, got %d %s", path, want, w.Code, w.Body.String())
+				}
+				if want == http.StatusForbidden {
+					if body := apitest.Decode[httperr.Envelope](t, w); body.Code != "forbidden" {
+						t.Fatalf("%s: unexpected body %+v", path, body)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRequireRoleAndPermission(t *testing.T) {
+	tests := []struct {
+		name string
+		user users.User
+		path string
+		want int
+	}{
+		{"student is not a teacher", student, "/role/teacher", http.StatusForbidden},
+		{"teacher role", teacher, "/role/teacher", http.StatusNoContent},
+		{"admin permission is not a role", admin, "/role/teacher", http.StatusForbidden},
+		{"student is not an admin", student, "/perm/admin", http.StatusForbidden},
+		{"teacher is not an admin", teacher, "/perm/admin", http.StatusForbidden},
+		{"admin permission", admin, "/perm/admin", http.StatusNoContent},
+		{"unknown permission ranks lowest", users.User{Permission: "root"}, "/perm/admin", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := get(newGuardRouter(&tt.user), tt.path); w.Code != tt.want {
+				t.Fatalf("expected %d, got %d %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestGuardsRequireUser(t *testing.T) {
+	r := newGuardRouter(nil)
+
+	for _, path := range []string{"/learner", "/teacher", "/admin", "/role/teacher", "/perm/admin"} {
+		if w := get(r, path); w.Code != http.StatusUnauthorized {
+			t.Fatalf("%s: expected 401, got %d", path, w.Code)
+		}
+	}
+}
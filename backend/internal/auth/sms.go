@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// SMSSender delivers text messages to a phone number.
+type SMSSender interface {
+	Send(ctx context.Context, phone, message string) error
+}
+
+// LogSMSSender writes messages to the log instead of sending them.
+// For local development only: it prints one-time codes in clear text.
+type LogSMSSender struct{}
+
+func (LogSMSSender) Send(ctx context.Context, phone, message string) error {
+	log.Printf("sms to %s: %s", phone, message)
+	return nil
+}
+
+// SMSMessage is one message captured by FakeSMSSender.
+type SMSMessage struct {
+	Phone   string
+	Message string
+}
+
+// FakeSMSSender records messages for tests.
+type FakeSMSSender struct {
+	mu       sync.Mutex
+	messages []SMSMessage
+}
+
+func (f *FakeSMSSender) Send(ctx context.Context, phone, message string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.messages = append(f.messages, SMSMessage{Phone: phone, Message: message})
+	return nil
+}
+
+// Messages returns a copy of everything sent so far.
+func (f *FakeSMSSender) Messages() []SMSMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]SMSMessage(nil), f.messages...)
+}
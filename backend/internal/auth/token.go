@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenIssuer signs and verifies HS256 JWT session tokens.
+//
+// The token only carries the user ID; role and account type are read
+// from the user repository on every request so changes apply at once.
+type TokenIssuer struct {
+	secret []byte
+	ttl    time.Duration
+
+	now func() time.Time
+}
+
+func NewTokenIssuer(secret []byte, ttl time.Duration) *TokenIssuer {
+	return &TokenIssuer{secret: secret, ttl: ttl, now: time.Now}
+}
+
+// Issue returns a signed token for userID and its expiry.
+func (t *TokenIssuer) Issue(userID uint64) (string, time.Time, error) {
+	now := t.now()
+	expiresAt := now.Add(t.ttl)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   strconv.FormatUint(userID, 10),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	})
+
+	signed, err := token.SignedString(t.secret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// Verify checks signature and expiry and returns the user ID.
+func (t *TokenIssuer) Verify(token string) (uint64, error) {
+	var claims jwt.RegisteredClaims
+	_, err := jwt.ParseWithClaims(
+		token,
+		&claims,
+		func(*jwt.Token) (any, error) { return t.secret, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(t.now),
+	)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+	return userID, nil
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTokenRoundTrip(t *testing.T) {
+	tokens := NewTokenIssuer([]byte("secret"), time.Hour)
+
+	token, expiresAt, err := tokens.Issue(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !expiresAt.After(time.Now()) {
+		t.Fatalf("expected expiry in the future, got %v", expiresAt)
+	}
+
+	userID, err := tokens.Verify(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if userID != 42 {
+		t.Fatalf("expected user 42, got %d", userID)
+	}
+}
+
+func TestTokenExpires(t *testing.T) {
+	now := time.Now()
+	tokens := NewTokenIssuer([]byte("secret"), time.Hour)
+	tokens.now = func() time.Time { return now }
+
+	token, _, _ := tokens.Issue(42)
+
+	tokens.now = func() time.Time { return now.Add(2 * time.Hour) }
+	if _, err := tokens.Verify(token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestTokenRejectsOtherSecret(t *testing.T) {
+	token, _, _ := NewTokenIssuer([]byte("secret"), time.Hour).Issue(42)
+
+	other := NewTokenIssuer([]byte("other"), time.Hour)
+	if _, err := other.Verify(token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestTokenRejectsGarbage(t *testing.T) {
+	tokens := NewTokenIssuer([]byte("secret"), time.Hour)
+
+	for _, token := range []string{"", "abc", "a.b.c"} {
+		if _, err := tokens.Verify(token); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("%q: expected ErrInvalidToken, got %v", token, err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Package classroom lets teachers group students into classes and
+// assign them topics to practise.
+package classroom
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/bugii1995/backend/internal/httperr"
+)
+
+var (
+	ErrClassroomNotFound  = errors.New("classroom not found")
+	ErrAssignmentNotFound = errors.New("assignment not found")
+	ErrStudentNotFound    = errors.New("student is not in this classroom")
+	ErrInviteNotFound     = errors.New("no pending invite to this classroom")
+	ErrInvalidJoinCode    = errors.New("no classroom has this join code")
+	ErrJoinCodeTaken      = errors.New("join code already in use")
+	ErrInvalidClassroom   = errors.New("classroom name is required")
+	ErrInvalidAssignment  = errors.New("an assignment needs at least one known topic and a future due date")
+)
+
+// HTTPErrors maps classroom errors for httperr.Middleware.
+var HTTPErrors = []httperr.Rule{
+	{Err: ErrClassroomNotFound, Status: http.StatusNotFound, Code: "classroom_not_found"},
+	{Err: ErrAssignmentNotFound, Status: http.StatusNotFound, Code: "assignment_not_found"},
+	{Err: ErrStudentNotFound, Status: http.StatusNotFound, Code: "student_not_found"},
+	{Err: ErrInviteNotFound, Status: http.StatusNotFound, Code: "invite_not_found"},
+	{Err: ErrInvalidJoinCode, Status: http.StatusNotFound, Code: "invalid_join_code"},
+	{Err: ErrInvalidClassroom, Status: http.StatusUnprocessableEntity, Code: "invalid_classroom"},
+	{Err: ErrInvalidAssignment, Status: http.StatusUnprocessableEntity, Code: "invalid_assignment"},
+}
+
+// ---------- Models ----------
+
+type Classroom struct {
+	ID        uint64    `json:"id"`
+	TeacherID uint64    `json:"teacher_id"`
+	Name      string    `json:"name"`
+	JoinCode  string    `json:"join_code"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Membership struct {
+	ClassroomID uint64    `json:"classroom_id"`
+	StudentID   uint64    `json:"student_id"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+// Assignment asks a class to practise TopicIDs until DueAt.
+type Assignment struct {
+	ID          uint64    `json:"id"`
+	ClassroomID uint64    `json:"classroom_id"`
+	TopicIDs    []string  `json:"topic_ids"`
+	DueAt       time.Time `json:"due_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// IsOpen reports whether a is still being worked on at now.
+func (a Assignment) IsOpen(now time.Time) bool {
+	return now.Before(a.DueAt)
+}
@@ -0,0 +1,245 @@
+package classroom
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/bugii1995/backend/internal/quiz"
+	"github.com/bugii1995/backend/internal/users"
+)
+
+// MostMissedLimit caps ClassReport.MostMissed.
+const MostMissedLimit = 10
+
+// ---------- Report models ----------
+
+// TopicProgress is quiz.TopicProgress as shown to teachers.
+// ProjectedMastery is Mastery decayed up to the time of the report.
+type TopicProgress struct {
+	TopicID          string    `json:"topic_id"`
+	Mastery          float64   `json:"mastery"`
+	ProjectedMastery float64   `json:"projected_mastery"`
+	CorrectStreak    int       `json:"correct_streak"`
+	WrongStreak      int       `json:"wrong_streak"`
+	IsMastered       bool      `json:"is_mastered"`
+	LastSeen         time.Time `json:"last_seen"`
+}
+
+// MasteryPoint is one answer in a topic's mastery history.
+type MasteryPoint struct {
+	QuestionID    int64     `json:"question_id"`
+	WasCorrect    bool      `json:"was_correct"`
+	Mastery       float64   `json:"mastery"`
+	CorrectStreak int       `json:"correct_streak"`
+	WrongStreak   int       `json:"wrong_streak"`
+	IsMastered    bool      `json:"is_mastered"`
+	AnsweredAt    time.Time `json:"answered_at"`
+}
+
+type StudentReport struct {
+	StudentID   uint64          `json:"student_id"`
+	PhoneNumber string          `json:"phone_number"`
+	Progress    []TopicProgress `json:"progress"`
+
+	// History is each topic's mastery after every answer, oldest first.
+	History map[string][]MasteryPoint `json:"history"`
+}
+
+// TopicSummary aggregates one topic over a class. MeanMastery is the
+// mean ProjectedMastery of the students who practised it;
+// MasteredShare is the share of the whole class still mastering it
+// after decay.
+type TopicSummary struct {
+	TopicID       string  `json:"topic_id"`
+	Students      int     `json:"students"`
+	MeanMastery   float64 `json:"mean_mastery"`
+	MasteredShare float64 `json:"mastered_share"`
+}
+
+type MissedQuestion struct {
+	QuestionID int64   `json:"question_id"`
+	TopicID    string  `json:"topic_id"`
+	Attempts   int     `json:"attempts"`
+	Misses     int     `json:"misses"`
+	MissRate   float64 `json:"miss_rate"`
+}
+
+type ClassReport struct {
+	ClassroomID uint64           `json:"classroom_id"`
+	Students    int              `json:"students"`
+	Topics      []TopicSummary   `json:"topics"`
+	MostMissed  []MissedQuestion `json:"most_missed"`
+}
+
+// ---------------- Reports ----------------
+
+// StudentReport returns a member's progress and mastery history.
+func (s *Service) StudentReport(
+	ctx context.Context,
+	teacher users.User,
+	classroomID, studentID uint64,
+) (StudentReport, error) {
+
+	members, err := s.Members(ctx, teacher, classroomID)
+	if err != nil {
+		return StudentReport{}, err
+	}
+	if !isMember(members, studentID) {
+		return StudentReport{}, ErrStudentNotFound
+	}
+
+	student, err := s.users.GetByID(ctx, studentID)
+	if err != nil {
+		return StudentReport{}, err
+	}
+	progress, err := s.progress.ListProgress(ctx, studentID)
+	if err != nil {
+		return StudentReport{}, err
+	}
+	answers, err := s.progress.ListAnswers(ctx, studentID)
+	if err != nil {
+		return StudentReport{}, err
+	}
+
+	mastery, now := s.mastery(), s.now()
+	report := StudentReport{
+		StudentID:   studentID,
+		PhoneNumber: student.PhoneNumber,
+		Progress:    make([]TopicProgress, 0, len(progress)),
+		History:     make(map[string][]MasteryPoint),
+	}
+	for _, p := range progress {
+		report.Progress = append(report.Progress, TopicProgress{
+			TopicID:          p.TopicID,
+			Mastery:          p.Mastery,
+			ProjectedMastery: mastery.ProjectedMastery(p, now),
+			CorrectStreak:    p.CorrectStreak,
+			WrongStreak:      p.WrongStreak,
+			IsMastered:       p.IsMastered,
+			LastSeen:         p.LastSeen,
+		})
+	}
+	for _, a := range answers {
+		report.History[a.TopicID] = append(report.History[a.TopicID], MasteryPoint{
+			QuestionID:    a.QuestionID,
+			WasCorrect:    a.WasCorrect,
+			Mastery:       a.Mastery.Mastery,
+			CorrectStreak: a.Mastery.CorrectStreak,
+			WrongStreak:   a.Mastery.WrongStreak,
+			IsMastered:    a.Mastery.IsMastered,
+			AnsweredAt:    a.AnsweredAt,
+		})
+	}
+	return report, nil
+}
+
+// ClassReport aggregates progress and answers over all members.
+func (s *Service) ClassReport(ctx context.Context, teacher users.User, classroomID uint64) (ClassReport, error) {
+	members, err := s.Members(ctx, teacher, classroomID)
+	if err != nil {
+		return ClassReport{}, err
+	}
+
+	type topicTotals struct {
+		students int
+		mastery  float64
+		mastered int
+	}
+	topics := make(map[string]*topicTotals)
+	missed := make(map[int64]*MissedQuestion)
+	mastery, now := s.mastery(), s.now()
+
+	for _, m := range members {
+		progress, err := s.progress.ListProgress(ctx, m.StudentID)
+		if err != nil {
+			return ClassReport{}, err
+		}
+		for _, p := range progress {
+			t := topics[p.TopicID]
+			if t == nil {
+				t = &topicTotals{}
+				topics[p.TopicID] = t
+			}
+			t.students++
+			t.mastery += mastery.ProjectedMastery(p, now)
+			if mastery.ProjectedMastered(p, now) {
+				t.mastered++
+			}
+		}
+
+		answers, err := s.progress.ListAnswers(ctx, m.StudentID)
+		if err != nil {
+			return ClassReport{}, err
+		}
+		for _, a := range answers {
+			q := missed[a.QuestionID]
+			if q == nil {
+				q = &MissedQuestion{QuestionID: a.QuestionID, TopicID: a.TopicID}
+				missed[a.QuestionID] = q
+			}
+			q.Attempts++
+			if !a.WasCorrect {
+				q.Misses++
+			}
+		}
+	}
+
+	report := ClassReport{
+		ClassroomID: classroomID,
+		Students:    len(members),
+		Topics:      make([]TopicSummary, 0, len(topics)),
+		MostMissed:  make([]MissedQuestion, 0),
+	}
+
+	for id, t := range topics {
+		report.Topics = append(report.Topics, TopicSummary{
+			TopicID:       id,
+			Students:      t.students,
+			MeanMastery:   t.mastery / float64(t.students),
+			MasteredShare: float64(t.mastered) / float64(len(members)),
+		})
+	}
+	sort.Slice(report.Topics, func(i, j int) bool { return report.Topics[i].TopicID < report.Topics[j].TopicID })
+
+	for _, q := range missed {
+		if q.Misses == 0 {
+			continue
+		}
+		q.MissRate = float64(q.Misses) / float64(q.Attempts)
+		report.MostMissed = append(report.MostMissed, *q)
+	}
+	sort.Slice(report.MostMissed, func(i, j int) bool {
+		a, b := report.MostMissed[i], report.MostMissed[j]
+		if a.Misses != b.Misses {
+			return a.Misses > b.Misses
+		}
+		if a.MissRate != b.MissRate {
+			return a.MissRate > b.MissRate
+		}
+		return a.QuestionID < b.QuestionID
+	})
+	if len(report.MostMissed) > MostMissedLimit {
+		report.MostMissed = report.MostMissed[:MostMissedLimit]
+	}
+
+	return report, nil
+}
+
+// mastery is the mastery config in force, so reports decay mastery
+// the way quiz selection does.
+func (s *Service) mastery() quiz.MasteryConfig {
+	if s.pedagogy == nil {
+		return quiz.DefaultMasteryConfig()
+	}
+	return s.pedagogy.Current().Mastery
+}
+
+func isMember(members []Membership, studentID uint64) bool {
+	for _, m := range members {
+		if m.StudentID == studentID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,220 @@
+package classroom
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bugii1995/backend/internal/quiz"
+	"github.com/bugii1995/backend/internal/users"
+)
+
+// answer records a graded answer the way quiz.Handler does.
+func (f *fixture) answer(t *testing.T, student users.User, topicID string, questionID int64, correct bool) {
+	t.Helper()
+	ctx := context.Background()
+
+	current := quiz.TopicProgress{TopicID: topicID, Mastery: quiz.InitialMastery}
+	progress, _ := f.progress.ListProgress(ctx, student.ID)
+	for _, p := range progress {
+		if p.TopicID == topicID {
+			current = p
+		}
+	}
+
+	f.now = f.now.Add(time.Minute)
+	update := quiz.UpdateMastery(current, quiz.MasteryUpdateInput{
+		WasCorrect: correct, Difficulty: 2, AnsweredAt: f.now, CurrentTime: f.now,
+	})
+
+	f.progress.SaveProgress(ctx, student.ID, quiz.TopicProgress{
+		TopicID:       topicID,
+		Mastery:       update.Mastery,
+		CorrectStreak: update.CorrectStreak,
+		WrongStreak:   update.WrongStreak,
+		IsMastered:    update.IsMastered,
+		LastSeen:      update.LastSeen,
+	})
+	f.progress.RecordAnswer(ctx, student.ID, quiz.AnswerRecord{
+		TopicID: topicID, QuestionID: questionID, WasCorrect: correct, Mastery: update, AnsweredAt: f.now,
+	})
+}
+
+func TestStudentReport(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	c := f.classroom(t)
+	f.service.Join(ctx, f.student, c.JoinCode)
+
+	f.answer(t, f.student, "articles", 1, true)
+	f.answer(t, f.student, "conditionals", 5, false)
+	f.answer(t, f.student, "articles", 2, true)
+
+	report, err := f.service.StudentReport(ctx, f.teacher, c.ID, f.student.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Progress) != 2 || report.Progress[0].TopicID != "articles" || report.Progress[0].CorrectStreak != 2 {
+		t.Fatalf("unexpected progress %+v", report.Progress)
+	}
+
+	history := report.History["articles"]
+	if len(history) != 2 || history[0].QuestionID != 1 || history[1].QuestionID != 2 {
+		t.Fatalf("expected articles history in answer order, got %+v", history)
+	}
+	if history[1].Mastery <= history[0].Mastery || history[1].Mastery != report.Progress[0].Mastery {
+		t.Fatalf("expected a rising series ending at current mastery, got %+v", history)
+	}
+	if got := report.History["conditionals"]; len(got) != 1 || got[0].WasCorrect {
+		t.Fatalf("unexpected conditionals history %+v", got)
+	}
+}
+
+func TestStudentReportProjectsDecay(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	c := f.classroom(t)
+	f.service.Join(ctx, f.student, c.JoinCode)
+
+	f.answer(t, f.student, "articles", 1, true)
+
+	// Twenty days without practice: 13 days of medium decay.
+	f.now = f.now.Add(20 * 24 * time.Hour)
+	report, err := f.service.StudentReport(ctx, f.teacher, c.ID, f.student.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := report.Progress[0]
+	if want := got.Mastery - 13*quiz.DecayMediumRate; math.Abs(got.ProjectedMastery-want) > 1e-9 {
+		t.Fatalf("expected projected mastery %v, got %+v", want, got)
+	}
+
+	// The report only reads; stored mastery is unchanged.
+	progress, _ := f.progress.ListProgress(ctx, f.student.ID)
+	if progress[0].Mastery != got.Mastery {
+		t.Fatalf("expected stored mastery %v, got %v", got.Mastery, progress[0].Mastery)
+	}
+}
+
+func TestReportsUseConfiguredDecay(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	c := f.classroom(t)
+	f.service.Join(ctx, f.student, c.JoinCode)
+
+	path := filepath.Join(t.TempDir(), "pedagogy.yaml")
+	if err := os.WriteFile(path, []byte("mastery:\n  decay_medium_rate: 1.5\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pedagogy, err := quiz.OpenPedagogy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.service.pedagogy = pedagogy
+
+	f.answer(t, f.student, "articles", 1, true)
+
+	// Twenty days without practice: 13 days of the configured decay.
+	f.now = f.now.Add(20 * 24 * time.Hour)
+	student, err := f.service.StudentReport(ctx, f.teacher, c.ID, f.student.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := student.Progress[0]
+	if want := got.Mastery - 13*1.5; math.Abs(got.ProjectedMastery-want) > 1e-9 {
+		t.Fatalf("expected projected mastery %v, got %+v", want, got)
+	}
+
+	// The class report agrees with the student report.
+	class, err := f.service.ClassReport(ctx, f.teacher, c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mean := class.Topics[0].MeanMastery; math.Abs(mean-got.ProjectedMastery) > 1e-9 {
+		t.Fatalf("expected class mean %v, got %v", got.ProjectedMastery, mean)
+	}
+}
+
+func TestStudentReportAccess(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	c := f.classroom(t)
+	f.service.Join(ctx, f.student, c.JoinCode)
+
+	outsider, _ := f.users.Create(ctx, users.NewUser("+97688000002"))
+	if _, err := f.service.StudentReport(ctx, f.teacher, c.ID, outsider.ID); !errors.Is(err, ErrStudentNotFound) {
+		t.Fatalf("expected ErrStudentNotFound for a non-member, got %v", err)
+	}
+
+	other := users.User{ID: 99, Role: users.RoleTeacher}
+	if _, err := f.service.StudentReport(ctx, other, c.ID, f.student.ID); !errors.Is(err, ErrClassroomNotFound) {
+		t.Fatalf("expected ErrClassroomNotFound for another teacher, got %v", err)
+	}
+	if _, err := f.service.ClassReport(ctx, other, c.ID); !errors.Is(err, ErrClassroomNotFound) {
+		t.Fatalf("expected ErrClassroomNotFound for another teacher, got %v", err)
+	}
+}
+
+func TestClassReport(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	c := f.classroom(t)
+
+	second, _ := f.users.Create(ctx, users.NewUser("+97688000002"))
+	third, _ := f.users.Create(ctx, users.NewUser("+97688000003"))
+	for _, s := range []users.User{f.student, second, third} {
+		f.service.Join(ctx, s, c.JoinCode)
+	}
+
+	// The first student has mastered articles outright.
+	f.progress.SaveProgress(ctx, f.student.ID, quiz.TopicProgress{TopicID: "articles", Mastery: 100, IsMastered: true})
+
+	f.answer(t, second, "articles", 1, false)
+	f.answer(t, second, "articles", 2, false)
+	f.answer(t, third, "articles", 1, false)
+	f.answer(t, third, "articles", 2, true)
+	f.answer(t, third, "conditionals", 5, false)
+
+	report, err := f.service.ClassReport(ctx, f.teacher, c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Students != 3 || len(report.Topics) != 2 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	articles := report.Topics[0]
+	progress2, _ := f.progress.ListProgress(ctx, second.ID)
+	progress3, _ := f.progress.ListProgress(ctx, third.ID)
+	wantMean := (100 + progress2[0].Mastery + progress3[0].Mastery) / 3
+	if articles.TopicID != "articles" || articles.Students != 3 || articles.MeanMastery != wantMean {
+		t.Fatalf("expected articles mean %v over 3 students, got %+v", wantMean, articles)
+	}
+	if articles.MasteredShare != 1.0/3 {
+		t.Fatalf("expected a third of the class to have mastered articles, got %v", articles.MasteredShare)
+	}
+	if conditionals := report.Topics[1]; conditionals.Students != 1 || conditionals.MasteredShare != 0 {
+		t.Fatalf("unexpected conditionals summary %+v", conditionals)
+	}
+
+	want := []MissedQuestion{
+		{QuestionID: 1, TopicID: "articles", Attempts: 2, Misses: 2, MissRate: 1},
+		{QuestionID: 5, TopicID: "conditionals", Attempts: 1, Misses: 1, MissRate: 1},
+		{QuestionID: 2, TopicID: "articles", Attempts: 2, Misses: 1, MissRate: 0.5},
+	}
+	if len(report.MostMissed) != len(want) {
+		t.Fatalf("expected %d missed questions, got %+v", len(want), report.MostMissed)
+	}
+	for i := range want {
+		if report.MostMissed[i] != want[i] {
+			t.Fatalf("most missed %d: expected %+v, got %+v", i, want[i], report.MostMissed[i])
+		}
+	}
+}
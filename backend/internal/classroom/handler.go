@@ -0,0 +1,342 @@
+package classroom
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bugii1995/backend/internal/auth"
+	"github.com/bugii1995/backend/internal/httperr"
+)
+
+// ---------------- Handler ----------------
+
+// Handler serves /teacher/classrooms (behind auth.AreaTeacher) and
+// /classrooms (behind auth.AreaLearner).
+type Handler struct {
+	Classrooms *Service
+}
+
+// ---------------- DTOs ----------------
+
+type CreateClassroomRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type InviteRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required"`
+}
+
+type InviteResponse struct {
+	Status string `json:"status"` // "invited"
+}
+
+type AssignRequest struct {
+	TopicIDs []string  `json:"topic_ids" binding:"required"`
+	DueAt    time.Time `json:"due_at" binding:"required"`
+}
+
+type JoinRequest struct {
+	JoinCode string `json:"join_code" binding:"required"`
+}
+
+// ---------------- Helpers ----------------
+
+// idParam parses a numeric path parameter. Malformed IDs are reported
+// as notFound.
+func idParam(c *gin.Context, name string, notFound error) (uint64, error) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil {
+		return 0, notFound
+	}
+	return id, nil
+}
+
+// ---------------- Teacher handlers ----------------
+
+// POST /teacher/classrooms
+func (h *Handler) CreateClassroom(c *gin.Context) {
+	teacher, ok := auth.CurrentUser(c)
+	if !ok {
+		c.Error(auth.ErrUnauthenticated)
+		return
+	}
+
+	var req CreateClassroomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(httperr.BadRequest(err))
+		return
+	}
+
+	classroom, err := h.Classrooms.CreateClassroom(c.Request.Context(), teacher, req.Name)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, classroom)
+}
+
+// GET /teacher/classrooms
+func (h *Handler) ListClassrooms(c *gin.Context) {
+	teacher, ok := auth.CurrentUser(c)
+	if !ok {
+		c.Error(auth.ErrUnauthenticated)
+		return
+	}
+
+	classrooms, err := h.Classrooms.TeacherClassrooms(c.Request.Context(), teacher)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, classrooms)
+}
+
+// GET /teacher/classrooms/:id/members
+func (h *Handler) ListMembers(c *gin.Context) {
+	teacher, ok := auth.CurrentUser(c)
+	if !ok {
+		c.Error(auth.ErrUnauthenticated)
+		return
+	}
+	classroomID, err := idParam(c, "id", ErrClassroomNotFound)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	members, err := h.Classrooms.Members(c.Request.Context(), teacher, classroomID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+// POST /teacher/classrooms/:id/invites
+func (h *Handler) Invite(c *gin.Context) {
+	teacher, ok := auth.CurrentUser(c)
+	if !ok {
+		c.Error(auth.ErrUnauthenticated)
+		return
+	}
+	classroomID, err := idParam(c, "id", ErrClassroomNotFound)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req InviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(httperr.BadRequest(err))
+		return
+	}
+
+	if err := h.Classrooms.Invite(c.Request.Context(), teacher, classroomID, req.PhoneNumber); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusAccepted, InviteResponse{Status: "invited"})
+}
+
+// POST /teacher/classrooms/:id/assignments
+func (h *Handler) Assign(c *gin.Context) {
+	teacher, ok := auth.CurrentUser(c)
+	if !ok {
+		c.Error(auth.ErrUnauthenticated)
+		return
+	}
+	classroomID, err := idParam(c, "id", ErrClassroomNotFound)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req AssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(httperr.BadRequest(err))
+		return
+	}
+
+	assignment, err := h.Classrooms.Assign(c.Request.Context(), teacher, classroomID, req.TopicIDs, req.DueAt)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, assignment)
+}
+
+// GET /teacher/classrooms/:id/assignments
+func (h *Handler) ListAssignments(c *gin.Context) {
+	teacher, ok := auth.CurrentUser(c)
+	if !ok {
+		c.Error(auth.ErrUnauthenticated)
+		return
+	}
+	classroomID, err := idParam(c, "id", ErrClassroomNotFound)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	assignments, err := h.Classrooms.Assignments(c.Request.Context(), teacher, classroomID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, assignments)
+}
+
+// GET /teacher/classrooms/:id/assignments/:assignmentID/completion
+func (h *Handler) Completion(c *gin.Context) {
+	teacher, ok := auth.CurrentUser(c)
+	if !ok {
+		c.Error(auth.ErrUnauthenticated)
+		return
+	}
+	classroomID, err := idParam(c, "id", ErrClassroomNotFound)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	assignmentID, err := idParam(c, "assignmentID", ErrAssignmentNotFound)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	completion, err := h.Classrooms.Completion(c.Request.Context(), teacher, classroomID, assignmentID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, completion)
+}
+
+// ---------------- Student handlers ----------------
+
+// POST /classrooms/join
+func (h *Handler) Join(c *gin.Context) {
+	student, ok := auth.CurrentUser(c)
+	if !ok {
+		c.Error(auth.ErrUnauthenticated)
+		return
+	}
+
+	var req JoinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(httperr.BadRequest(err))
+		return
+	}
+
+	classroom, err := h.Classrooms.Join(c.Request.Context(), student, req.JoinCode)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, classroom)
+}
+
+// GET /classrooms
+func (h *Handler) MyClassrooms(c *gin.Context) {
+	student, ok := auth.CurrentUser(c)
+	if !ok {
+		c.Error(auth.ErrUnauthenticated)
+		return
+	}
+
+	classrooms, err := h.Classrooms.StudentClassrooms(c.Request.Context(), student)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, classrooms)
+}
+
+// GET /classrooms/invites
+func (h *Handler) MyInvites(c *gin.Context) {
+	student, ok := auth.CurrentUser(c)
+	if !ok {
+		c.Error(auth.ErrUnauthenticated)
+		return
+	}
+
+	classrooms, err := h.Classrooms.Invites(c.Request.Context(), student)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, classrooms)
+}
+
+// POST /classrooms/invites/:id/accept
+func (h *Handler) AcceptInvite(c *gin.Context) {
+	student, ok := auth.CurrentUser(c)
+	if !ok {
+		c.Error(auth.ErrUnauthenticated)
+		return
+	}
+	classroomID, err := idParam(c, "id", ErrInviteNotFound)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	classroom, err := h.Classrooms.AcceptInvite(c.Request.Context(), student, classroomID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, classroom)
+}
+
+// ---------------- Dashboard handlers ----------------
+
+// GET /teacher/classrooms/:id/report
+func (h *Handler) ClassReport(c *gin.Context) {
+	teacher, ok := auth.CurrentUser(c)
+	if !ok {
+		c.Error(auth.ErrUnauthenticated)
+		return
+	}
+	classroomID, err := idParam(c, "id", ErrClassroomNotFound)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	report, err := h.Classrooms.ClassReport(c.Request.Context(), teacher, classroomID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GET /teacher/classrooms/:id/students/:studentID
+func (h *Handler) StudentReport(c *gin.Context) {
+	teacher, ok := auth.CurrentUser(c)
+	if !ok {
+		c.Error(auth.ErrUnauthenticated)
+		return
+	}
+	classroomID, err := idParam(c, "id", ErrClassroomNotFound)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	studentID, err := idParam(c, "studentID", ErrStudentNotFound)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	report, err := h.Classrooms.StudentReport(c.Request.Context(), teacher, classroomID, studentID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
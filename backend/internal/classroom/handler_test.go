@@ -0,0 +1,213 @@
+package classroom
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bugii1995/backend/internal/apitest"
+	"github.com/bugii1995/backend/internal/auth"
+	"github.com/bugii1995/backend/internal/httperr"
+	"github.com/bugii1995/backend/internal/users"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// testUserHeader selects the authenticated user, by ID, in test routers.
+const testUserHeader = "X-Test-User"
+
+func newTestRouter(f *fixture) *gin.Engine {
+	h := &Handler{Classrooms: f.service}
+
+	r := gin.New()
+	var rules []httperr.Rule
+	rules = append(rules, HTTPErrors...)
+	rules = append(rules, auth.HTTPErrors...)
+	r.Use(httperr.Middleware(rules...))
+	r.Use(func(c *gin.Context) {
+		id, _ := strconv.ParseUint(c.GetHeader(testUserHeader), 10, 64)
+		if u, err := f.users.GetByID(c.Request.Context(), id); err == nil {
+			auth.SetCurrentUser(c, u)
+		}
+	})
+
+	learner := r.Group("/classrooms", auth.RequireArea(auth.AreaLearner))
+	learner.GET("", h.MyClassrooms)
+	learner.POST("/join", h.Join)
+	learner.GET("/invites", h.MyInvites)
+	learner.POST("/invites/:id/accept", h.AcceptInvite)
+
+	teacher := r.Group("/teacher", auth.RequireArea(auth.AreaTeacher))
+	teacher.POST("/classrooms", h.CreateClassroom)
+	teacher.GET("/classrooms", h.ListClassrooms)
+	teacher.GET("/classrooms/:id/members", h.ListMembers)
+	teacher.POST("/classrooms/:id/invites", h.Invite)
+	teacher.POST("/classrooms/:id/assignments", h.Assign)
+	teacher.GET("/classrooms/:id/assignments", h.ListAssignments)
+	teacher.GET("/classrooms/:id/assignments/:assignmentID/completion", h.Completion)
+	teacher.GET("/classrooms/:id/report", h.ClassReport)
+	teacher.GET("/classrooms/:id/students/:studentID", h.StudentReport)
+	return r
+}
+
+func do(t *testing.T, r http.Handler, user users.User, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(testUserHeader, strconv.FormatUint(user.ID, 10))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestClassroomFlow(t *testing.T) {
+	f := newFixture(t)
+	r := newTestRouter(f)
+
+	w := do(t, r, f.teacher, http.MethodPost, "/teacher/classrooms", CreateClassroomRequest{Name: "Grade 9B"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+	class := apitest.Decode[Classroom](t, w)
+	base := "/teacher/classrooms/" + strconv.FormatUint(class.ID, 10)
+
+	w = do(t, r, f.student, http.MethodPost, "/classrooms/join", JoinRequest{JoinCode: class.JoinCode})
+	if w.Code != http.StatusOK {
+		t.Fatalf("join: %d %s", w.Code, w.Body.String())
+	}
+
+	w = do(t, r, f.teacher, http.MethodPost, base+"/invites", InviteRequest{PhoneNumber: "+97688000009"})
+	if w.Code != http.StatusAccepted || apitest.Decode[InviteResponse](t, w).Status != "invited" {
+		t.Fatalf("invite: %d %s", w.Code, w.Body.String())
+	}
+
+	due := f.now.Add(7 * 24 * time.Hour)
+	w = do(t, r, f.teacher, http.MethodPost, base+"/assignments", AssignRequest{TopicIDs: []string{"articles"}, DueAt: due})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("assign: %d %s", w.Code, w.Body.String())
+	}
+	assignment := apitest.Decode[Assignment](t, w)
+
+	w = do(t, r, f.teacher, http.MethodGet, base+"/assignments/"+strconv.FormatUint(assignment.ID, 10)+"/completion", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("completion: %d %s", w.Code, w.Body.String())
+	}
+	completion := apitest.Decode[[]StudentCompletion](t, w)
+	if len(completion) != 1 || completion[0].StudentID != f.student.ID || completion[0].Completed {
+		t.Fatalf("unexpected completion %+v", completion)
+	}
+
+	w = do(t, r, f.student, http.MethodGet, "/classrooms", nil)
+	if mine := apitest.Decode[[]Classroom](t, w); len(mine) != 1 || mine[0].ID != class.ID {
+		t.Fatalf("expected the student's class, got %+v", mine)
+	}
+}
+
+func TestInviteAcceptFlow(t *testing.T) {
+	f := newFixture(t)
+	r := newTestRouter(f)
+	class := f.classroom(t)
+	base := "/teacher/classrooms/" + strconv.FormatUint(class.ID, 10)
+
+	w := do(t, r, f.teacher, http.MethodPost, base+"/invites", InviteRequest{PhoneNumber: f.student.PhoneNumber})
+	if w.Code != http.StatusAccepted || apitest.Decode[InviteResponse](t, w).Status != "invited" {
+		t.Fatalf("invite: %d %s", w.Code, w.Body.String())
+	}
+	if w := do(t, r, f.teacher, http.MethodGet, base+"/students/"+strconv.FormatUint(f.student.ID, 10), nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected no report before accepting, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = do(t, r, f.student, http.MethodGet, "/classrooms/invites", nil)
+	if invites := apitest.Decode[[]Classroom](t, w); len(invites) != 1 || invites[0].ID != class.ID {
+		t.Fatalf("expected the pending invite, got %+v", invites)
+	}
+
+	accept := "/classrooms/invites/" + strconv.FormatUint(class.ID, 10) + "/accept"
+	if w := do(t, r, f.student, http.MethodPost, accept, nil); w.Code != http.StatusOK {
+		t.Fatalf("accept: %d %s", w.Code, w.Body.String())
+	}
+	if w := do(t, r, f.student, http.MethodPost, accept, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected a second accept to find no invite, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = do(t, r, f.student, http.MethodGet, "/classrooms", nil)
+	if mine := apitest.Decode[[]Classroom](t, w); len(mine) != 1 || mine[0].ID != class.ID {
+		t.Fatalf("expected the accepted class, got %+v", mine)
+	}
+}
+
+func TestClassroomAccess(t *testing.T) {
+	f := newFixture(t)
+	r := newTestRouter(f)
+	class := f.classroom(t)
+	base := "/teacher/classrooms/" + strconv.FormatUint(class.ID, 10)
+
+	// Students cannot reach teacher routes at all.
+	for _, path := range []string{"/teacher/classrooms", base + "/members", base + "/assignments", base + "/report"} {
+		if w := do(t, r, f.student, http.MethodGet, path, nil); w.Code != http.StatusForbidden {
+			t.Fatalf("%s: expected 403, got %d %s", path, w.Code, w.Body.String())
+		}
+	}
+
+	// Other teachers see the class as missing.
+	other := users.NewUser("+97699000002")
+	other.Role = users.RoleTeacher
+	other, _ = f.users.Create(t.Context(), other)
+
+	w := do(t, r, other, http.MethodGet, base+"/members", nil)
+	if body := apitest.Decode[httperr.Envelope](t, w); w.Code != http.StatusNotFound || body.Code != "classroom_not_found" {
+		t.Fatalf("expected classroom_not_found, got %d %+v", w.Code, body)
+	}
+
+	if w := do(t, r, f.teacher, http.MethodGet, "/teacher/classrooms/abc/members", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected malformed IDs to be 404, got %d", w.Code)
+	}
+}
+
+func TestDashboardRoutes(t *testing.T) {
+	f := newFixture(t)
+	r := newTestRouter(f)
+	class := f.classroom(t)
+	f.service.Join(t.Context(), f.student, class.JoinCode)
+	f.answer(t, f.student, "articles", 1, false)
+
+	base := "/teacher/classrooms/" + strconv.FormatUint(class.ID, 10)
+
+	w := do(t, r, f.teacher, http.MethodGet, base+"/report", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("report: %d %s", w.Code, w.Body.String())
+	}
+	if report := apitest.Decode[ClassReport](t, w); len(report.MostMissed) != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	w = do(t, r, f.teacher, http.MethodGet, base+"/students/"+strconv.FormatUint(f.student.ID, 10), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("student: %d %s", w.Code, w.Body.String())
+	}
+	if report := apitest.Decode[StudentReport](t, w); len(report.History["articles"]) != 1 {
+		t.Fatalf("unexpected student report %+v", report)
+	}
+
+	w = do(t, r, f.teacher, http.MethodGet, base+"/students/999", nil)
+	if body := apitest.Decode[httperr.Envelope](t, w); w.Code != http.StatusNotFound || body.Code != "student_not_found" {
+		t.Fatalf("expected student_not_found, got %d %+v", w.Code, body)
+	}
+}
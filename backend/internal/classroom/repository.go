@@ -0,0 +1,242 @@
+package classroom
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// ClassroomRepository stores classrooms, their members and pending
+// phone invites.
+type ClassroomRepository interface {
+	// Create assigns the ID. Join codes are unique.
+	Create(ctx context.Context, c Classroom) (Classroom, error)
+	GetByID(ctx context.Context, id uint64) (Classroom, error)
+	GetByJoinCode(ctx context.Context, code string) (Classroom, error)
+	ListByTeacher(ctx context.Context, teacherID uint64) ([]Classroom, error)
+	ListByStudent(ctx context.Context, studentID uint64) ([]Classroom, error)
+
+	// AddMember is a no-op for existing members.
+	AddMember(ctx context.Context, m Membership) error
+	ListMembers(ctx context.Context, classroomID uint64) ([]Membership, error)
+
+	// AddInvite records a pending invite for a phone number, and is a
+	// no-op for one already pending. ListInvites returns the invited
+	// classroom IDs; TakeInvite removes one, or returns
+	// ErrInviteNotFound.
+	AddInvite(ctx context.Context, classroomID uint64, phone string) error
+	ListInvites(ctx context.Context, phone string) ([]uint64, error)
+	TakeInvite(ctx context.Context, classroomID uint64, phone string) error
+}
+
+// AssignmentRepository stores topic assignments.
+type AssignmentRepository interface {
+	// Create assigns the ID.
+	Create(ctx context.Context, a Assignment) (Assignment, error)
+	GetByID(ctx context.Context, id uint64) (Assignment, error)
+	ListByClassroom(ctx context.Context, classroomID uint64) ([]Assignment, error)
+}
+
+// ---------------- In-memory classrooms ----------------
+//
+// Lists are ordered by ID (members by student ID).
+
+type MemoryClassroomRepository struct {
+	mu         sync.RWMutex
+	classrooms map[uint64]Classroom
+	members    map[uint64]map[uint64]Membership // classroom -> student -> membership
+	invites    map[string][]uint64              // phone -> classroom IDs
+	nextID     uint64
+}
+
+func NewMemoryClassroomRepository() *MemoryClassroomRepository {
+	return &MemoryClassroomRepository{
+		classrooms: make(map[uint64]Classroom),
+		members:    make(map[uint64]map[uint64]Membership),
+		invites:    make(map[string][]uint64),
+		nextID:     1,
+	}
+}
+
+func (r *MemoryClassroomRepository) Create(ctx context.Context, c Classroom) (Classroom, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.classrooms {
+		if existing.JoinCode == c.JoinCode {
+			return Classroom{}, ErrJoinCodeTaken
+		}
+	}
+
+	c.ID = r.nextID
+	r.nextID++
+	r.classrooms[c.ID] = c
+	return c, nil
+}
+
+func (r *MemoryClassroomRepository) GetByID(ctx context.Context, id uint64) (Classroom, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.classrooms[id]
+	if !ok {
+		return Classroom{}, ErrClassroomNotFound
+	}
+	return c, nil
+}
+
+func (r *MemoryClassroomRepository) GetByJoinCode(ctx context.Context, code string) (Classroom, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, c := range r.classrooms {
+		if c.JoinCode == code {
+			return c, nil
+		}
+	}
+	return Classroom{}, ErrClassroomNotFound
+}
+
+func (r *MemoryClassroomRepository) ListByTeacher(ctx context.Context, teacherID uint64) ([]Classroom, error) {
+	return r.list(func(c Classroom) bool { return c.TeacherID == teacherID }), nil
+}
+
+func (r *MemoryClassroomRepository) ListByStudent(ctx context.Context, studentID uint64) ([]Classroom, error) {
+	return r.list(func(c Classroom) bool {
+		_, ok := r.members[c.ID][studentID]
+		return ok
+	}), nil
+}
+
+func (r *MemoryClassroomRepository) list(keep func(Classroom) bool) []Classroom {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]Classroom, 0)
+	for _, c := range r.classrooms {
+		if keep(c) {
+			out = append(out, c)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+func (r *MemoryClassroomRepository) AddMember(ctx context.Context, m Membership) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.classrooms[m.ClassroomID]; !ok {
+		return ErrClassroomNotFound
+	}
+	if r.members[m.ClassroomID] == nil {
+		r.members[m.ClassroomID] = make(map[uint64]Membership)
+	}
+	if _, ok := r.members[m.ClassroomID][m.StudentID]; !ok {
+		r.members[m.ClassroomID][m.StudentID] = m
+	}
+	return nil
+}
+
+func (r *MemoryClassroomRepository) ListMembers(ctx context.Context, classroomID uint64) ([]Membership, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]Membership, 0, len(r.members[classroomID]))
+	for _, m := range r.members[classroomID] {
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].StudentID < out[j].StudentID })
+	return out, nil
+}
+
+func (r *MemoryClassroomRepository) AddInvite(ctx context.Context, classroomID uint64, phone string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.classrooms[classroomID]; !ok {
+		return ErrClassroomNotFound
+	}
+	if !slices.Contains(r.invites[phone], classroomID) {
+		r.invites[phone] = append(r.invites[phone], classroomID)
+	}
+	return nil
+}
+
+func (r *MemoryClassroomRepository) ListInvites(ctx context.Context, phone string) ([]uint64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := append([]uint64(nil), r.invites[phone]...)
+	slices.Sort(ids)
+	return ids, nil
+}
+
+func (r *MemoryClassroomRepository) TakeInvite(ctx context.Context, classroomID uint64, phone string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := slices.Index(r.invites[phone], classroomID)
+	if i < 0 {
+		return ErrInviteNotFound
+	}
+	r.invites[phone] = slices.Delete(r.invites[phone], i, i+1)
+	if len(r.invites[phone]) == 0 {
+		delete(r.invites, phone)
+	}
+	return nil
+}
+
+// ---------------- In-memory assignments ----------------
+
+type MemoryAssignmentRepository struct {
+	mu          sync.RWMutex
+	assignments map[uint64]Assignment
+	nextID      uint64
+}
+
+func NewMemoryAssignmentRepository() *MemoryAssignmentRepository {
+	return &MemoryAssignmentRepository{
+		assignments: make(map[uint64]Assignment),
+		nextID:      1,
+	}
+}
+
+func (r *MemoryAssignmentRepository) Create(ctx context.Context, a Assignment) (Assignment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a.ID = r.nextID
+	a.TopicIDs = slices.Clone(a.TopicIDs)
+	r.nextID++
+	r.assignments[a.ID] = a
+	return a, nil
+}
+
+func (r *MemoryAssignmentRepository) GetByID(ctx context.Context, id uint64) (Assignment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	a, ok := r.assignments[id]
+	if !ok {
+		return Assignment{}, ErrAssignmentNotFound
+	}
+	a.TopicIDs = slices.Clone(a.TopicIDs)
+	return a, nil
+}
+
+func (r *MemoryAssignmentRepository) ListByClassroom(ctx context.Context, classroomID uint64) ([]Assignment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]Assignment, 0)
+	for _, a := range r.assignments {
+		if a.ClassroomID == classroomID {
+			a.TopicIDs = slices.Clone(a.TopicIDs)
+			out = append(out, a)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
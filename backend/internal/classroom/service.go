@@ -0,0 +1,373 @@
+package classroom
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bugii1995/backend/internal/httperr"
+	"github.com/bugii1995/backend/internal/quiz"
+	"github.com/bugii1995/backend/internal/users"
+)
+
+const (
+	JoinCodeLength = 6
+
+	// CompletionMastery is the mastery at which an assigned topic
+	// counts as done (the "confident" band of question selection).
+	CompletionMastery = 80.0
+)
+
+// Join codes avoid look-alike characters (0/O, 1/I/L).
+const joinCodeAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+// Service holds the classroom rules. It implements quiz.TopicScope.
+type Service struct {
+	classrooms  ClassroomRepository
+	assignments AssignmentRepository
+	users       users.UserRepository
+	progress    quiz.ProgressRepository
+	topics      quiz.TopicLookup
+	pedagogy    *quiz.PedagogyStore
+
+	now          func() time.Time
+	generateCode func() (string, error)
+}
+
+// NewService builds the service. Assignments may only name topics that
+// topics knows. Reports decay mastery with the config pedagogy holds, or
+// with the defaults if it is nil.
+func NewService(
+	classrooms ClassroomRepository,
+	assignments AssignmentRepository,
+	userRepo users.UserRepository,
+	progress quiz.ProgressRepository,
+	topics quiz.TopicLookup,
+	pedagogy *quiz.PedagogyStore,
+) *Service {
+	return &Service{
+		classrooms:   classrooms,
+		assignments:  assignments,
+		users:        userRepo,
+		progress:     progress,
+		topics:       topics,
+		pedagogy:     pedagogy,
+		now:          time.Now,
+		generateCode: generateJoinCode,
+	}
+}
+
+// ---------------- Teachers ----------------
+
+// CreateClassroom creates a class owned by teacher with a fresh join code.
+func (s *Service) CreateClassroom(ctx context.Context, teacher users.User, name string) (Classroom, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Classroom{}, ErrInvalidClassroom
+	}
+
+	for {
+		code, err := s.generateCode()
+		if err != nil {
+			return Classroom{}, err
+		}
+
+		c, err := s.classrooms.Create(ctx, Classroom{
+			TeacherID: teacher.ID,
+			Name:      name,
+			JoinCode:  code,
+			CreatedAt: s.now(),
+		})
+		if errors.Is(err, ErrJoinCodeTaken) {
+			continue
+		}
+		return c, err
+	}
+}
+
+// TeacherClassroom returns classroom id if teacher owns it. Other
+// teachers' classes are reported as missing.
+func (s *Service) TeacherClassroom(ctx context.Context, teacher users.User, id uint64) (Classroom, error) {
+	c, err := s.classrooms.GetByID(ctx, id)
+	if err != nil {
+		return Classroom{}, err
+	}
+	if c.TeacherID != teacher.ID {
+		return Classroom{}, ErrClassroomNotFound
+	}
+	return c, nil
+}
+
+func (s *Service) TeacherClassrooms(ctx context.Context, teacher users.User) ([]Classroom, error) {
+	return s.classrooms.ListByTeacher(ctx, teacher.ID)
+}
+
+// Invite invites the student with phone to the class. The invite
+// waits, also for numbers without an account yet, until the student
+// accepts it, so no teacher sees a student's progress without consent.
+func (s *Service) Invite(ctx context.Context, teacher users.User, classroomID uint64, phone string) error {
+	if !users.IsValidMongoliaPhone(phone) {
+		return users.ErrInvalidPhone
+	}
+	if _, err := s.TeacherClassroom(ctx, teacher, classroomID); err != nil {
+		return err
+	}
+	return s.classrooms.AddInvite(ctx, classroomID, phone)
+}
+
+func (s *Service) Members(ctx context.Context, teacher users.User, classroomID uint64) ([]Membership, error) {
+	if _, err := s.TeacherClassroom(ctx, teacher, classroomID); err != nil {
+		return nil, err
+	}
+	return s.classrooms.ListMembers(ctx, classroomID)
+}
+
+// Assign asks the class to practise topicIDs until dueAt. Topics the
+// topic lookup does not know are refused.
+func (s *Service) Assign(
+	ctx context.Context,
+	teacher users.User,
+	classroomID uint64,
+	topicIDs []string,
+	dueAt time.Time,
+) (Assignment, error) {
+
+	if _, err := s.TeacherClassroom(ctx, teacher, classroomID); err != nil {
+		return Assignment{}, err
+	}
+
+	topics := make([]string, 0, len(topicIDs))
+	for _, id := range topicIDs {
+		if id = strings.TrimSpace(id); id != "" && !slices.Contains(topics, id) {
+			topics = append(topics, id)
+		}
+	}
+	now := s.now()
+	if len(topics) == 0 || !dueAt.After(now) {
+		return Assignment{}, ErrInvalidAssignment
+	}
+
+	var unknown []string
+	for _, id := range topics {
+		ok, err := s.topics.TopicExists(ctx, id)
+		if err != nil {
+			return Assignment{}, err
+		}
+		if !ok {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 {
+		return Assignment{}, httperr.WithDetails(ErrInvalidAssignment, map[string]any{"unknown_topic_ids": unknown})
+	}
+
+	return s.assignments.Create(ctx, Assignment{
+		ClassroomID: classroomID,
+		TopicIDs:    topics,
+		DueAt:       dueAt,
+		CreatedAt:   now,
+	})
+}
+
+func (s *Service) Assignments(ctx context.Context, teacher users.User, classroomID uint64) ([]Assignment, error) {
+	if _, err := s.TeacherClassroom(ctx, teacher, classroomID); err != nil {
+		return nil, err
+	}
+	return s.assignments.ListByClassroom(ctx, classroomID)
+}
+
+// StudentCompletion is one student's progress on an assignment.
+type StudentCompletion struct {
+	StudentID       uint64   `json:"student_id"`
+	PhoneNumber     string   `json:"phone_number"`
+	CompletedTopics []string `json:"completed_topics"`
+	TotalTopics     int      `json:"total_topics"`
+	Completed       bool     `json:"completed"`
+}
+
+// Completion reports, per member, which assigned topics are at
+// CompletionMastery or mastered, after decay up to now.
+func (s *Service) Completion(
+	ctx context.Context,
+	teacher users.User,
+	classroomID, assignmentID uint64,
+) ([]StudentCompletion, error) {
+
+	assignment, err := s.classAssignment(ctx, teacher, classroomID, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.classrooms.ListMembers(ctx, classroomID)
+	if err != nil {
+		return nil, err
+	}
+
+	mastery, now := s.mastery(), s.now()
+	out := make([]StudentCompletion, 0, len(members))
+	for _, m := range members {
+		student, err := s.users.GetByID(ctx, m.StudentID)
+		if err != nil {
+			return nil, err
+		}
+		progress, err := s.progress.ListProgress(ctx, m.StudentID)
+		if err != nil {
+			return nil, err
+		}
+
+		done := make([]string, 0)
+		for _, p := range progress {
+			if slices.Contains(assignment.TopicIDs, p.TopicID) &&
+				(mastery.ProjectedMastered(p, now) || mastery.ProjectedMastery(p, now) >= CompletionMastery) {
+				done = append(done, p.TopicID)
+			}
+		}
+
+		out = append(out, StudentCompletion{
+			StudentID:       m.StudentID,
+			PhoneNumber:     student.PhoneNumber,
+			CompletedTopics: done,
+			TotalTopics:     len(assignment.TopicIDs),
+			Completed:       len(done) == len(assignment.TopicIDs),
+		})
+	}
+	return out, nil
+}
+
+// classAssignment returns assignmentID if it belongs to a class that
+// teacher owns.
+func (s *Service) classAssignment(
+	ctx context.Context,
+	teacher users.User,
+	classroomID, assignmentID uint64,
+) (Assignment, error) {
+
+	if _, err := s.TeacherClassroom(ctx, teacher, classroomID); err != nil {
+		return Assignment{}, err
+	}
+	a, err := s.assignments.GetByID(ctx, assignmentID)
+	if err != nil {
+		return Assignment{}, err
+	}
+	if a.ClassroomID != classroomID {
+		return Assignment{}, ErrAssignmentNotFound
+	}
+	return a, nil
+}
+
+// ---------------- Students ----------------
+
+// Join adds student to the class with code.
+func (s *Service) Join(ctx context.Context, student users.User, code string) (Classroom, error) {
+	c, err := s.classrooms.GetByJoinCode(ctx, strings.ToUpper(strings.TrimSpace(code)))
+	if errors.Is(err, ErrClassroomNotFound) {
+		return Classroom{}, ErrInvalidJoinCode
+	}
+	if err != nil {
+		return Classroom{}, err
+	}
+
+	err = s.classrooms.AddMember(ctx, Membership{
+		ClassroomID: c.ID,
+		StudentID:   student.ID,
+		JoinedAt:    s.now(),
+	})
+	return c, err
+}
+
+// StudentClassrooms lists the classes student belongs to.
+func (s *Service) StudentClassrooms(ctx context.Context, student users.User) ([]Classroom, error) {
+	return s.classrooms.ListByStudent(ctx, student.ID)
+}
+
+// Invites lists the classes student's phone number is invited to.
+func (s *Service) Invites(ctx context.Context, student users.User) ([]Classroom, error) {
+	if student.PhoneNumber == "" {
+		return []Classroom{}, nil
+	}
+
+	ids, err := s.classrooms.ListInvites(ctx, student.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Classroom, 0, len(ids))
+	for _, id := range ids {
+		c, err := s.classrooms.GetByID(ctx, id)
+		if errors.Is(err, ErrClassroomNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// AcceptInvite adds student to a class their phone number is invited
+// to.
+func (s *Service) AcceptInvite(ctx context.Context, student users.User, classroomID uint64) (Classroom, error) {
+	if student.PhoneNumber == "" {
+		return Classroom{}, ErrInviteNotFound
+	}
+	c, err := s.classrooms.GetByID(ctx, classroomID)
+	if errors.Is(err, ErrClassroomNotFound) {
+		return Classroom{}, ErrInviteNotFound
+	}
+	if err != nil {
+		return Classroom{}, err
+	}
+
+	if err := s.classrooms.TakeInvite(ctx, classroomID, student.PhoneNumber); err != nil {
+		return Classroom{}, err
+	}
+	err = s.classrooms.AddMember(ctx, Membership{
+		ClassroomID: c.ID,
+		StudentID:   student.ID,
+		JoinedAt:    s.now(),
+	})
+	return c, err
+}
+
+// AssignedTopics implements quiz.TopicScope: a student in a class
+// practises the topics of its open assignments. Students without an
+// open assignment are unrestricted.
+func (s *Service) AssignedTopics(ctx context.Context, student users.User) ([]string, bool, error) {
+	classes, err := s.StudentClassrooms(ctx, student)
+	if err != nil {
+		return nil, false, err
+	}
+
+	now := s.now()
+	var topics []string
+	for _, c := range classes {
+		assignments, err := s.assignments.ListByClassroom(ctx, c.ID)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, a := range assignments {
+			if !a.IsOpen(now) {
+				continue
+			}
+			for _, id := range a.TopicIDs {
+				if !slices.Contains(topics, id) {
+					topics = append(topics, id)
+				}
+			}
+		}
+	}
+	return topics, len(topics) > 0, nil
+}
+
+func generateJoinCode() (string, error) {
+	buf := make([]byte, JoinCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		buf[i] = joinCodeAlphabet[int(b)%len(joinCodeAlphabet)]
+	}
+	return string(buf), nil
+}
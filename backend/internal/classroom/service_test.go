@@ -0,0 +1,282 @@
+package classroom
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bugii1995/backend/internal/quiz"
+	"github.com/bugii1995/backend/internal/users"
+)
+
+type fixture struct {
+	service  *Service
+	users    *users.MemoryUserRepository
+	progress *quiz.MemoryProgressRepository
+	now      time.Time
+
+	teacher users.User
+	student users.User
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	ctx := context.Background()
+
+	f := &fixture{
+		users:    users.NewMemoryUserRepository(),
+		progress: quiz.NewMemoryProgressRepository(),
+		now:      time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+	}
+	f.service = NewService(NewMemoryClassroomRepository(), NewMemoryAssignmentRepository(), f.users, f.progress, quiz.NewTopicSet("articles", "conditionals"), nil)
+	f.service.now = func() time.Time { return f.now }
+
+	teacher := users.NewUser("+97699000001")
+	teacher.Role = users.RoleTeacher
+	f.teacher, _ = f.users.Create(ctx, teacher)
+	f.student, _ = f.users.Create(ctx, users.NewUser("+97688000001"))
+	return f
+}
+
+func (f *fixture) classroom(t *testing.T) Classroom {
+	t.Helper()
+
+	c, err := f.service.CreateClassroom(context.Background(), f.teacher, "Grade 9B")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestJoinByCode(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	c := f.classroom(t)
+
+	if len(c.JoinCode) != JoinCodeLength {
+		t.Fatalf("expected a %d character join code, got %q", JoinCodeLength, c.JoinCode)
+	}
+
+	if _, err := f.service.Join(ctx, f.student, "nope"); !errors.Is(err, ErrInvalidJoinCode) {
+		t.Fatalf("expected ErrInvalidJoinCode, got %v", err)
+	}
+
+	// Codes are case-insensitive and joining twice is harmless.
+	for range 2 {
+		joined, err := f.service.Join(ctx, f.student, " "+strings.ToLower(c.JoinCode)+" ")
+		if err != nil || joined.ID != c.ID {
+			t.Fatalf("expected to join %d, got %+v %v", c.ID, joined, err)
+		}
+	}
+
+	members, _ := f.service.Members(ctx, f.teacher, c.ID)
+	if len(members) != 1 || members[0].StudentID != f.student.ID {
+		t.Fatalf("expected one member, got %+v", members)
+	}
+}
+
+func TestJoinCodeCollisionRetries(t *testing.T) {
+	f := newFixture(t)
+
+	codes := []string{"AAAAAA", "AAAAAA", "BBBBBB"}
+	f.service.generateCode = func() (string, error) {
+		code := codes[0]
+		codes = codes[1:]
+		return code, nil
+	}
+
+	first := f.classroom(t)
+	second := f.classroom(t)
+	if first.JoinCode != "AAAAAA" || second.JoinCode != "BBBBBB" {
+		t.Fatalf("expected a retry on collision, got %q and %q", first.JoinCode, second.JoinCode)
+	}
+}
+
+func TestInviteByPhone(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	c := f.classroom(t)
+
+	// An existing student is only invited, not added.
+	if err := f.service.Invite(ctx, f.teacher, c.ID, f.student.PhoneNumber); err != nil {
+		t.Fatal(err)
+	}
+	if members, _ := f.service.Members(ctx, f.teacher, c.ID); len(members) != 0 {
+		t.Fatalf("expected no member before accepting, got %+v", members)
+	}
+	if _, err := f.service.StudentReport(ctx, f.teacher, c.ID, f.student.ID); !errors.Is(err, ErrStudentNotFound) {
+		t.Fatalf("expected no report before accepting, got %v", err)
+	}
+
+	invites, err := f.service.Invites(ctx, f.student)
+	if err != nil || len(invites) != 1 || invites[0].ID != c.ID {
+		t.Fatalf("expected the pending invite, got %+v %v", invites, err)
+	}
+	if _, err := f.service.AcceptInvite(ctx, f.student, c.ID); err != nil {
+		t.Fatal(err)
+	}
+	classes, err := f.service.StudentClassrooms(ctx, f.student)
+	if err != nil || len(classes) != 1 || classes[0].ID != c.ID {
+		t.Fatalf("expected the accepted class, got %+v %v", classes, err)
+	}
+	if _, err := f.service.AcceptInvite(ctx, f.student, c.ID); !errors.Is(err, ErrInviteNotFound) {
+		t.Fatalf("expected the invite used up, got %v", err)
+	}
+
+	// Unknown numbers wait until the student signs up and accepts.
+	newcomerPhone := "+97688000002"
+	if err := f.service.Invite(ctx, f.teacher, c.ID, newcomerPhone); err != nil {
+		t.Fatal(err)
+	}
+	newcomer, _ := f.users.Create(ctx, users.NewUser(newcomerPhone))
+
+	if classes, _ := f.service.StudentClassrooms(ctx, newcomer); len(classes) != 0 {
+		t.Fatalf("expected no class before accepting, got %+v", classes)
+	}
+	if _, err := f.service.AcceptInvite(ctx, f.student, c.ID); !errors.Is(err, ErrInviteNotFound) {
+		t.Fatalf("expected another phone's invite refused, got %v", err)
+	}
+	if _, err := f.service.AcceptInvite(ctx, newcomer, c.ID); err != nil {
+		t.Fatal(err)
+	}
+	if members, _ := f.service.Members(ctx, f.teacher, c.ID); len(members) != 2 {
+		t.Fatalf("expected both students to be members, got %+v", members)
+	}
+
+	if err := f.service.Invite(ctx, f.teacher, c.ID, "12345"); !errors.Is(err, users.ErrInvalidPhone) {
+		t.Fatalf("expected ErrInvalidPhone, got %v", err)
+	}
+}
+
+func TestOtherTeachersCannotManageClass(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	c := f.classroom(t)
+
+	other := users.User{ID: 99, Role: users.RoleTeacher}
+
+	if err := f.service.Invite(ctx, other, c.ID, f.student.PhoneNumber); !errors.Is(err, ErrClassroomNotFound) {
+		t.Fatalf("invite: expected ErrClassroomNotFound, got %v", err)
+	}
+	if _, err := f.service.Assign(ctx, other, c.ID, []string{"articles"}, f.now.Add(time.Hour)); !errors.Is(err, ErrClassroomNotFound) {
+		t.Fatalf("assign: expected ErrClassroomNotFound, got %v", err)
+	}
+	if _, err := f.service.Members(ctx, other, c.ID); !errors.Is(err, ErrClassroomNotFound) {
+		t.Fatalf("members: expected ErrClassroomNotFound, got %v", err)
+	}
+}
+
+func TestAssignValidation(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	c := f.classroom(t)
+
+	tests := []struct {
+		name   string
+		topics []string
+		due    time.Time
+	}{
+		{"no topics", nil, f.now.Add(time.Hour)},
+		{"blank topics", []string{" ", ""}, f.now.Add(time.Hour)},
+		{"unknown topic", []string{"articles", "artcles"}, f.now.Add(time.Hour)},
+		{"due now", []string{"articles"}, f.now},
+		{"due in the past", []string{"articles"}, f.now.Add(-time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := f.service.Assign(ctx, f.teacher, c.ID, tt.topics, tt.due); !errors.Is(err, ErrInvalidAssignment) {
+				t.Fatalf("expected ErrInvalidAssignment, got %v", err)
+			}
+		})
+	}
+
+	a, err := f.service.Assign(ctx, f.teacher, c.ID, []string{"articles", " articles", "conditionals"}, f.now.Add(time.Hour))
+	if err != nil || !slices.Equal(a.TopicIDs, []string{"articles", "conditionals"}) {
+		t.Fatalf("expected de-duplicated topics, got %+v %v", a, err)
+	}
+}
+
+func TestAssignedTopicsFollowOpenAssignments(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	c := f.classroom(t)
+
+	if _, ok, _ := f.service.AssignedTopics(ctx, f.student); ok {
+		t.Fatal("students outside a class must be unrestricted")
+	}
+
+	f.service.Join(ctx, f.student, c.JoinCode)
+	f.service.Assign(ctx, f.teacher, c.ID, []string{"articles"}, f.now.Add(48*time.Hour))
+	f.service.Assign(ctx, f.teacher, c.ID, []string{"conditionals", "articles"}, f.now.Add(24*time.Hour))
+
+	topics, ok, err := f.service.AssignedTopics(ctx, f.student)
+	if err != nil || !ok || !slices.Equal(topics, []string{"articles", "conditionals"}) {
+		t.Fatalf("expected both assignments, got %v %v %v", topics, ok, err)
+	}
+
+	f.now = f.now.Add(24 * time.Hour)
+	topics, _, _ = f.service.AssignedTopics(ctx, f.student)
+	if !slices.Equal(topics, []string{"articles"}) {
+		t.Fatalf("expected past-due assignment to lapse, got %v", topics)
+	}
+
+	f.now = f.now.Add(24 * time.Hour)
+	if _, ok, _ := f.service.AssignedTopics(ctx, f.student); ok {
+		t.Fatal("expected no restriction once every assignment is due")
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	c := f.classroom(t)
+
+	other, _ := f.users.Create(ctx, users.NewUser("+97688000002"))
+	f.service.Join(ctx, f.student, c.JoinCode)
+	f.service.Join(ctx, other, c.JoinCode)
+
+	a, _ := f.service.Assign(ctx, f.teacher, c.ID, []string{"articles", "conditionals"}, f.now.Add(time.Hour))
+
+	f.progress.SaveProgress(ctx, f.student.ID, quiz.TopicProgress{TopicID: "articles", Mastery: CompletionMastery})
+	f.progress.SaveProgress(ctx, f.student.ID, quiz.TopicProgress{TopicID: "conditionals", Mastery: 92, IsMastered: true})
+	f.progress.SaveProgress(ctx, other.ID, quiz.TopicProgress{TopicID: "articles", Mastery: 85})
+	f.progress.SaveProgress(ctx, other.ID, quiz.TopicProgress{TopicID: "conditionals", Mastery: 79.9})
+	f.progress.SaveProgress(ctx, other.ID, quiz.TopicProgress{TopicID: "tenses", Mastery: 100})
+
+	got, err := f.service.Completion(ctx, f.teacher, c.ID, a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected two students, got %+v", got)
+	}
+	if !got[0].Completed || len(got[0].CompletedTopics) != 2 || got[0].PhoneNumber != f.student.PhoneNumber {
+		t.Fatalf("expected %d to be done, got %+v", f.student.ID, got[0])
+	}
+	if got[1].Completed || !slices.Equal(got[1].CompletedTopics, []string{"articles"}) || got[1].TotalTopics != 2 {
+		t.Fatalf("expected %d to be half done, got %+v", other.ID, got[1])
+	}
+
+	// Mastery decays: two months on, a mastered topic left alone is
+	// neither done nor mastered any more.
+	f.progress.SaveProgress(ctx, f.student.ID, quiz.TopicProgress{TopicID: "conditionals", Mastery: 100, IsMastered: true, LastSeen: f.now.Add(-60 * 24 * time.Hour)})
+	got, _ = f.service.Completion(ctx, f.teacher, c.ID, a.ID)
+	if got[0].Completed || !slices.Equal(got[0].CompletedTopics, []string{"articles"}) {
+		t.Fatalf("expected the decayed topic to be undone, got %+v", got[0])
+	}
+	report, _ := f.service.ClassReport(ctx, f.teacher, c.ID)
+	for _, topic := range report.Topics {
+		if topic.TopicID == "conditionals" && topic.MasteredShare != 0 {
+			t.Fatalf("expected no student to still master conditionals, got %+v", topic)
+		}
+	}
+
+	// Assignments are only reachable through their own class.
+	otherClass := f.classroom(t)
+	if _, err := f.service.Completion(ctx, f.teacher, otherClass.ID, a.ID); !errors.Is(err, ErrAssignmentNotFound) {
+		t.Fatalf("expected ErrAssignmentNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+// Package entitlements decides what free and paid accounts may do:
+// daily quotas for free accounts and topics reserved for paid ones.
+package entitlements
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/bugii1995/backend/internal/httperr"
+	"github.com/bugii1995/backend/internal/users"
+)
+
+var (
+	ErrQuotaExceeded   = errors.New("daily limit reached; upgrade for unlimited practice")
+	ErrUpgradeRequired = errors.New("this topic is available on paid accounts")
+)
+
+// HTTPErrors maps entitlement errors for httperr.Middleware.
+var HTTPErrors = []httperr.Rule{
+	{Err: ErrQuotaExceeded, Status: http.StatusTooManyRequests, Code: "quota_exceeded"},
+	{Err: ErrUpgradeRequired, Status: http.StatusPaymentRequired, Code: "upgrade_required"},
+}
+
+// Kind is a separately metered kind of usage.
+type Kind string
+
+const (
+	KindQuestion Kind = "question" // new learning, reinforcement, stretch
+	KindReview   Kind = "review"   // spaced-repetition reviews
+)
+
+// Plan is what a free account gets. Paid accounts are unlimited and
+// may open every topic.
+type Plan struct {
+	FreeDailyQuestions int
+	FreeDailyReviews   int
+	PaidOnlyTopics     []string
+}
+
+func DefaultPlan() Plan {
+	return Plan{
+		FreeDailyQuestions: 20,
+		FreeDailyReviews:   5,
+	}
+}
+
+// Days roll over at midnight Mongolia time (UTC+8, no DST).
+var dayZone = time.FixedZone("ULAT", 8*60*60)
+
+// Service enforces a Plan using persisted daily counters. A nil
+// *Service enforces nothing: every account is treated as paid.
+type Service struct {
+	quotas QuotaRepository
+	plan   Plan
+	now    func() time.Time
+}
+
+func NewService(quotas QuotaRepository, plan Plan) *Service {
+	return &Service{quotas: quotas, plan: plan, now: time.Now}
+}
+
+// CanAccessTopic reports whether u may practise topicID.
+func (s *Service) CanAccessTopic(u users.User, topicID string) bool {
+	return s.unmetered(u) || !slices.Contains(s.plan.PaidOnlyTopics, topicID)
+}
+
+// CheckTopics returns ErrUpgradeRequired, with the locked topics as
+// details, if u may not practise every one of topicIDs.
+func (s *Service) CheckTopics(u users.User, topicIDs []string) error {
+	var locked []string
+	for _, id := range topicIDs {
+		if !s.CanAccessTopic(u, id) {
+			locked = append(locked, id)
+		}
+	}
+	if len(locked) > 0 {
+		return httperr.WithDetails(ErrUpgradeRequired, map[string]any{"topic_ids": locked})
+	}
+	return nil
+}
+
+// Check returns ErrQuotaExceeded if u has no kind left today, without
+// using any.
+func (s *Service) Check(ctx context.Context, u users.User, kind Kind) error {
+	if s.unmetered(u) {
+		return nil
+	}
+
+	limit := s.limit(kind)
+	used, err := s.quotas.Used(ctx, u.ID, s.day(), kind)
+	if err != nil {
+		return err
+	}
+	if used >= limit {
+		return s.exceeded(kind, limit)
+	}
+	return nil
+}
+
+// Remaining returns how much of kind u has left today. Paid accounts
+// are unlimited and get math.MaxInt.
+func (s *Service) Remaining(ctx context.Context, u users.User, kind Kind) (int, error) {
+	if s.unmetered(u) {
+		return math.MaxInt, nil
+	}
+
+	used, err := s.quotas.Used(ctx, u.ID, s.day(), kind)
+	if err != nil {
+		return 0, err
+	}
+	return max(s.limit(kind)-used, 0), nil
+}
+
+// Consume uses one unit of u's kind quota for today, or returns
+// ErrQuotaExceeded.
+func (s *Service) Consume(ctx context.Context, u users.User, kind Kind) error {
+	if s.unmetered(u) {
+		return nil
+	}
+
+	limit := s.limit(kind)
+	ok, err := s.quotas.Consume(ctx, u.ID, s.day(), kind, limit)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return s.exceeded(kind, limit)
+	}
+	return nil
+}
+
+// unmetered reports whether u is exempt from the plan.
+func (s *Service) unmetered(u users.User) bool {
+	return s == nil || isPaid(u)
+}
+
+func (s *Service) limit(kind Kind) int {
+	if kind == KindReview {
+		return s.plan.FreeDailyReviews
+	}
+	return s.plan.FreeDailyQuestions
+}
+
+func (s *Service) day() string {
+	return s.now().In(dayZone).Format(time.DateOnly)
+}
+
+func (s *Service) exceeded(kind Kind, limit int) error {
+	local := s.now().In(dayZone)
+	resetsAt := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, dayZone)
+
+	return httperr.WithDetails(ErrQuotaExceeded, map[string]any{
+		"kind":      kind,
+		"limit":     limit,
+		"resets_at": resetsAt,
+	})
+}
+
+func isPaid(u users.User) bool {
+	return u.AccountType == users.AccountPaid
+}
@@ -0,0 +1,136 @@
+package entitlements
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/bugii1995/backend/internal/users"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.t }
+func (c *fakeClock) Advance(d time.Duration) { c.t = c.t.Add(d) }
+
+var (
+	freeUser = users.User{ID: 1, AccountType: users.AccountFree}
+	paidUser = users.User{ID: 2, AccountType: users.AccountPaid}
+)
+
+func newTestService(plan Plan) (*Service, *fakeClock) {
+	// 20:00 in Ulaanbaatar, four hours before the daily reset.
+	clock := &fakeClock{t: time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)}
+	s := NewService(NewMemoryQuotaRepository(), plan)
+	s.now = clock.Now
+	return s, clock
+}
+
+func TestFreeQuotaResetsAtLocalMidnight(t *testing.T) {
+	ctx := context.Background()
+	s, clock := newTestService(Plan{FreeDailyQuestions: 2})
+
+	for i := 0; i < 2; i++ {
+		if err := s.Consume(ctx, freeUser, KindQuestion); err != nil {
+			t.Fatalf("consume %d: %v", i, err)
+		}
+	}
+	if err := s.Consume(ctx, freeUser, KindQuestion); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("expected ErrQuotaExceeded, got %v", err)
+	}
+	if err := s.Check(ctx, freeUser, KindQuestion); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("expected Check to agree, got %v", err)
+	}
+
+	clock.Advance(3*time.Hour + 59*time.Minute)
+	if err := s.Check(ctx, freeUser, KindQuestion); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("expected quota to last until midnight, got %v", err)
+	}
+
+	clock.Advance(time.Minute)
+	if err := s.Consume(ctx, freeUser, KindQuestion); err != nil {
+		t.Fatalf("expected a fresh quota after midnight, got %v", err)
+	}
+}
+
+func TestReviewsAreMeteredSeparately(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(Plan{FreeDailyQuestions: 1, FreeDailyReviews: 1})
+
+	s.Consume(ctx, freeUser, KindQuestion)
+	if err := s.Consume(ctx, freeUser, KindReview); err != nil {
+		t.Fatalf("expected review quota to be separate, got %v", err)
+	}
+	if err := s.Consume(ctx, freeUser, KindReview); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("expected review quota to run out, got %v", err)
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(Plan{FreeDailyQuestions: 3, FreeDailyReviews: 1})
+
+	s.Consume(ctx, freeUser, KindQuestion)
+	if left, err := s.Remaining(ctx, freeUser, KindQuestion); err != nil || left != 2 {
+		t.Fatalf("expected 2 questions left, got %d %v", left, err)
+	}
+	s.Consume(ctx, freeUser, KindReview)
+	if left, _ := s.Remaining(ctx, freeUser, KindReview); left != 0 {
+		t.Fatalf("expected no reviews left, got %d", left)
+	}
+	if left, _ := s.Remaining(ctx, paidUser, KindReview); left != math.MaxInt {
+		t.Fatalf("expected paid accounts unlimited, got %d", left)
+	}
+}
+
+func TestPaidAccountsAreUnmetered(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(Plan{})
+
+	for i := 0; i < 100; i++ {
+		if err := s.Consume(ctx, paidUser, KindReview); err != nil {
+			t.Fatalf("consume %d: %v", i, err)
+		}
+	}
+	if err := s.Check(ctx, paidUser, KindQuestion); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPaidOnlyTopics(t *testing.T) {
+	s, _ := newTestService(Plan{PaidOnlyTopics: []string{"conditionals"}})
+
+	if s.CanAccessTopic(freeUser, "conditionals") || !s.CanAccessTopic(freeUser, "articles") {
+		t.Fatal("free users must be locked out of paid-only topics only")
+	}
+	if !s.CanAccessTopic(paidUser, "conditionals") {
+		t.Fatal("paid users may access every topic")
+	}
+
+	if err := s.CheckTopics(freeUser, []string{"articles", "conditionals"}); !errors.Is(err, ErrUpgradeRequired) {
+		t.Fatalf("expected ErrUpgradeRequired, got %v", err)
+	}
+	if err := s.CheckTopics(paidUser, []string{"articles", "conditionals"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNilServiceIsUnmetered(t *testing.T) {
+	ctx := context.Background()
+	var s *Service
+
+	if err := s.Consume(ctx, freeUser, KindQuestion); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Check(ctx, freeUser, KindReview); err != nil {
+		t.Fatal(err)
+	}
+	if left, err := s.Remaining(ctx, freeUser, KindReview); err != nil || left != math.MaxInt {
+		t.Fatalf("expected unlimited reviews, got %d %v", left, err)
+	}
+	if err := s.CheckTopics(freeUser, []string{"conditionals"}); err != nil || !s.CanAccessTopic(freeUser, "conditionals") {
+		t.Fatalf("expected every topic open, got %v", err)
+	}
+}
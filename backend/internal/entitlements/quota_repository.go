@@ -0,0 +1,113 @@
+package entitlements
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/bugii1995/backend/internal/storage"
+)
+
+// QuotaRepository counts each user's usage per day and kind.
+type QuotaRepository interface {
+	// Used returns how many units were consumed.
+	Used(ctx context.Context, userID uint64, day string, kind Kind) (int, error)
+
+	// Consume adds one unit if fewer than limit were used, and reports
+	// whether it did. The check and the increment are atomic.
+	Consume(ctx context.Context, userID uint64, day string, kind Kind, limit int) (bool, error)
+}
+
+// ---------------- In-memory ----------------
+
+type quotaKey struct {
+	userID uint64
+	day    string
+	kind   Kind
+}
+
+type MemoryQuotaRepository struct {
+	mu     sync.Mutex
+	counts map[quotaKey]int
+}
+
+func NewMemoryQuotaRepository() *MemoryQuotaRepository {
+	return &MemoryQuotaRepository{counts: make(map[quotaKey]int)}
+}
+
+func (r *MemoryQuotaRepository) Used(ctx context.Context, userID uint64, day string, kind Kind) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.counts[quotaKey{userID, day, kind}], nil
+}
+
+func (r *MemoryQuotaRepository) Consume(ctx context.Context, userID uint64, day string, kind Kind, limit int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := quotaKey{userID, day, kind}
+	if r.counts[key] >= limit {
+		return false, nil
+	}
+	r.counts[key]++
+	return true, nil
+}
+
+// ---------------- SQLite ----------------
+
+var quotaMigrations = []string{
+	`CREATE TABLE daily_quotas (
+		user_id INTEGER NOT NULL,
+		day     TEXT    NOT NULL, -- YYYY-MM-DD in the service's time zone
+		kind    TEXT    NOT NULL,
+		used    INTEGER NOT NULL,
+		PRIMARY KEY (user_id, day, kind)
+	);`,
+}
+
+type SQLiteQuotaRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteQuotaRepository(db *sql.DB) (*SQLiteQuotaRepository, error) {
+	if err := storage.Migrate(db, "quotas", quotaMigrations); err != nil {
+		return nil, err
+	}
+	return &SQLiteQuotaRepository{db: db}, nil
+}
+
+func (r *SQLiteQuotaRepository) Used(ctx context.Context, userID uint64, day string, kind Kind) (int, error) {
+	var used int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT used FROM daily_quotas WHERE user_id = ? AND day = ? AND kind = ?`,
+		userID, day, kind,
+	).Scan(&used)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return used, err
+}
+
+func (r *SQLiteQuotaRepository) Consume(ctx context.Context, userID uint64, day string, kind Kind, limit int) (bool, error) {
+	if limit <= 0 {
+		return false, nil
+	}
+
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO daily_quotas (user_id, day, kind, used)
+		VALUES (?, ?, ?, 1)
+		ON CONFLICT (user_id, day, kind) DO UPDATE SET used = used + 1
+		WHERE used < ?`,
+		userID, day, kind, limit,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
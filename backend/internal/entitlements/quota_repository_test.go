@@ -0,0 +1,82 @@
+package entitlements
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bugii1995/backend/internal/storage"
+)
+
+func TestMemoryQuotaRepository(t *testing.T) {
+	testQuotaRepositoryContract(t, func(t *testing.T) QuotaRepository {
+		return NewMemoryQuotaRepository()
+	})
+}
+
+func TestSQLiteQuotaRepository(t *testing.T) {
+	testQuotaRepositoryContract(t, func(t *testing.T) QuotaRepository {
+		db, err := storage.Open(":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		repo, err := NewSQLiteQuotaRepository(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return repo
+	})
+}
+
+// testQuotaRepositoryContract is run against every QuotaRepository.
+func testQuotaRepositoryContract(t *testing.T, newRepo func(t *testing.T) QuotaRepository) {
+	ctx := context.Background()
+
+	t.Run("ConsumeUpToLimit", func(t *testing.T) {
+		repo := newRepo(t)
+
+		for i := 0; i < 3; i++ {
+			if ok, err := repo.Consume(ctx, 1, "2026-03-02", KindQuestion, 3); err != nil || !ok {
+				t.Fatalf("consume %d: %v %v", i, ok, err)
+			}
+		}
+		if ok, err := repo.Consume(ctx, 1, "2026-03-02", KindQuestion, 3); err != nil || ok {
+			t.Fatalf("expected limit to hold, got %v %v", ok, err)
+		}
+		if used, _ := repo.Used(ctx, 1, "2026-03-02", KindQuestion); used != 3 {
+			t.Fatalf("expected 3 used, got %d", used)
+		}
+	})
+
+	t.Run("ZeroLimit", func(t *testing.T) {
+		repo := newRepo(t)
+
+		if ok, err := repo.Consume(ctx, 1, "2026-03-02", KindReview, 0); err != nil || ok {
+			t.Fatalf("expected nothing to be allowed, got %v %v", ok, err)
+		}
+		if used, _ := repo.Used(ctx, 1, "2026-03-02", KindReview); used != 0 {
+			t.Fatalf("expected 0 used, got %d", used)
+		}
+	})
+
+	t.Run("CountersAreSeparate", func(t *testing.T) {
+		repo := newRepo(t)
+
+		repo.Consume(ctx, 1, "2026-03-02", KindQuestion, 1)
+
+		for _, other := range []struct {
+			user uint64
+			day  string
+			kind Kind
+		}{
+			{2, "2026-03-02", KindQuestion},
+			{1, "2026-03-03", KindQuestion},
+			{1, "2026-03-02", KindReview},
+		} {
+			if ok, err := repo.Consume(ctx, other.user, other.day, other.kind, 1); err != nil || !ok {
+				t.Fatalf("%+v: expected a fresh counter, got %v %v", other, ok, err)
+			}
+		}
+	})
+}
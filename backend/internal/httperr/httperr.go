@@ -0,0 +1,106 @@
+package httperr
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Envelope is the JSON body of every error response.
+type Envelope struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+}
+
+// Error is an error that already knows its HTTP status and code,
+// for failures that are not domain errors (e.g. malformed JSON).
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string { return e.Message }
+
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// BadRequest wraps a request binding/validation failure.
+func BadRequest(err error) *Error {
+	return New(http.StatusBadRequest, "invalid_request", err.Error())
+}
+
+// Rule maps a domain error (matched with errors.Is) to HTTP.
+type Rule struct {
+	Err    error
+	Status int
+	Code   string
+}
+
+type detailedError struct {
+	err     error
+	details any
+}
+
+func (e *detailedError) Error() string { return e.err.Error() }
+func (e *detailedError) Unwrap() error { return e.err }
+
+// WithDetails attaches structured details to err; they are returned
+// in the envelope's "details" field.
+func WithDetails(err error, details any) error {
+	return &detailedError{err: err, details: details}
+}
+
+// Middleware renders the last error recorded with c.Error as an
+// Envelope. Handlers report failures with c.Error(err) and return.
+//
+// Errors matching none of the rules become a 500 whose message does
+// not leak internals.
+func Middleware(rules ...Rule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		status, body := Resolve(c.Errors.Last().Err, rules)
+		c.AbortWithStatusJSON(status, body)
+	}
+}
+
+// Resolve turns err into a status and envelope using rules.
+func Resolve(err error, rules []Rule) (int, Envelope) {
+	var details any
+	var detailed *detailedError
+	if errors.As(err, &detailed) {
+		details = detailed.details
+	}
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Status, Envelope{
+			Code:    apiErr.Code,
+			Message: apiErr.Message,
+			Details: details,
+		}
+	}
+
+	for _, rule := range rules {
+		if errors.Is(err, rule.Err) {
+			return rule.Status, Envelope{
+				Code:    rule.Code,
+				Message: rule.Err.Error(),
+				Details: details,
+			}
+		}
+	}
+
+	return http.StatusInternalServerError, Envelope{
+		Code:    "internal",
+		Message: "internal server error",
+	}
+}
@@ -0,0 +1,85 @@
+package httperr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errThingNotFound = errors.New("thing not found")
+
+func serve(t *testing.T, handler gin.HandlerFunc) (int, Envelope) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(Middleware(Rule{Err: errThingNotFound, Status: http.StatusNotFound, Code: "thing_not_found"}))
+	r.GET("/", handler)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var body Envelope
+	if w.Body.Len() > 0 {
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode %q: %v", w.Body.String(), err)
+		}
+	}
+	return w.Code, body
+}
+
+func TestMiddlewareMapsDomainErrors(t *testing.T) {
+	status, body := serve(t, func(c *gin.Context) {
+		c.Error(fmt.Errorf("load: %w", errThingNotFound))
+	})
+
+	if status != http.StatusNotFound || body.Code != "thing_not_found" || body.Message != "thing not found" {
+		t.Fatalf("unexpected response %d %+v", status, body)
+	}
+}
+
+func TestMiddlewareIncludesDetails(t *testing.T) {
+	status, body := serve(t, func(c *gin.Context) {
+		c.Error(WithDetails(errThingNotFound, map[string]any{"id": 7}))
+	})
+
+	details, ok := body.Details.(map[string]any)
+	if status != http.StatusNotFound || !ok || details["id"] != float64(7) {
+		t.Fatalf("unexpected response %d %+v", status, body)
+	}
+}
+
+func TestMiddlewareUsesErrorStatus(t *testing.T) {
+	status, body := serve(t, func(c *gin.Context) {
+		c.Error(BadRequest(errors.New("bad json")))
+	})
+
+	if status != http.StatusBadRequest || body.Code != "invalid_request" || body.Message != "bad json" {
+		t.Fatalf("unexpected response %d %+v", status, body)
+	}
+}
+
+func TestMiddlewareHidesUnknownErrors(t *testing.T) {
+	status, body := serve(t, func(c *gin.Context) {
+		c.Error(errors.New("database is on fire"))
+	})
+
+	if status != http.StatusInternalServerError || body.Code != "internal" || body.Message == "database is on fire" {
+		t.Fatalf("unexpected response %d %+v", status, body)
+	}
+}
+
+func TestMiddlewareLeavesSuccessAlone(t *testing.T) {
+	status, body := serve(t, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"code": "ok"})
+	})
+
+	if status != http.StatusOK || body.Code != "ok" {
+		t.Fatalf("unexpected response %d %+v", status, body)
+	}
+}
@@ -0,0 +1,46 @@
+package keylock
+
+import "sync"
+
+// Mutex serializes work per key, e.g. per user ID or phone number,
+// without making work on different keys wait on each other. Entries
+// are reference-counted and dropped once unused.
+//
+// The zero value is ready to use.
+type Mutex struct {
+	mu    sync.Mutex
+	locks map[string]*lock
+}
+
+type lock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// Lock blocks until key is free and returns the matching unlock func.
+func (k *Mutex) Lock(key string) (unlock func()) {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*lock)
+	}
+	l, ok := k.locks[key]
+	if !ok {
+		l = &lock{}
+		k.locks[key] = l
+	}
+	l.refs++
+	k.mu.Unlock()
+
+	l.mu.Lock()
+
+	return func() {
+		l.mu.Unlock()
+
+		k.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
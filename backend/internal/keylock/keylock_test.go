@@ -0,0 +1,56 @@
+package keylock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMutexSerializesSameKey(t *testing.T) {
+	var k Mutex
+	unlock := k.Lock("a")
+
+	acquired := make(chan struct{})
+	go func() {
+		defer k.Lock("a")()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("expected the second lock on a to wait")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("expected the second lock on a once the first was released")
+	}
+}
+
+func TestMutexKeysAreIndependent(t *testing.T) {
+	var k Mutex
+	defer k.Lock("a")()
+
+	acquired := make(chan struct{})
+	go func() {
+		defer k.Lock("b")()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("expected b not to wait on a")
+	}
+}
+
+func TestMutexDropsUnusedEntries(t *testing.T) {
+	var k Mutex
+	k.Lock("a")()
+
+	if len(k.locks) != 0 {
+		t.Fatalf("expected no entries left, got %d", len(k.locks))
+	}
+}
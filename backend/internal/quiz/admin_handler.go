@@ -0,0 +1,282 @@
+package quiz
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bugii1995/backend/internal/httperr"
+)
+
+// ---------------- Handler ----------------
+
+// AdminHandler serves /admin/questions, /admin/config and
+// /admin/calibration, behind auth.AreaAdmin.
+type AdminHandler struct {
+	Questions QuestionRepository
+	Topics    TopicLookup
+	Pedagogy  *PedagogyStore
+
+	// Progress supplies the answer counts questions are calibrated
+	// against.
+	Progress ProgressRepository
+}
+
+// errQuestionMissing is ErrQuestionNotFound for admin lookups by path,
+// where the resource itself is missing (404, not 422).
+var errQuestionMissing = httperr.New(http.StatusNotFound, "question_not_found", ErrQuestionNotFound.Error())
+
+// ---------------- DTOs ----------------
+
+// QuestionRequest creates or replaces a question. Status defaults to
+// draft.
+type QuestionRequest struct {
+	TopicID       string         `json:"topic_id"`
+	Difficulty    int            `json:"difficulty"`
+	Prompt        string         `json:"prompt"`
+	Options       []string       `json:"options"`
+	CorrectAnswer string         `json:"correct_answer"`
+	Explanation   string         `json:"explanation"`
+	Status        QuestionStatus `json:"status"`
+}
+
+// AdminQuestionResponse is the full question, answer included.
+type AdminQuestionResponse struct {
+	ID            int64          `json:"id"`
+	TopicID       string         `json:"topic_id"`
+	Difficulty    int            `json:"difficulty"`
+	Prompt        string         `json:"prompt"`
+	Options       []string       `json:"options"`
+	CorrectAnswer string         `json:"correct_answer"`
+	Explanation   string         `json:"explanation"`
+	Status        QuestionStatus `json:"status"`
+}
+
+// ---------------- Helpers ----------------
+
+func (r QuestionRequest) question(id int64) Question {
+	status := r.Status
+	if status == "" {
+		status = StatusDraft
+	}
+	return Question{
+		ID:            id,
+		TopicID:       r.TopicID,
+		Difficulty:    r.Difficulty,
+		Prompt:        r.Prompt,
+		Options:       r.Options,
+		CorrectAnswer: r.CorrectAnswer,
+		Explanation:   r.Explanation,
+		Status:        status,
+	}
+}
+
+func toAdminQuestionResponse(q Question) AdminQuestionResponse {
+	return AdminQuestionResponse{
+		ID:            q.ID,
+		TopicID:       q.TopicID,
+		Difficulty:    q.Difficulty,
+		Prompt:        q.Prompt,
+		Options:       q.Options,
+		CorrectAnswer: q.CorrectAnswer,
+		Explanation:   q.Explanation,
+		Status:        q.Status,
+	}
+}
+
+// questionParam loads the question named by the :id path parameter.
+func (h *AdminHandler) questionParam(c *gin.Context) (Question, error) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return Question{}, errQuestionMissing
+	}
+	q, err := h.Questions.GetByID(c.Request.Context(), id)
+	if errors.Is(err, ErrQuestionNotFound) {
+		return Question{}, errQuestionMissing
+	}
+	return q, err
+}
+
+// ---------------- Handlers ----------------
+
+// GET /admin/questions?status=&topic_id=
+func (h *AdminHandler) ListQuestions(c *gin.Context) {
+	var (
+		questions []Question
+		err       error
+	)
+	if status := c.Query("status"); status != "" {
+		questions, err = h.Questions.ListByStatus(c.Request.Context(), QuestionStatus(status))
+	} else {
+		questions, err = h.Questions.List(c.Request.Context())
+	}
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	topicID := c.Query("topic_id")
+	out := make([]AdminQuestionResponse, 0, len(questions))
+	for _, q := range questions {
+		if topicID == "" || q.TopicID == topicID {
+			out = append(out, toAdminQuestionResponse(q))
+		}
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// GET /admin/questions/:id
+func (h *AdminHandler) GetQuestion(c *gin.Context) {
+	q, err := h.questionParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, toAdminQuestionResponse(q))
+}
+
+// POST /admin/questions
+func (h *AdminHandler) CreateQuestion(c *gin.Context) {
+	var req QuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(httperr.BadRequest(err))
+		return
+	}
+
+	q := req.question(0)
+	if err := ValidateQuestion(c.Request.Context(), q, h.Topics); err != nil {
+		c.Error(err)
+		return
+	}
+
+	q, err := h.Questions.Save(c.Request.Context(), q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, toAdminQuestionResponse(q))
+}
+
+// PUT /admin/questions/:id
+func (h *AdminHandler) UpdateQuestion(c *gin.Context) {
+	current, err := h.questionParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req QuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(httperr.BadRequest(err))
+		return
+	}
+
+	q := req.question(current.ID)
+	if req.Status == "" {
+		q.Status = current.Status
+	}
+	if err := ValidateQuestion(c.Request.Context(), q, h.Topics); err != nil {
+		c.Error(err)
+		return
+	}
+
+	q, err = h.Questions.Save(c.Request.Context(), q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, toAdminQuestionResponse(q))
+}
+
+// DELETE /admin/questions/:id
+//
+// Questions are retired, never removed: running sessions and answer
+// history still refer to them.
+func (h *AdminHandler) RetireQuestion(c *gin.Context) {
+	q, err := h.questionParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	q.Status = StatusRetired
+	q, err = h.Questions.Save(c.Request.Context(), q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, toAdminQuestionResponse(q))
+}
+
+// GET /admin/config
+//
+// The pedagogy config in force, after the last reload.
+func (h *AdminHandler) GetConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, h.Pedagogy.Current())
+}
+
+// GET /admin/calibration?flagged=true&topic_id=
+//
+// Every question still in use, label against answers. flagged=true
+// keeps the ones whose label the answers disagree with.
+func (h *AdminHandler) ListCalibration(c *gin.Context) {
+	questions, err := h.Questions.List(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	stats, err := h.Progress.ListStats(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	topicID := c.Query("topic_id")
+	inUse := make([]Question, 0, len(questions))
+	for _, q := range questions {
+		if q.Status != StatusRetired && (topicID == "" || q.TopicID == topicID) {
+			inUse = append(inUse, q)
+		}
+	}
+
+	flaggedOnly := c.Query("flagged") == "true"
+	out := make([]Calibration, 0, len(inUse))
+	for _, cal := range CalibrateAll(inUse, stats) {
+		if !flaggedOnly || cal.Flagged {
+			out = append(out, cal)
+		}
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// POST /admin/calibration/:id/accept
+//
+// Relabels a flagged question with its suggested difficulty.
+func (h *AdminHandler) AcceptCalibration(c *gin.Context) {
+	q, err := h.questionParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	stats, err := h.Progress.GetStats(c.Request.Context(), q.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	cal := Calibrate(q, stats)
+	if !cal.Flagged {
+		c.Error(httperr.WithDetails(ErrNotMiscalibrated, gin.H{"question_id": q.ID, "attempts": cal.Attempts}))
+		return
+	}
+
+	q.Difficulty = cal.SuggestedDifficulty
+	q, err = h.Questions.Save(c.Request.Context(), q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, toAdminQuestionResponse(q))
+}
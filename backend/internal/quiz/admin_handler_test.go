@@ -0,0 +1,186 @@
+package quiz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bugii1995/backend/internal/apitest"
+	"github.com/bugii1995/backend/internal/httperr"
+)
+
+func newAdminRouter(h *AdminHandler) *gin.Engine {
+	r := gin.New()
+	r.Use(httperr.Middleware(HTTPErrors...))
+	r.GET("/admin/questions", h.ListQuestions)
+	r.POST("/admin/questions", h.CreateQuestion)
+	r.GET("/admin/questions/:id", h.GetQuestion)
+	r.PUT("/admin/questions/:id", h.UpdateQuestion)
+	r.DELETE("/admin/questions/:id", h.RetireQuestion)
+	r.GET("/admin/config", h.GetConfig)
+	r.GET("/admin/calibration", h.ListCalibration)
+	r.POST("/admin/calibration/:id/accept", h.AcceptCalibration)
+	return r
+}
+
+func doAdmin(t *testing.T, r http.Handler, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func questionPath(id int64) string {
+	return "/admin/questions/" + strconv.FormatInt(id, 10)
+}
+
+var hourQuestion = QuestionRequest{
+	TopicID:       "articles",
+	Difficulty:    1,
+	Prompt:        "Choose the correct article: ___ hour",
+	Options:       []string{"a", "an", "the"},
+	CorrectAnswer: "an",
+}
+
+func TestAdminQuestionLifecycle(t *testing.T) {
+	repo := NewMemoryQuestionRepository()
+	r := newAdminRouter(&AdminHandler{Questions: repo, Topics: NewTopicSet("articles")})
+
+	w := doAdmin(t, r, http.MethodPost, "/admin/questions", hourQuestion)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+	created := apitest.Decode[AdminQuestionResponse](t, w)
+	if created.Status != StatusDraft || created.CorrectAnswer != "an" {
+		t.Fatalf("expected a draft with its answer, got %+v", created)
+	}
+
+	published := hourQuestion
+	published.Status = StatusPublished
+	published.Explanation = "'Hour' starts with a vowel sound."
+	w = doAdmin(t, r, http.MethodPut, questionPath(created.ID), published)
+	if got := apitest.Decode[AdminQuestionResponse](t, w); w.Code != http.StatusOK || got.Status != StatusPublished || got.Explanation == "" {
+		t.Fatalf("publish: %d %s", w.Code, w.Body.String())
+	}
+
+	// Leaving the status out keeps it.
+	w = doAdmin(t, r, http.MethodPut, questionPath(created.ID), hourQuestion)
+	if got := apitest.Decode[AdminQuestionResponse](t, w); got.Status != StatusPublished {
+		t.Fatalf("expected status to be kept, got %+v", got)
+	}
+
+	w = doAdmin(t, r, http.MethodGet, "/admin/questions?status=published", nil)
+	if got := apitest.Decode[[]AdminQuestionResponse](t, w); len(got) != 1 || got[0].ID != created.ID {
+		t.Fatalf("expected the published question, got %+v", got)
+	}
+
+	w = doAdmin(t, r, http.MethodDelete, questionPath(created.ID), nil)
+	if got := apitest.Decode[AdminQuestionResponse](t, w); w.Code != http.StatusOK || got.Status != StatusRetired {
+		t.Fatalf("retire: %d %s", w.Code, w.Body.String())
+	}
+
+	// Retired questions are kept.
+	if w := doAdmin(t, r, http.MethodGet, questionPath(created.ID), nil); w.Code != http.StatusOK {
+		t.Fatalf("expected retired question to remain readable, got %d", w.Code)
+	}
+}
+
+func TestAdminQuestionValidation(t *testing.T) {
+	r := newAdminRouter(&AdminHandler{Questions: NewMemoryQuestionRepository(), Topics: NewTopicSet("articles")})
+
+	bad := hourQuestion
+	bad.CorrectAnswer = "the hour"
+	bad.TopicID = "idioms"
+
+	w := doAdmin(t, r, http.MethodPost, "/admin/questions", bad)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d %s", w.Code, w.Body.String())
+	}
+	body := apitest.Decode[errorBody](t, w)
+	details, _ := body.Details.(map[string]any)
+	if body.Code != "invalid_question" || details["correct_answer"] == nil || details["topic_id"] == nil {
+		t.Fatalf("unexpected body %+v", body)
+	}
+
+	w = doAdmin(t, r, http.MethodPut, questionPath(42), hourQuestion)
+	if body := apitest.Decode[errorBody](t, w); w.Code != http.StatusNotFound || body.Code != "question_not_found" {
+		t.Fatalf("expected 404 question_not_found, got %d %+v", w.Code, body)
+	}
+	if w := doAdmin(t, r, http.MethodGet, "/admin/questions/abc", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected malformed IDs to be 404, got %d", w.Code)
+	}
+}
+
+func TestOnlyPublishedQuestionsAreQuizzed(t *testing.T) {
+	h := newTestHandler(
+		Question{ID: 1, TopicID: "articles", Difficulty: 2, Options: []string{"a", "an"}, CorrectAnswer: "a", Status: StatusDraft},
+		Question{ID: 2, TopicID: "articles", Difficulty: 2, Options: []string{"a", "an"}, CorrectAnswer: "a", Status: StatusRetired},
+		Question{ID: 3, TopicID: "articles", Difficulty: 2, Options: []string{"a", "an"}, CorrectAnswer: "a", Status: StatusPublished},
+	)
+
+	start := apitest.Decode[StartQuizResponse](t, doJSON(t, newTestRouter(h), "/quiz/start", nil))
+	session, _ := h.Sessions.Get(context.Background(), start.SessionID)
+
+	if len(session.Questions) != 1 || session.Questions[0].ID != 3 {
+		t.Fatalf("expected only the published question, got %+v", session.Questions)
+	}
+}
+
+func TestRetiringKeepsRunningSessions(t *testing.T) {
+	h, r, start := tamperFixture(t)
+	admin := newAdminRouter(&AdminHandler{Questions: h.Questions, Topics: NewTopicSet("articles")})
+
+	if w := doAdmin(t, admin, http.MethodDelete, questionPath(start.Question.ID), nil); w.Code != http.StatusOK {
+		t.Fatalf("retire: %d %s", w.Code, w.Body.String())
+	}
+
+	w := doJSON(t, r, "/quiz/answer", AnswerQuizRequest{SessionID: start.SessionID, QuestionID: start.Question.ID, SelectedOption: "a"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected the running session to accept its answer, got %d %s", w.Code, w.Body.String())
+	}
+	if got := apitest.Decode[AnswerQuizResponse](t, w); !got.IsCorrect {
+		t.Fatalf("expected the answer to be graded, got %+v", got)
+	}
+
+	// New sessions no longer see it.
+	replacement := hourQuestion
+	replacement.Difficulty = 2
+	replacement.Status = StatusPublished
+	if w := doAdmin(t, admin, http.MethodPost, "/admin/questions", replacement); w.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+
+	w = doJSON(t, r, "/quiz/start", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("start: %d %s", w.Code, w.Body.String())
+	}
+	next := apitest.Decode[StartQuizResponse](t, w)
+	session, err := h.Sessions.Get(context.Background(), next.SessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range session.Questions {
+		if q.ID == start.Question.ID {
+			t.Fatalf("expected the retired question to be left out, got %+v", session.Questions)
+		}
+	}
+	if len(session.Questions) != 3 {
+		t.Fatalf("expected the other questions, got %+v", session.Questions)
+	}
+}
@@ -0,0 +1,128 @@
+package quiz
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+)
+
+//
+// -------- Constants --------
+//
+
+// CalibrationMinAttempts is how many answers a question needs before
+// its label is judged.
+const CalibrationMinAttempts = 30
+
+//
+// -------- Estimates --------
+//
+
+// QuestionStats counts the answers a question has received. The
+// progress repository counts each answer as it saves it; see
+// ProgressRepository.ListStats.
+type QuestionStats struct {
+	QuestionID int64 `json:"question_id"`
+	Attempts   int   `json:"attempts"`
+	Correct    int   `json:"correct"`
+}
+
+// Calibration compares a question's label with how it is answered.
+//
+// PValue is the share of right answers. IRTDifficulty is the Rasch
+// difficulty for learners of average ability, on the scale of
+// LabelDifficulty, so it can be compared with the label directly.
+// SuggestedDifficulty is the label whose LabelDifficulty is nearest
+// IRTDifficulty, and 0 until the question has CalibrationMinAttempts
+// answers.
+type Calibration struct {
+	QuestionID          int64   `json:"question_id"`
+	TopicID             string  `json:"topic_id"`
+	Difficulty          int     `json:"difficulty"`
+	Attempts            int     `json:"attempts"`
+	Correct             int     `json:"correct"`
+	PValue              float64 `json:"p_value"`
+	IRTDifficulty       float64 `json:"irt_difficulty"`
+	SuggestedDifficulty int     `json:"suggested_difficulty,omitempty"`
+
+	// Flagged is set when the label disagrees with the suggestion.
+	Flagged bool `json:"flagged"`
+}
+
+// Calibrate estimates one question from its stats.
+func Calibrate(q Question, s QuestionStats) Calibration {
+	c := Calibration{
+		QuestionID: q.ID,
+		TopicID:    q.TopicID,
+		Difficulty: q.Difficulty,
+		Attempts:   s.Attempts,
+		Correct:    s.Correct,
+	}
+	if s.Attempts == 0 {
+		return c
+	}
+
+	c.PValue = float64(s.Correct) / float64(s.Attempts)
+
+	// Half an answer each way keeps all-right and all-wrong questions
+	// finite.
+	smoothed := (float64(s.Correct) + 0.5) / (float64(s.Attempts) + 1)
+	c.IRTDifficulty = -logit(smoothed)
+
+	if s.Attempts >= CalibrationMinAttempts {
+		c.SuggestedDifficulty = suggestDifficulty(c.IRTDifficulty)
+		c.Flagged = c.SuggestedDifficulty != q.Difficulty
+	}
+	return c
+}
+
+// CalibrateAll calibrates questions, in order, against stats.
+// Questions without stats count as never answered.
+func CalibrateAll(questions []Question, stats []QuestionStats) []Calibration {
+	byQuestion := make(map[int64]QuestionStats, len(stats))
+	for _, s := range stats {
+		byQuestion[s.QuestionID] = s
+	}
+
+	out := make([]Calibration, 0, len(questions))
+	for _, q := range questions {
+		out = append(out, Calibrate(q, byQuestion[q.ID]))
+	}
+	return out
+}
+
+// suggestDifficulty is the label nearest irt on the LabelDifficulty
+// scale: easy below -0.5, hard above 0.5.
+func suggestDifficulty(irt float64) int {
+	suggested := 1
+	for d := 2; d <= 3; d++ {
+		if math.Abs(irt-LabelDifficulty(d)) < math.Abs(irt-LabelDifficulty(suggested)) {
+			suggested = d
+		}
+	}
+	return suggested
+}
+
+//
+// -------- Offline job --------
+//
+
+// RecalibrateEvery rebuilds the question stats from the answer
+// history on every tick until ctx is done, so answers recorded before
+// counting began are included and any drift is corrected.
+func RecalibrateEvery(ctx context.Context, progress ProgressRepository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := progress.RecountStats(ctx); err != nil {
+				log.Printf("quiz: recount question stats: %v", err)
+			}
+		}
+	}
+}
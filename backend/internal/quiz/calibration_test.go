@@ -0,0 +1,145 @@
+package quiz
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/bugii1995/backend/internal/apitest"
+)
+
+func TestCalibrate(t *testing.T) {
+	tests := []struct {
+		name       string
+		difficulty int
+		stats      QuestionStats
+		suggested  int
+		flagged    bool
+	}{
+		{"never answered", 2, QuestionStats{}, 0, false},
+		{"too few answers", 3, QuestionStats{Attempts: CalibrationMinAttempts - 1, Correct: CalibrationMinAttempts - 1}, 0, false},
+		{"easy as labelled", 1, QuestionStats{Attempts: 50, Correct: 45}, 1, false},
+		{"labelled hard, answered as easy", 3, QuestionStats{Attempts: 50, Correct: 40}, 1, true},
+		{"medium as labelled", 2, QuestionStats{Attempts: 50, Correct: 25}, 2, false},
+		{"labelled easy, answered as hard", 1, QuestionStats{Attempts: 40, Correct: 12}, 3, true},
+		{"three in four right reads as easy", 2, QuestionStats{Attempts: 40, Correct: 30}, 1, true},
+		{"just above chance reads as medium", 2, QuestionStats{Attempts: 60, Correct: 36}, 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Calibrate(Question{ID: 1, TopicID: "articles", Difficulty: tt.difficulty}, tt.stats)
+			if got.SuggestedDifficulty != tt.suggested || got.Flagged != tt.flagged {
+				t.Fatalf("expected suggestion %d, flagged %v, got %+v", tt.suggested, tt.flagged, got)
+			}
+		})
+	}
+}
+
+func TestCalibrateEstimates(t *testing.T) {
+	got := Calibrate(Question{ID: 1, Difficulty: 2}, QuestionStats{Attempts: 40, Correct: 30})
+	if got.PValue != 0.75 {
+		t.Fatalf("expected p-value 0.75, got %v", got.PValue)
+	}
+	if want := -math.Log(30.5 / 10.5); !approxEqual(got.IRTDifficulty, want) {
+		t.Fatalf("expected IRT difficulty %v, got %v", want, got.IRTDifficulty)
+	}
+
+	// Questions nobody gets right, or wrong, stay finite.
+	for _, correct := range []int{0, 40} {
+		got := Calibrate(Question{ID: 1, Difficulty: 2}, QuestionStats{Attempts: 40, Correct: correct})
+		if math.IsInf(got.IRTDifficulty, 0) || math.IsNaN(got.IRTDifficulty) {
+			t.Fatalf("expected a finite difficulty for %d/40, got %v", correct, got.IRTDifficulty)
+		}
+	}
+}
+
+func TestCalibrateSuggestsNearestLabel(t *testing.T) {
+	for _, correct := range []int{0, 10, 20, 30, 40} {
+		got := Calibrate(Question{ID: 1, Difficulty: 2}, QuestionStats{Attempts: 40, Correct: correct})
+		for d := 1; d <= 3; d++ {
+			if math.Abs(got.IRTDifficulty-LabelDifficulty(d)) < math.Abs(got.IRTDifficulty-LabelDifficulty(got.SuggestedDifficulty)) {
+				t.Fatalf("%d/40: IRT %v is nearer %d than the suggested %d", correct, got.IRTDifficulty, d, got.SuggestedDifficulty)
+			}
+		}
+	}
+}
+
+func TestCalibrationCountsEveryAnswer(t *testing.T) {
+	h := newTestHandler(
+		Question{ID: 1, TopicID: "articles", Difficulty: 2, Options: []string{"a", "an"}, CorrectAnswer: "a"},
+		Question{ID: 2, TopicID: "articles", Difficulty: 2, Options: []string{"a", "an"}, CorrectAnswer: "a"},
+	)
+	r := newTestRouter(h)
+
+	start := apitest.Decode[StartQuizResponse](t, doJSON(t, r, "/quiz/start", nil))
+	w := doJSON(t, r, "/quiz/answer", AnswerQuizRequest{SessionID: start.SessionID, QuestionID: start.Question.ID, SelectedOption: "an"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("answer: %d %s", w.Code, w.Body.String())
+	}
+
+	// The answer is counted as it is saved.
+	got, err := h.Progress.ListStats(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != (QuestionStats{QuestionID: start.Question.ID, Attempts: 1}) {
+		t.Fatalf("expected one wrong answer counted, got %+v", got)
+	}
+}
+
+func TestAdminCalibration(t *testing.T) {
+	ctx := context.Background()
+	questions := NewMemoryQuestionRepository(
+		Question{ID: 1, TopicID: "articles", Difficulty: 3, Options: []string{"a", "an"}, CorrectAnswer: "a"},
+		Question{ID: 2, TopicID: "articles", Difficulty: 2, Options: []string{"a", "an"}, CorrectAnswer: "a"},
+		Question{ID: 3, TopicID: "conditionals", Difficulty: 1, Options: []string{"a", "an"}, CorrectAnswer: "a"},
+		Question{ID: 4, TopicID: "articles", Difficulty: 1, Options: []string{"a", "an"}, CorrectAnswer: "a", Status: StatusRetired},
+	)
+	progress := NewMemoryProgressRepository()
+	for _, s := range []QuestionStats{
+		{QuestionID: 1, Attempts: 60, Correct: 54}, // labelled hard, answered as easy
+		{QuestionID: 2, Attempts: 60, Correct: 36},
+		{QuestionID: 3, Attempts: 60, Correct: 15}, // labelled easy, answered as hard
+		{QuestionID: 4, Attempts: 60, Correct: 6},
+	} {
+		for i := 0; i < s.Attempts; i++ {
+			progress.RecordAnswer(ctx, uint64(i+1), AnswerRecord{QuestionID: s.QuestionID, WasCorrect: i < s.Correct})
+		}
+	}
+	r := newAdminRouter(&AdminHandler{Questions: questions, Topics: NewTopicSet(), Progress: progress})
+
+	all := apitest.Decode[[]Calibration](t, doAdmin(t, r, http.MethodGet, "/admin/calibration", nil))
+	if len(all) != 3 {
+		t.Fatalf("expected the three questions in use, got %+v", all)
+	}
+
+	flagged := apitest.Decode[[]Calibration](t, doAdmin(t, r, http.MethodGet, "/admin/calibration?flagged=true&topic_id=articles", nil))
+	if len(flagged) != 1 || flagged[0].QuestionID != 1 || flagged[0].SuggestedDifficulty != 1 {
+		t.Fatalf("expected question 1 flagged as easy, got %+v", flagged)
+	}
+
+	accept := func(id int64) int {
+		return doAdmin(t, r, http.MethodPost, "/admin/calibration/"+strconv.FormatInt(id, 10)+"/accept", nil).Code
+	}
+
+	if code := accept(1); code != http.StatusOK {
+		t.Fatalf("accept: %d", code)
+	}
+	if q, _ := questions.GetByID(ctx, 1); q.Difficulty != 1 {
+		t.Fatalf("expected question 1 relabelled easy, got %d", q.Difficulty)
+	}
+
+	// Relabelled, it no longer disagrees.
+	if code := accept(1); code != http.StatusConflict {
+		t.Fatalf("expected 409 accepting twice, got %d", code)
+	}
+	if code := accept(2); code != http.StatusConflict {
+		t.Fatalf("expected 409 for a question that matches its label, got %d", code)
+	}
+	if code := accept(99); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown question, got %d", code)
+	}
+}
@@ -0,0 +1,124 @@
+package quiz
+
+import (
+	"math"
+	"time"
+)
+
+//
+// -------- Decay models --------
+//
+
+const (
+	DecayModelPiecewise   = "piecewise"
+	DecayModelExponential = "exponential"
+
+	DecayHalfLifeDays = 30.0 // exponential model, after the grace period
+)
+
+// DecayModel is how mastery fades while a topic goes unpractised.
+// Elapsed time is in fractional days, so a topic decays as smoothly at
+// noon as at midnight.
+type DecayModel interface {
+	Decay(mastery, days float64) float64
+}
+
+// PiecewiseDecay drops mastery linearly: nothing for AfterDays, then
+// MediumRate per day until HeavyThreshold, HeavyRate per day after.
+type PiecewiseDecay struct {
+	AfterDays      float64
+	MediumRate     float64
+	HeavyRate      float64
+	HeavyThreshold float64
+	Floor          float64
+}
+
+func (d PiecewiseDecay) Decay(mastery, days float64) float64 {
+	if days <= d.AfterDays {
+		return mastery
+	}
+
+	medium := math.Min(days, d.HeavyThreshold) - d.AfterDays
+	heavy := math.Max(days-d.HeavyThreshold, 0)
+	return floorDecay(mastery, mastery-medium*d.MediumRate-heavy*d.HeavyRate, d.Floor)
+}
+
+// ExponentialDecay is a forgetting curve: after AfterDays, the mastery
+// above Floor halves every HalfLifeDays.
+type ExponentialDecay struct {
+	AfterDays    float64
+	HalfLifeDays float64
+	Floor        float64
+}
+
+func (d ExponentialDecay) Decay(mastery, days float64) float64 {
+	if days <= d.AfterDays || mastery <= d.Floor {
+		return mastery
+	}
+
+	kept := math.Exp2(-(days - d.AfterDays) / d.HalfLifeDays)
+	return d.Floor + (mastery-d.Floor)*kept
+}
+
+// floorDecay stops decayed at floor. Passive decay never raises
+// mastery, so a topic already below floor keeps its value.
+func floorDecay(mastery, decayed, floor float64) float64 {
+	if mastery < floor {
+		return mastery
+	}
+	return math.Max(decayed, floor)
+}
+
+// Decay returns the model c.DecayModel names, piecewise by
+// default.
+func (c MasteryConfig) Decay() DecayModel {
+	if c.DecayModel == DecayModelExponential {
+		return ExponentialDecay{
+			AfterDays:    float64(c.DecayAfterDays),
+			HalfLifeDays: c.DecayHalfLifeDays,
+			Floor:        c.MinimumPassiveMastery,
+		}
+	}
+	return PiecewiseDecay{
+		AfterDays:      float64(c.DecayAfterDays),
+		MediumRate:     c.DecayMediumRate,
+		HeavyRate:      c.DecayHeavyRate,
+		HeavyThreshold: float64(c.DecayHeavyThreshold),
+		Floor:          c.MinimumPassiveMastery,
+	}
+}
+
+//
+// -------- Projection --------
+//
+
+// ProjectedMastery is p's mastery decayed up to now, with the
+// compiled-in defaults. It changes nothing; the stored value only
+// catches up on the next answer.
+func ProjectedMastery(p TopicProgress, now time.Time) float64 {
+	return DefaultMasteryConfig().ProjectedMastery(p, now)
+}
+
+// ProjectedMastery is the package-level ProjectedMastery with c's
+// parameters.
+func (c MasteryConfig) ProjectedMastery(p TopicProgress, now time.Time) float64 {
+	// A topic never seen has nothing to forget.
+	if p.LastSeen.IsZero() || !now.After(p.LastSeen) {
+		return p.Mastery
+	}
+
+	days := now.Sub(p.LastSeen).Hours() / 24
+	return c.Decay().Decay(p.Mastery, days)
+}
+
+// ProjectedMastered reports whether p still counts as mastered at now.
+// The projected mastery is held against c's thresholds with the same
+// hysteresis an answer uses: a mastered topic stays mastered until it
+// decays below UnmasteredHysteresis, any other needs MasteredThreshold.
+func (c MasteryConfig) ProjectedMastered(p TopicProgress, now time.Time) bool {
+	projected := c.ProjectedMastery(p, now)
+	if p.IsMastered {
+		return projected >= c.UnmasteredHysteresis
+	}
+	return projected >= c.MasteredThreshold
+}
@@ -0,0 +1,125 @@
+package quiz
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestPiecewiseDecay(t *testing.T) {
+	model := DefaultMasteryConfig().Decay()
+
+	tests := []struct {
+		name    string
+		mastery float64
+		days    float64
+		want    float64
+	}{
+		{"inside grace", 80, 7, 80},
+		{"half a day past grace", 80, 7.5, 80 - 0.5*DecayMediumRate},
+		{"medium", 80, 20, 80 - 13*DecayMediumRate},
+		{"heavy", 80, 31.5, 80 - 23*DecayMediumRate - 1.5*DecayHeavyRate},
+		{"stops at floor", 30, 100, MinimumPassiveMastery},
+		{"never raises", 10, 100, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := model.Decay(tt.mastery, tt.days); !approxEqual(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestExponentialDecay(t *testing.T) {
+	model := ExponentialDecay{AfterDays: 7, HalfLifeDays: 30, Floor: 20}
+
+	if got := model.Decay(80, 7); got != 80 {
+		t.Fatalf("expected no decay inside grace, got %v", got)
+	}
+	// One half-life: the 60 points above the floor halve.
+	if got := model.Decay(80, 37); !approxEqual(got, 50) {
+		t.Fatalf("expected 50, got %v", got)
+	}
+	if got := model.Decay(80, 67); !approxEqual(got, 35) {
+		t.Fatalf("expected 35, got %v", got)
+	}
+	if got := model.Decay(10, 100); got != 10 {
+		t.Fatalf("expected mastery below the floor to stay, got %v", got)
+	}
+
+	previous := 80.0
+	for hours := 7 * 24; hours <= 120*24; hours += 6 {
+		got := model.Decay(80, float64(hours)/24)
+		if got > previous || got < 20 {
+			t.Fatalf("at %d hours: expected a falling curve above the floor, got %v after %v", hours, got, previous)
+		}
+		previous = got
+	}
+}
+
+func TestProjectedMastery(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	p := TopicProgress{TopicID: "articles", Mastery: 80, LastSeen: now.Add(-37 * day)}
+
+	want := 80 - 23*DecayMediumRate - 7*DecayHeavyRate
+	if got := ProjectedMastery(p, now); !approxEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if p.Mastery != 80 {
+		t.Fatal("expected the progress left untouched")
+	}
+
+	cfg := DefaultMasteryConfig()
+	cfg.DecayModel = DecayModelExponential
+	if got := cfg.ProjectedMastery(p, now); !approxEqual(got, 50) {
+		t.Fatalf("expected the exponential model to give 50, got %v", got)
+	}
+
+	if got := ProjectedMastery(TopicProgress{Mastery: 80}, now); got != 80 {
+		t.Fatalf("expected an unseen topic not to decay, got %v", got)
+	}
+	if got := ProjectedMastery(TopicProgress{Mastery: 80, LastSeen: now.Add(day)}, now); got != 80 {
+		t.Fatalf("expected no decay before LastSeen, got %v", got)
+	}
+}
+
+func TestProjectedMastered(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	cfg := DefaultMasteryConfig()
+
+	tests := []struct {
+		name string
+		p    TopicProgress
+		want bool
+	}{
+		{"fresh and mastered", TopicProgress{Mastery: 100, IsMastered: true, LastSeen: now}, true},
+		{"decayed within the hysteresis", TopicProgress{Mastery: 100, IsMastered: true, LastSeen: now.Add(-20 * day)}, true},
+		{"decayed below the hysteresis", TopicProgress{Mastery: 100, IsMastered: true, LastSeen: now.Add(-60 * day)}, false},
+		{"never mastered", TopicProgress{Mastery: 95, LastSeen: now}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cfg.ProjectedMastered(tt.p, now); got != tt.want {
+				t.Fatalf("expected %v, got %v (projected %v)", tt.want, got, cfg.ProjectedMastery(tt.p, now))
+			}
+		})
+	}
+}
+
+func TestUpdateMastery_DecayUsesFractionalDays(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	current := TopicProgress{TopicID: "articles", Mastery: 80, LastSeen: now.Add(-(20*day + 12*time.Hour))}
+
+	got := UpdateMastery(current, MasteryUpdateInput{WasCorrect: true, Difficulty: 2, AnsweredAt: now, CurrentTime: now})
+	if want := 80 - 13.5*DecayMediumRate + BaseCorrectDelta; !approxEqual(got.Mastery, want) {
+		t.Fatalf("expected %v, got %v", want, got.Mastery)
+	}
+}
@@ -0,0 +1,53 @@
+package quiz
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bugii1995/backend/internal/httperr"
+)
+
+// ---------------- Domain errors ----------------
+
+var (
+	ErrQuestionNotFound     = errors.New("question not found")
+	ErrSessionNotFound      = errors.New("session not found")
+	ErrSessionFinished      = errors.New("session already finished")
+	ErrNoQuestionsAvailable = errors.New("no questions available")
+
+	// ErrNoAssignedQuestions means a learner's class assigned only
+	// topics with nothing they can practice, e.g. paid-only topics or
+	// topics without published questions.
+	ErrNoAssignedQuestions = errors.New("no questions available for the assigned topics")
+
+	ErrQuestionNotServed = errors.New("question was not served in this session")
+	ErrAlreadyAnswered   = errors.New("question already answered")
+	ErrInvalidOption     = errors.New("selected option is not one of the question's options")
+
+	ErrInvalidQuestion  = errors.New("question is invalid")
+	ErrNotMiscalibrated = errors.New("question is not flagged by calibration")
+
+	// ErrAnswerNotFound is handled by the handler; it never reaches a
+	// response.
+	ErrAnswerNotFound = errors.New("answer not found")
+
+	// Catalog and config errors stop the server at startup, or keep
+	// the old config on reload; they never reach a response.
+	ErrInvalidTopic    = errors.New("topic is invalid")
+	ErrTopicCycle      = errors.New("topic prerequisites form a cycle")
+	ErrInvalidPedagogy = errors.New("pedagogy config is invalid")
+)
+
+// HTTPErrors maps the quiz domain errors for httperr.Middleware.
+var HTTPErrors = []httperr.Rule{
+	{Err: ErrQuestionNotFound, Status: http.StatusUnprocessableEntity, Code: "question_not_found"},
+	{Err: ErrSessionNotFound, Status: http.StatusNotFound, Code: "session_not_found"},
+	{Err: ErrSessionFinished, Status: http.StatusConflict, Code: "session_finished"},
+	{Err: ErrNoQuestionsAvailable, Status: http.StatusNotFound, Code: "no_questions_available"},
+	{Err: ErrNoAssignedQuestions, Status: http.StatusNotFound, Code: "no_assigned_questions"},
+	{Err: ErrQuestionNotServed, Status: http.StatusUnprocessableEntity, Code: "question_not_served"},
+	{Err: ErrAlreadyAnswered, Status: http.StatusConflict, Code: "already_answered"},
+	{Err: ErrInvalidOption, Status: http.StatusUnprocessableEntity, Code: "invalid_option"},
+	{Err: ErrInvalidQuestion, Status: http.StatusUnprocessableEntity, Code: "invalid_question"},
+	{Err: ErrNotMiscalibrated, Status: http.StatusConflict, Code: "not_miscalibrated"},
+}
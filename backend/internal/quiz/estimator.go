@@ -0,0 +1,286 @@
+package quiz
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+//
+// -------- Strategy --------
+//
+
+// MasteryEstimator turns an answer into the topic's new state.
+// MasteryConfig, the fixed-delta model, is the default one.
+type MasteryEstimator interface {
+	Update(current TopicProgress, input MasteryUpdateInput) MasteryUpdateResult
+}
+
+// QuestionRater is implemented by estimators that learn question
+// ratings from answers. Their Update only proposes a change, in the
+// result's Rating; Handler commits it once the answer is saved, so a
+// retried answer is never rated twice, and saves the new rating so it
+// outlives the process.
+type QuestionRater interface {
+	Rating(questionID int64) (QuestionRating, bool)
+	Commit(u RatingUpdate) QuestionRating
+}
+
+var (
+	_ MasteryEstimator = MasteryConfig{}
+	_ MasteryEstimator = BKTEstimator{}
+	_ MasteryEstimator = (*EloEstimator)(nil)
+	_ QuestionRater    = (*EloEstimator)(nil)
+)
+
+const (
+	// Probabilistic estimators approach 100 without reaching it, so
+	// they flag mastery lower than MasteredThreshold by default.
+	EstimatorMasteredThreshold    = 95.0
+	EstimatorUnmasteredHysteresis = 85.0
+
+	BKTPrior = InitialMastery / 100
+	BKTLearn = 0.1
+	BKTGuess = 1.0 / 3 // three options per question
+	BKTSlip  = 0.1
+
+	EloLearnerK      = 0.4
+	EloQuestionK     = 0.4
+	EloSettleAnswers = 20
+)
+
+// estimatorConfig is the mastery config pedagogy holds, or the
+// default one if it is nil, with the estimator mastery thresholds.
+// Only its decay and thresholds are used.
+func estimatorConfig(pedagogy *PedagogyStore) MasteryConfig {
+	cfg := DefaultPedagogy()
+	if pedagogy != nil {
+		cfg = *pedagogy.Current()
+	}
+
+	c := cfg.Mastery
+	c.MasteredThreshold = cfg.Estimator.MasteredThreshold
+	c.UnmasteredHysteresis = cfg.Estimator.UnmasteredHysteresis
+	return c
+}
+
+//
+// -------- Bayesian Knowledge Tracing --------
+//
+
+// BKTEstimator treats mastery as the probability, in percent, that the
+// learner knows the topic. Each answer is evidence weighed by Guess,
+// the chance of a right answer without knowing, and Slip, the chance of
+// a wrong one despite knowing; then the learner may learn the topic
+// with probability Learn. Difficulty is not used.
+//
+// Guess + Slip must be below 1, or answers stop being evidence.
+type BKTEstimator struct {
+	Prior float64 // of a topic never answered
+	Learn float64
+	Guess float64
+	Slip  float64
+
+	// Pedagogy supplies decay and the mastery flag thresholds, as they
+	// stand at each answer; nil means the defaults.
+	Pedagogy *PedagogyStore
+}
+
+func DefaultBKTEstimator() BKTEstimator {
+	return BKTEstimator{
+		Prior: BKTPrior,
+		Learn: BKTLearn,
+		Guess: BKTGuess,
+		Slip:  BKTSlip,
+	}
+}
+
+func (e BKTEstimator) Update(current TopicProgress, input MasteryUpdateInput) MasteryUpdateResult {
+	config := estimatorConfig(e.Pedagogy)
+
+	known := e.Prior
+	if !current.LastSeen.IsZero() {
+		known = config.ProjectedMastery(current, input.CurrentTime) / 100
+	}
+
+	if input.WasCorrect {
+		known = posterior(known, 1-e.Slip, e.Guess)
+	} else {
+		known = posterior(known, e.Slip, 1-e.Guess)
+	}
+	known += (1 - known) * e.Learn
+
+	correctStreak, wrongStreak := updateStreaks(current.CorrectStreak, current.WrongStreak, input.WasCorrect)
+	return config.result(current, input, clamp(known*100, 0, 100), correctStreak, wrongStreak)
+}
+
+// posterior is P(known | answer), given how likely the answer is when
+// the topic is known and when it is not.
+func posterior(known, ifKnown, ifUnknown float64) float64 {
+	evidence := known*ifKnown + (1-known)*ifUnknown
+	if evidence == 0 {
+		return known
+	}
+	return known * ifKnown / evidence
+}
+
+//
+// -------- Elo / Rasch --------
+//
+
+// QuestionRating is a question's difficulty as learned from answers,
+// in logits: 0 is a question an average learner gets right half the
+// time.
+type QuestionRating struct {
+	QuestionID int64   `json:"question_id"`
+	Difficulty float64 `json:"difficulty"`
+	Answers    int     `json:"answers"`
+}
+
+// RatingUpdate is the change one answer proposes to a question's
+// rating. Surprise is the outcome, 1 or 0, less the chance the model
+// gave of a right answer. A zero QuestionID proposes nothing.
+type RatingUpdate struct {
+	QuestionID int64
+	Difficulty int // label the rating starts from if there is none yet
+	Surprise   float64
+}
+
+// EloEstimator rates learners and questions against each other with
+// the Rasch model: a learner of ability a answers a question of
+// difficulty b right with probability 1 / (1 + e^(b-a)). After each
+// answer both move toward the outcome, the learner by LearnerK and the
+// question by QuestionK, shrinking as the question gathers answers.
+//
+// Ability is kept as mastery, the chance of answering a question of
+// difficulty 0 right, in percent. Questions start from their label:
+// easy at -1, medium at 0, hard at +1. Update leaves the ratings
+// alone and proposes a question's change; Commit applies it. Ratings
+// are kept in memory; Restore loads saved ones after a restart.
+//
+// It is safe for concurrent use.
+type EloEstimator struct {
+	LearnerK      float64
+	QuestionK     float64
+	SettleAnswers int // answers after which QuestionK has halved
+
+	// Pedagogy supplies decay and the mastery flag thresholds, as they
+	// stand at each answer; nil means the defaults.
+	Pedagogy *PedagogyStore
+
+	mu      sync.Mutex
+	ratings map[int64]QuestionRating
+}
+
+func NewEloEstimator() *EloEstimator {
+	return &EloEstimator{
+		LearnerK:      EloLearnerK,
+		QuestionK:     EloQuestionK,
+		SettleAnswers: EloSettleAnswers,
+		ratings:       make(map[int64]QuestionRating),
+	}
+}
+
+func (e *EloEstimator) Update(current TopicProgress, input MasteryUpdateInput) MasteryUpdateResult {
+	config := estimatorConfig(e.Pedagogy)
+	mastery := config.ProjectedMastery(current, input.CurrentTime)
+	ability := logit(clamp(mastery, 0.5, 99.5) / 100)
+
+	outcome := 0.0
+	if input.WasCorrect {
+		outcome = 1
+	}
+
+	e.mu.Lock()
+	rating := e.rating(input.QuestionID, input.Difficulty)
+	e.mu.Unlock()
+	surprise := outcome - logistic(ability-rating.Difficulty)
+
+	ability += e.LearnerK * surprise
+
+	correctStreak, wrongStreak := updateStreaks(current.CorrectStreak, current.WrongStreak, input.WasCorrect)
+	result := config.result(current, input, logistic(ability)*100, correctStreak, wrongStreak)
+
+	// Answers without a question ID still move the learner.
+	if input.QuestionID != 0 {
+		result.Rating = RatingUpdate{QuestionID: input.QuestionID, Difficulty: input.Difficulty, Surprise: surprise}
+	}
+	return result
+}
+
+// Commit applies a rating change Update proposed and returns the
+// question's new rating. The step shrinks with the answers the
+// question has by then, so concurrent answers each count once.
+func (e *EloEstimator) Commit(u RatingUpdate) QuestionRating {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	rating := e.rating(u.QuestionID, u.Difficulty)
+	k := e.QuestionK / (1 + float64(rating.Answers)/float64(max(e.SettleAnswers, 1)))
+	rating.Difficulty -= k * u.Surprise
+	rating.Answers++
+	e.ratings[u.QuestionID] = rating
+	return rating
+}
+
+// Rating returns the question's learned rating, if it was answered.
+func (e *EloEstimator) Rating(questionID int64) (QuestionRating, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	r, ok := e.ratings[questionID]
+	return r, ok
+}
+
+// Ratings returns every answered question's rating, by question ID.
+func (e *EloEstimator) Ratings() []QuestionRating {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ratings := make([]QuestionRating, 0, len(e.ratings))
+	for _, r := range e.ratings {
+		ratings = append(ratings, r)
+	}
+	sort.Slice(ratings, func(i, j int) bool { return ratings[i].QuestionID < ratings[j].QuestionID })
+	return ratings
+}
+
+// Restore loads saved ratings, replacing any learned for the same
+// questions.
+func (e *EloEstimator) Restore(ratings []QuestionRating) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, r := range ratings {
+		e.ratings[r.QuestionID] = r
+	}
+}
+
+// rating returns the question's rating, starting from its label.
+// e.mu must be held.
+func (e *EloEstimator) rating(questionID int64, difficulty int) QuestionRating {
+	if r, ok := e.ratings[questionID]; ok {
+		return r
+	}
+	return QuestionRating{QuestionID: questionID, Difficulty: LabelDifficulty(difficulty)}
+}
+
+// LabelDifficulty is a difficulty label in logits.
+func LabelDifficulty(difficulty int) float64 {
+	switch difficulty {
+	case 1:
+		return -1
+	case 3:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func logistic(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
+
+func logit(p float64) float64 {
+	return math.Log(p / (1 - p))
+}
@@ -0,0 +1,299 @@
+package quiz
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bugii1995/backend/internal/apitest"
+	"github.com/bugii1995/backend/internal/storage"
+)
+
+func TestBKTEstimator(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	bkt := DefaultBKTEstimator()
+	unseen := TopicProgress{TopicID: "articles", Mastery: InitialMastery}
+
+	// P(known) 0.4; a right answer gives 0.36 / (0.36 + 0.6/3), then
+	// learning adds a tenth of the rest.
+	right := bkt.Update(unseen, MasteryUpdateInput{WasCorrect: true, Difficulty: 2, AnsweredAt: now, CurrentTime: now})
+	if want := (0.36/0.56 + (1-0.36/0.56)*0.1) * 100; !approxEqual(right.Mastery, want) {
+		t.Fatalf("expected %v after a right answer, got %v", want, right.Mastery)
+	}
+
+	wrong := bkt.Update(unseen, MasteryUpdateInput{WasCorrect: false, Difficulty: 2, AnsweredAt: now, CurrentTime: now})
+	if want := (0.04/0.44 + (1-0.04/0.44)*0.1) * 100; !approxEqual(wrong.Mastery, want) {
+		t.Fatalf("expected %v after a wrong answer, got %v", want, wrong.Mastery)
+	}
+	if right.CorrectStreak != 1 || wrong.WrongStreak != 1 {
+		t.Fatalf("expected streaks tracked, got %+v and %+v", right, wrong)
+	}
+
+	// The likelier a guess, the less a right answer proves.
+	guessy := bkt
+	guessy.Guess = 0.5
+	if got := guessy.Update(unseen, MasteryUpdateInput{WasCorrect: true, AnsweredAt: now, CurrentTime: now}); got.Mastery >= right.Mastery {
+		t.Fatalf("expected less credit with guess 0.5, got %v vs %v", got.Mastery, right.Mastery)
+	}
+}
+
+func TestBKTEstimatorReachesMastery(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	bkt := DefaultBKTEstimator()
+	current := TopicProgress{TopicID: "articles", Mastery: InitialMastery}
+
+	var events []MasteryEvent
+	for i := 0; i < 10; i++ {
+		now = now.Add(time.Minute)
+		update := bkt.Update(current, MasteryUpdateInput{WasCorrect: true, Difficulty: 2, AnsweredAt: now, CurrentTime: now})
+		if update.Mastery <= current.Mastery || update.Mastery > 100 {
+			t.Fatalf("answer %d: expected rising mastery up to 100, got %v after %v", i, update.Mastery, current.Mastery)
+		}
+		if update.Event != "" {
+			events = append(events, update.Event)
+		}
+		current = TopicProgress{
+			TopicID:       "articles",
+			Mastery:       update.Mastery,
+			CorrectStreak: update.CorrectStreak,
+			IsMastered:    update.IsMastered,
+			LastSeen:      update.LastSeen,
+		}
+	}
+
+	if !current.IsMastered || len(events) != 1 || events[0] != EventBecameMastered {
+		t.Fatalf("expected one became_mastered, got %+v and %v", current, events)
+	}
+}
+
+func TestEloEstimator(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	current := TopicProgress{TopicID: "articles", Mastery: 50}
+	answer := func(e *EloEstimator, questionID int64, difficulty int, correct bool) MasteryUpdateResult {
+		update := e.Update(current, MasteryUpdateInput{
+			QuestionID: questionID, WasCorrect: correct, Difficulty: difficulty, AnsweredAt: now, CurrentTime: now,
+		})
+		e.Commit(update.Rating)
+		return update
+	}
+
+	// At ability 0, a right answer to an average question moves it by
+	// LearnerK / 2.
+	if got, want := answer(NewEloEstimator(), 1, 2, true).Mastery, logistic(EloLearnerK/2)*100; !approxEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	elo := NewEloEstimator()
+	easy := answer(elo, 1, 1, true)
+	hard := answer(elo, 3, 3, true)
+	if hard.Mastery <= easy.Mastery {
+		t.Fatalf("expected a hard question to count for more, got %v vs %v", hard.Mastery, easy.Mastery)
+	}
+	if missed := answer(elo, 1, 1, false); 50-missed.Mastery <= 50-answer(elo, 3, 3, false).Mastery {
+		t.Fatal("expected missing an easy question to cost more than missing a hard one")
+	}
+
+	if _, ok := elo.Rating(2); ok {
+		t.Fatal("expected no rating for an unanswered question")
+	}
+	if got := elo.Ratings(); len(got) != 2 || got[0].QuestionID != 1 || got[1].QuestionID != 3 || got[0].Answers != 2 {
+		t.Fatalf("unexpected ratings %+v", got)
+	}
+}
+
+func TestEloEstimatorOnlyProposesRatings(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	elo := NewEloEstimator()
+	input := MasteryUpdateInput{QuestionID: 1, WasCorrect: true, Difficulty: 2, AnsweredAt: now, CurrentTime: now}
+
+	first := elo.Update(TopicProgress{TopicID: "articles", Mastery: 50}, input)
+	if _, ok := elo.Rating(1); ok {
+		t.Fatal("expected Update to leave the ratings alone")
+	}
+	// Scoring the same answer again, e.g. on a retry, gives the same.
+	if again := elo.Update(TopicProgress{TopicID: "articles", Mastery: 50}, input); again != first {
+		t.Fatalf("expected the same update, got %+v and %+v", first, again)
+	}
+
+	rating := elo.Commit(first.Rating)
+	if rating.Answers != 1 || rating.Difficulty >= 0 {
+		t.Fatalf("expected a right answer to make the question easier, got %+v", rating)
+	}
+	if stored, _ := elo.Rating(1); stored != rating {
+		t.Fatalf("expected the committed rating kept, got %+v", stored)
+	}
+}
+
+func TestEloEstimatorRatesQuestions(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	elo := NewEloEstimator()
+
+	// Labelled hard, but average learners get it right; labelled easy,
+	// but they miss it.
+	for i := 0; i < 200; i++ {
+		learner := TopicProgress{TopicID: "articles", Mastery: 50}
+		elo.Commit(elo.Update(learner, MasteryUpdateInput{QuestionID: 1, WasCorrect: true, Difficulty: 3, AnsweredAt: now, CurrentTime: now}).Rating)
+		elo.Commit(elo.Update(learner, MasteryUpdateInput{QuestionID: 2, WasCorrect: false, Difficulty: 1, AnsweredAt: now, CurrentTime: now}).Rating)
+	}
+
+	labelledHard, _ := elo.Rating(1)
+	labelledEasy, _ := elo.Rating(2)
+	if labelledHard.Difficulty >= LabelDifficulty(1) || labelledEasy.Difficulty <= LabelDifficulty(3) {
+		t.Fatalf("expected the ratings to cross their labels, got %+v and %+v", labelledHard, labelledEasy)
+	}
+	if labelledHard.Answers != 200 {
+		t.Fatalf("expected 200 answers, got %d", labelledHard.Answers)
+	}
+}
+
+func TestEloEstimatorRestore(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	elo := NewEloEstimator()
+	elo.Restore([]QuestionRating{{QuestionID: 1, Difficulty: 1.5, Answers: 40}})
+
+	// A question labelled easy but rated hard counts as hard.
+	restored := elo.Update(TopicProgress{TopicID: "articles", Mastery: 50}, MasteryUpdateInput{
+		QuestionID: 1, WasCorrect: true, Difficulty: 1, AnsweredAt: now, CurrentTime: now,
+	})
+	labelled := NewEloEstimator().Update(TopicProgress{TopicID: "articles", Mastery: 50}, MasteryUpdateInput{
+		QuestionID: 1, WasCorrect: true, Difficulty: 1, AnsweredAt: now, CurrentTime: now,
+	})
+	if restored.Mastery <= labelled.Mastery {
+		t.Fatalf("expected the restored rating to be used, got %v vs %v", restored.Mastery, labelled.Mastery)
+	}
+	if rating := elo.Commit(restored.Rating); rating.Answers != 41 {
+		t.Fatalf("expected the restored rating to keep counting, got %+v", rating)
+	}
+}
+
+func TestEstimatorsFollowPedagogy(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "pedagogy.yaml")
+	base := time.Now().Add(-time.Hour)
+	writeConfig(t, path, "estimator:\n  mastered_threshold: 60\n  unmastered_hysteresis: 50\n", base)
+
+	store, err := OpenPedagogy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bkt := DefaultBKTEstimator()
+	bkt.Pedagogy = store
+	elo := NewEloEstimator()
+	elo.Pedagogy = store
+
+	right := MasteryUpdateInput{QuestionID: 1, WasCorrect: true, Difficulty: 2, AnsweredAt: now, CurrentTime: now}
+	if got := bkt.Update(TopicProgress{TopicID: "articles", Mastery: InitialMastery}, right); got.Mastery < 60 || !got.IsMastered {
+		t.Fatalf("expected BKT to flag mastery from 60, got %+v", got)
+	}
+	if got := elo.Update(TopicProgress{TopicID: "articles", Mastery: 58}, right); got.Mastery < 60 || !got.IsMastered {
+		t.Fatalf("expected Elo to flag mastery from 60, got %+v", got)
+	}
+
+	// Edits apply to the next answer without rebuilding the estimator.
+	writeConfig(t, path, "estimator:\n  mastered_threshold: 90\n", base.Add(time.Minute))
+	if _, err := store.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if got := bkt.Update(TopicProgress{TopicID: "articles", Mastery: InitialMastery}, right); got.IsMastered {
+		t.Fatalf("expected the reloaded threshold to apply, got %+v", got)
+	}
+}
+
+func TestSessionUsesEstimator(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	session := NewSession(
+		[]Question{{ID: 1, TopicID: "articles", Difficulty: 2}, {ID: 2, TopicID: "articles", Difficulty: 2}},
+		[]TopicProgress{{TopicID: "articles", Mastery: InitialMastery}},
+		nil,
+	)
+	session.Estimator = DefaultBKTEstimator()
+
+	session.NextQuestion(now)
+	_, update := session.SubmitAnswer(Answer{QuestionID: 1, TopicID: "articles", WasCorrect: true, Difficulty: 2}, now)
+
+	want := DefaultBKTEstimator().Update(
+		TopicProgress{TopicID: "articles", Mastery: InitialMastery},
+		MasteryUpdateInput{QuestionID: 1, WasCorrect: true, Difficulty: 2, AnsweredAt: now, CurrentTime: now},
+	)
+	if update != want || session.Progress["articles"].Mastery != want.Mastery {
+		t.Fatalf("expected the BKT update %+v, got %+v", want, update)
+	}
+}
+
+func TestHandlerUsesEstimator(t *testing.T) {
+	elo := NewEloEstimator()
+	h := newTestHandler(
+		Question{ID: 1, TopicID: "articles", Difficulty: 2, Options: []string{"a", "an"}, CorrectAnswer: "a"},
+		Question{ID: 2, TopicID: "articles", Difficulty: 2, Options: []string{"a", "an"}, CorrectAnswer: "a"},
+	)
+	h.Estimator = elo
+	h.Ratings = NewMemoryQuestionRatingRepository()
+	r := newTestRouter(h)
+
+	start := apitest.Decode[StartQuizResponse](t, doJSON(t, r, "/quiz/start", nil))
+	w := doJSON(t, r, "/quiz/answer", AnswerQuizRequest{SessionID: start.SessionID, QuestionID: start.Question.ID, SelectedOption: "a"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("answer: %d %s", w.Code, w.Body.String())
+	}
+
+	rating, ok := elo.Rating(start.Question.ID)
+	if !ok || rating.Answers != 1 {
+		t.Fatalf("expected the answered question rated, got %+v", rating)
+	}
+
+	// The rating is saved, so it survives a restart.
+	saved, err := h.Ratings.ListRatings(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 1 || saved[0] != rating {
+		t.Fatalf("expected %+v saved, got %+v", rating, saved)
+	}
+}
+
+func TestHandlerRatesRetriedAnswerOnce(t *testing.T) {
+	db, err := storage.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	store, err := NewSQLiteSessionStore(db, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions := &flakySessionStore{SessionStore: store}
+
+	elo := NewEloEstimator()
+	h := newTestHandler(
+		Question{ID: 1, TopicID: "articles", Difficulty: 2, Options: []string{"a", "an"}, CorrectAnswer: "a"},
+		Question{ID: 2, TopicID: "articles", Difficulty: 2, Options: []string{"a", "an"}, CorrectAnswer: "a"},
+	)
+	h.Sessions = sessions
+	h.Estimator = elo
+	h.Ratings = NewMemoryQuestionRatingRepository()
+	r := newTestRouter(h)
+
+	start := apitest.Decode[StartQuizResponse](t, doJSON(t, r, "/quiz/start", nil))
+	answer := AnswerQuizRequest{SessionID: start.SessionID, QuestionID: start.Question.ID, SelectedOption: "a"}
+
+	// The answer is saved, then the session write fails; the retry
+	// replays it.
+	sessions.failing = true
+	if w := doJSON(t, r, "/quiz/answer", answer); w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d %s", w.Code, w.Body.String())
+	}
+	sessions.failing = false
+	if w := doJSON(t, r, "/quiz/answer", answer); w.Code != http.StatusOK {
+		t.Fatalf("retry: %d %s", w.Code, w.Body.String())
+	}
+
+	if rating, _ := elo.Rating(start.Question.ID); rating.Answers != 1 {
+		t.Fatalf("expected the question rated once, got %+v", rating)
+	}
+	if saved, _ := h.Ratings.ListRatings(context.Background()); len(saved) != 1 || saved[0].Answers != 1 {
+		t.Fatalf("expected one answer in the saved rating, got %+v", saved)
+	}
+}
@@ -0,0 +1,92 @@
+package quiz
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// ExperimentBuckets is how many buckets users are hashed into; a
+// variant's Buckets is its share of them, in percent.
+const ExperimentBuckets = 100
+
+// Variant is one arm of an Experiment.
+type Variant struct {
+	Name     string
+	Buckets  int
+	Selector Selector
+}
+
+// Experiment splits users between selection strategies. A user's
+// variant depends only on the experiment name and their ID, so it is
+// stable across sessions and restarts as long as the variants keep
+// their order and sizes.
+type Experiment struct {
+	Name     string
+	variants []Variant
+}
+
+// NewExperiment checks that variant names are unique and that their
+// buckets add up to ExperimentBuckets.
+func NewExperiment(name string, variants ...Variant) (*Experiment, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("experiment needs a name")
+	}
+
+	total := 0
+	seen := make(map[string]bool, len(variants))
+	for _, v := range variants {
+		switch {
+		case strings.TrimSpace(v.Name) == "":
+			return nil, fmt.Errorf("experiment %s: variant without a name", name)
+		case seen[v.Name]:
+			return nil, fmt.Errorf("experiment %s: variant %s is listed twice", name, v.Name)
+		case v.Buckets <= 0:
+			return nil, fmt.Errorf("experiment %s: variant %s has no buckets", name, v.Name)
+		case v.Selector == nil:
+			return nil, fmt.Errorf("experiment %s: variant %s has no selector", name, v.Name)
+		}
+		seen[v.Name] = true
+		total += v.Buckets
+	}
+	if total != ExperimentBuckets {
+		return nil, fmt.Errorf("experiment %s: buckets add up to %d, not %d", name, total, ExperimentBuckets)
+	}
+
+	return &Experiment{Name: name, variants: variants}, nil
+}
+
+// Bucket hashes userID into [0, ExperimentBuckets). The experiment
+// name is part of the hash, so different experiments split users
+// independently.
+func (e *Experiment) Bucket(userID uint64) int {
+	h := fnv.New64a()
+	h.Write([]byte(e.Name))
+	h.Write(binary.BigEndian.AppendUint64(nil, userID))
+	return int(h.Sum64() % ExperimentBuckets)
+}
+
+// Assign returns userID's variant.
+func (e *Experiment) Assign(userID uint64) Variant {
+	bucket := e.Bucket(userID)
+	for _, v := range e.variants {
+		if bucket < v.Buckets {
+			return v
+		}
+		bucket -= v.Buckets
+	}
+	// Unreachable: NewExperiment checks the buckets add up.
+	return e.variants[len(e.variants)-1]
+}
+
+// Selector returns the selector of the named variant.
+func (e *Experiment) Selector(variant string) (Selector, bool) {
+	for _, v := range e.variants {
+		if v.Name == variant {
+			return v.Selector, true
+		}
+	}
+	return nil, false
+}
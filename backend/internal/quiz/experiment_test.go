@@ -0,0 +1,156 @@
+package quiz
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bugii1995/backend/internal/apitest"
+)
+
+// countingSelector is RuleSelector, counting its calls.
+type countingSelector struct {
+	calls *int
+}
+
+func (s countingSelector) Select(in SelectionInput) *SelectedQuestion {
+	*s.calls++
+	return RuleSelector{}.Select(in)
+}
+
+func TestNewExperimentRejectsBadVariants(t *testing.T) {
+	rules := RuleSelector{}
+
+	tests := []struct {
+		name     string
+		variants []Variant
+	}{
+		{"no variants", nil},
+		{"buckets short", []Variant{{Name: "a", Buckets: 60, Selector: rules}, {Name: "b", Buckets: 30, Selector: rules}}},
+		{"buckets over", []Variant{{Name: "a", Buckets: 60, Selector: rules}, {Name: "b", Buckets: 50, Selector: rules}}},
+		{"duplicate name", []Variant{{Name: "a", Buckets: 50, Selector: rules}, {Name: "a", Buckets: 50, Selector: rules}}},
+		{"missing name", []Variant{{Buckets: 100, Selector: rules}}},
+		{"no buckets", []Variant{{Name: "a", Buckets: 100, Selector: rules}, {Name: "b", Selector: rules}}},
+		{"no selector", []Variant{{Name: "a", Buckets: 100}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewExperiment("selection", tt.variants...); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestExperimentAssignment(t *testing.T) {
+	e, err := NewExperiment("selection",
+		Variant{Name: "rules", Buckets: 80, Selector: RuleSelector{}},
+		Variant{Name: "weighted", Buckets: 20, Selector: RuleSelector{}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := make(map[string]int)
+	for userID := uint64(1); userID <= 10000; userID++ {
+		v := e.Assign(userID)
+		if again := e.Assign(userID); again.Name != v.Name {
+			t.Fatalf("user %d: assigned %s, then %s", userID, v.Name, again.Name)
+		}
+		counts[v.Name]++
+	}
+
+	// Buckets are a share of users, give or take the hash.
+	if counts["weighted"] < 1800 || counts["weighted"] > 2200 {
+		t.Fatalf("expected about 20%% weighted, got %v", counts)
+	}
+
+	// Another experiment splits the same users differently.
+	other, _ := NewExperiment("pacing",
+		Variant{Name: "rules", Buckets: 80, Selector: RuleSelector{}},
+		Variant{Name: "weighted", Buckets: 20, Selector: RuleSelector{}},
+	)
+	same := 0
+	for userID := uint64(1); userID <= 1000; userID++ {
+		if e.Bucket(userID) == other.Bucket(userID) {
+			same++
+		}
+	}
+	if same > 50 {
+		t.Fatalf("expected independent buckets, %d of 1000 users matched", same)
+	}
+}
+
+func TestHandlerRecordsExperimentVariant(t *testing.T) {
+	var controlCalls, treatmentCalls int
+	experiment, err := NewExperiment("selection",
+		Variant{Name: "control", Buckets: 50, Selector: countingSelector{&controlCalls}},
+		Variant{Name: "treatment", Buckets: 50, Selector: countingSelector{&treatmentCalls}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := newTestHandler(
+		Question{ID: 1, TopicID: "articles", Difficulty: 2, Options: []string{"a", "an"}, CorrectAnswer: "a"},
+		Question{ID: 2, TopicID: "articles", Difficulty: 2, Options: []string{"a", "an"}, CorrectAnswer: "a"},
+	)
+	h.Experiment = experiment
+	h.Exposures = NewMemoryExposureRepository()
+	r := newTestRouter(h)
+
+	want := experiment.Assign(1).Name
+	calls := &controlCalls
+	if want == "treatment" {
+		calls = &treatmentCalls
+	}
+
+	start := apitest.Decode[StartQuizResponse](t, doJSON(t, r, "/quiz/start", nil))
+	session, _ := h.Sessions.Get(context.Background(), start.SessionID)
+	if session.Variant != want {
+		t.Fatalf("expected variant %s, got %q", want, session.Variant)
+	}
+
+	// Stored sessions lose their selector; answering restores it from
+	// the variant.
+	session.Selector = nil
+	w := doJSON(t, r, "/quiz/answer", AnswerQuizRequest{SessionID: start.SessionID, QuestionID: start.Question.ID, SelectedOption: "a"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("answer: %d %s", w.Code, w.Body.String())
+	}
+	if *calls != 2 || controlCalls+treatmentCalls != 2 {
+		t.Fatalf("expected both selections by %s, got control %d, treatment %d", want, controlCalls, treatmentCalls)
+	}
+
+	history, err := h.Progress.ListAnswers(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Variant != want {
+		t.Fatalf("expected the answer recorded under %s, got %+v", want, history)
+	}
+
+	// The second question is never answered but still counts as served.
+	served, err := h.Exposures.ListExposures(context.Background(), want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(served) != 2 || served[0].QuestionID != start.Question.ID || served[1].QuestionID == start.Question.ID {
+		t.Fatalf("expected both served questions recorded under %s, got %+v", want, served)
+	}
+}
+
+func TestSessionRecordsVariantOnServedQuestions(t *testing.T) {
+	session := NewSession(
+		[]Question{{ID: 1, TopicID: "articles", Difficulty: 2}},
+		[]TopicProgress{{TopicID: "articles", Mastery: 50}},
+		nil,
+	)
+	session.Variant = "weighted"
+
+	if got := session.NextQuestion(time.Now()); got == nil || got.Variant != "weighted" {
+		t.Fatalf("expected the variant on the selection, got %+v", got)
+	}
+}
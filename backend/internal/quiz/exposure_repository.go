@@ -0,0 +1,142 @@
+package quiz
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/bugii1995/backend/internal/storage"
+)
+
+// Exposure records one question served to a learner, whether or not
+// it was ever answered, so experiment variants can be compared on what
+// they served as well as on the answers given.
+type Exposure struct {
+	UserID     uint64
+	SessionID  string
+	QuestionID int64
+	TopicID    string
+	Purpose    QuestionPurpose
+	Variant    string // experiment variant that served it, if any
+	ServedAt   time.Time
+}
+
+// ExposureRepository keeps the questions served to learners.
+//
+// An exposure is identified by its SessionID and QuestionID; recording
+// one again, e.g. when an answer is retried, keeps the first.
+// ListExposures returns a variant's exposures in the order they were
+// recorded.
+type ExposureRepository interface {
+	RecordExposure(ctx context.Context, e Exposure) error
+	ListExposures(ctx context.Context, variant string) ([]Exposure, error)
+}
+
+// ---------------- In-memory ----------------
+
+type exposureKey struct {
+	sessionID  string
+	questionID int64
+}
+
+type MemoryExposureRepository struct {
+	mu        sync.RWMutex
+	exposures []Exposure
+	seen      map[exposureKey]bool
+}
+
+func NewMemoryExposureRepository() *MemoryExposureRepository {
+	return &MemoryExposureRepository{seen: make(map[exposureKey]bool)}
+}
+
+func (r *MemoryExposureRepository) RecordExposure(ctx context.Context, e Exposure) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := exposureKey{sessionID: e.SessionID, questionID: e.QuestionID}
+	if r.seen[key] {
+		return nil
+	}
+	r.seen[key] = true
+	r.exposures = append(r.exposures, e)
+	return nil
+}
+
+func (r *MemoryExposureRepository) ListExposures(ctx context.Context, variant string) ([]Exposure, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]Exposure, 0)
+	for _, e := range r.exposures {
+		if e.Variant == variant {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// ---------------- SQLite ----------------
+
+var exposureMigrations = []string{
+	`CREATE TABLE question_exposures (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id     INTEGER NOT NULL,
+		session_id  TEXT    NOT NULL,
+		question_id INTEGER NOT NULL,
+		topic_id    TEXT    NOT NULL,
+		purpose     TEXT    NOT NULL,
+		variant     TEXT    NOT NULL,
+		served_at   INTEGER NOT NULL,
+		UNIQUE (session_id, question_id)
+	);
+	CREATE INDEX question_exposures_variant ON question_exposures (variant);`,
+}
+
+type SQLiteExposureRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteExposureRepository(db *sql.DB) (*SQLiteExposureRepository, error) {
+	if err := storage.Migrate(db, "question_exposures", exposureMigrations); err != nil {
+		return nil, err
+	}
+	return &SQLiteExposureRepository{db: db}, nil
+}
+
+func (r *SQLiteExposureRepository) RecordExposure(ctx context.Context, e Exposure) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO question_exposures
+			(user_id, session_id, question_id, topic_id, purpose, variant, served_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (session_id, question_id) DO NOTHING`,
+		e.UserID, e.SessionID, e.QuestionID, e.TopicID, string(e.Purpose), e.Variant, toUnixNano(e.ServedAt),
+	)
+	return err
+}
+
+func (r *SQLiteExposureRepository) ListExposures(ctx context.Context, variant string) ([]Exposure, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT user_id, session_id, question_id, topic_id, purpose, variant, served_at
+		FROM question_exposures WHERE variant = ? ORDER BY id`, variant)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]Exposure, 0)
+	for rows.Next() {
+		var (
+			e        Exposure
+			purpose  string
+			servedAt int64
+		)
+		if err := rows.Scan(&e.UserID, &e.SessionID, &e.QuestionID, &e.TopicID, &purpose, &e.Variant, &servedAt); err != nil {
+			return nil, err
+		}
+		e.Purpose = QuestionPurpose(purpose)
+		e.ServedAt = fromUnixNano(servedAt)
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
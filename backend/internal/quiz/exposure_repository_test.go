@@ -0,0 +1,72 @@
+package quiz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bugii1995/backend/internal/storage"
+)
+
+func TestMemoryExposureRepository(t *testing.T) {
+	testExposureRepositoryContract(t, func(t *testing.T) ExposureRepository {
+		return NewMemoryExposureRepository()
+	})
+}
+
+func TestSQLiteExposureRepository(t *testing.T) {
+	testExposureRepositoryContract(t, func(t *testing.T) ExposureRepository {
+		db, err := storage.Open(":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		repo, err := NewSQLiteExposureRepository(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return repo
+	})
+}
+
+// testExposureRepositoryContract is run against every
+// ExposureRepository.
+func testExposureRepositoryContract(t *testing.T, newRepo func(t *testing.T) ExposureRepository) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("ListsByVariant", func(t *testing.T) {
+		repo := newRepo(t)
+
+		repo.RecordExposure(ctx, Exposure{UserID: 1, SessionID: "s1", QuestionID: 3, TopicID: "articles", Purpose: PurposeProgress, Variant: "rules", ServedAt: now})
+		repo.RecordExposure(ctx, Exposure{UserID: 2, SessionID: "s2", QuestionID: 3, TopicID: "articles", Purpose: PurposeProgress, Variant: "weighted", ServedAt: now})
+		repo.RecordExposure(ctx, Exposure{UserID: 1, SessionID: "s1", QuestionID: 5, TopicID: "tenses", Purpose: PurposeReview, Variant: "rules", ServedAt: now})
+
+		got, err := repo.ListExposures(ctx, "rules")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 || got[0].QuestionID != 3 || got[1].QuestionID != 5 {
+			t.Fatalf("expected the rules exposures in order, got %+v", got)
+		}
+		if got[1].Purpose != PurposeReview || got[1].TopicID != "tenses" || !got[1].ServedAt.Equal(now) {
+			t.Fatalf("exposure changed on the way: %+v", got[1])
+		}
+	})
+
+	t.Run("RecordOnce", func(t *testing.T) {
+		repo := newRepo(t)
+
+		e := Exposure{UserID: 1, SessionID: "s1", QuestionID: 3, TopicID: "articles", Purpose: PurposeProgress, Variant: "rules", ServedAt: now}
+		for range 2 {
+			if err := repo.RecordExposure(ctx, e); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if got, _ := repo.ListExposures(ctx, "rules"); len(got) != 1 {
+			t.Fatalf("expected a repeated exposure to be kept once, got %+v", got)
+		}
+	})
+}
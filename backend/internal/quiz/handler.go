@@ -1,15 +1,75 @@
 package quiz
 
 import (
+	"context"
+	"errors"
+	"io"
 	"net/http"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/bugii1995/backend/internal/auth"
+	"github.com/bugii1995/backend/internal/entitlements"
+	"github.com/bugii1995/backend/internal/httperr"
+	"github.com/bugii1995/backend/internal/keylock"
+	"github.com/bugii1995/backend/internal/users"
 )
 
-// ---------------- In-memory session store ----------------
+// ---------------- Handler ----------------
+
+// Handler serves the quiz endpoints.
+type Handler struct {
+	Questions QuestionRepository
+	Sessions  SessionStore
+	Progress  ProgressRepository
+
+	// Entitlements meters free accounts and locks paid-only topics;
+	// nil leaves every account unmetered.
+	Entitlements *entitlements.Service
+
+	// Scope, if set, narrows a learner's pool to assigned topics.
+	Scope TopicScope
+
+	// Catalog, if set, gates topics behind their prerequisites and
+	// breaks ties between equally weak topics by curriculum.
+	Catalog *TopicCatalog
 
-var sessions = make(map[string]*Session)
+	// Selector picks questions within a session; nil means
+	// RuleSelector. Experiment, if set, overrides it per user.
+	Selector   Selector
+	Experiment *Experiment
+
+	// Pedagogy, if set, supplies the mastery and selection config;
+	// otherwise the defaults apply.
+	Pedagogy *PedagogyStore
+
+	// Estimator, if set, scores answers instead of the pedagogy's
+	// fixed deltas. One estimator serves every session, so ones that
+	// learn, like EloEstimator, learn from every learner.
+	Estimator MasteryEstimator
+
+	// Ratings, if set, saves the question ratings Estimator learns,
+	// when it is a QuestionRater.
+	Ratings QuestionRatingRepository
+
+	// Exposures, if set, records every question served, with its
+	// experiment variant, whether or not it is answered.
+	Exposures ExposureRepository
+
+	// answerLocks runs one answer per user at a time, so each answer's
+	// load-update-save is atomic across all of the user's sessions.
+	answerLocks keylock.Mutex
+}
+
+// TopicScope restricts which topics a learner is quizzed on, e.g. to
+// what their class was assigned. ok is false when the learner is not
+// restricted.
+type TopicScope interface {
+	AssignedTopics(ctx context.Context, user users.User) (topicIDs []string, ok bool, err error)
+}
 
 // ---------------- DTOs ----------------
 
@@ -21,17 +81,23 @@ type QuestionResponse struct {
 	Purpose string   `json:"purpose"`
 }
 
+// StartQuizRequest optionally narrows the quiz to some topics. An
+// empty body quizzes every topic the learner can access.
+type StartQuizRequest struct {
+	TopicIDs []string `json:"topic_ids"`
+}
+
 type StartQuizResponse struct {
 	SessionID string           `json:"session_id"`
 	Question  QuestionResponse `json:"question"`
 }
 
+// AnswerQuizRequest carries only what the learner chose; topic and
+// difficulty are looked up server-side from the question.
 type AnswerQuizRequest struct {
 	SessionID      string `json:"session_id"`
 	QuestionID     int64  `json:"question_id"`
-	TopicID        string `json:"topic_id"`
 	SelectedOption string `json:"selected_option"`
-	Difficulty     int    `json:"difficulty"`
 }
 
 type AnswerQuizResponse struct {
@@ -53,62 +119,378 @@ func toQuestionResponse(q Question, purpose QuestionPurpose) QuestionResponse {
 	}
 }
 
-func findQuestionByID(questions []Question, id int64) Question {
+func findQuestionByID(questions []Question, id int64) (Question, error) {
 	for _, q := range questions {
 		if q.ID == id {
-			return q
+			return q, nil
+		}
+	}
+	return Question{}, httperr.WithDetails(ErrQuestionNotFound, gin.H{"question_id": id})
+}
+
+// quotaKind is the entitlement a question served for purpose uses.
+func quotaKind(purpose QuestionPurpose) entitlements.Kind {
+	if purpose == PurposeReview {
+		return entitlements.KindReview
+	}
+	return entitlements.KindQuestion
+}
+
+// filterQuestions keeps the questions whose topic passes keep.
+func filterQuestions(questions []Question, keep func(topicID string) bool) []Question {
+	out := make([]Question, 0, len(questions))
+	for _, q := range questions {
+		if keep(q.TopicID) {
+			out = append(out, q)
+		}
+	}
+	return out
+}
+
+// loadProgress returns the user's stored progress, plus InitialMastery
+// for every topic in questions they have not answered yet.
+func (h *Handler) loadProgress(ctx context.Context, userID uint64, questions []Question) ([]TopicProgress, error) {
+	progress, err := h.Progress.ListProgress(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(progress))
+	for _, p := range progress {
+		known[p.TopicID] = true
+	}
+	for _, q := range questions {
+		if !known[q.TopicID] {
+			known[q.TopicID] = true
+			progress = append(progress, TopicProgress{TopicID: q.TopicID, Mastery: InitialMastery})
+		}
+	}
+	return progress, nil
+}
+
+// practicable reports whether user can work up a topic: it has
+// published questions they may access, or they have progress on it.
+// Only such topics gate others.
+func (h *Handler) practicable(user users.User, bank []Question, progress []TopicProgress) func(topicID string) bool {
+	topics := make(map[string]bool)
+	for _, q := range bank {
+		if h.Entitlements.CanAccessTopic(user, q.TopicID) {
+			topics[q.TopicID] = true
+		}
+	}
+	for _, p := range progress {
+		topics[p.TopicID] = true
+	}
+	return func(topicID string) bool { return topics[topicID] }
+}
+
+// pedagogy is the config for one request.
+func (h *Handler) pedagogy() *PedagogyConfig {
+	if h.Pedagogy == nil {
+		return nil
+	}
+	return h.Pedagogy.Current()
+}
+
+// selectorFor returns the selector of a session's variant. Sessions
+// whose variant is gone, e.g. after the experiment changed, fall back
+// to h.Selector.
+func (h *Handler) selectorFor(variant string) Selector {
+	if h.Experiment != nil {
+		if selector, ok := h.Experiment.Selector(variant); ok {
+			return selector
 		}
 	}
-	panic("question not found")
+	return h.Selector
+}
+
+// prepare sets up a session for this request with what is not stored:
+// the learner's history and this request's selector, config and
+// estimator.
+func (h *Handler) prepare(ctx context.Context, session *Session) error {
+	shown, err := h.Progress.LastAnswered(ctx, session.UserID)
+	if err != nil {
+		return err
+	}
+	session.LastShown = shown
+	session.Selector = h.selectorFor(session.Variant)
+	session.Pedagogy = h.pedagogy()
+	session.Estimator = h.Estimator
+	return nil
+}
+
+// restore rebuilds a loaded session's questions from the bank and its
+// progress from the store, then prepares it.
+func (h *Handler) restore(ctx context.Context, session *Session) error {
+	bank, err := h.Questions.ListByStatus(ctx, StatusPublished)
+	if err != nil {
+		return err
+	}
+	session.Restore(bank)
+	if err := h.sync(ctx, session); err != nil {
+		return err
+	}
+	return h.prepare(ctx, session)
+}
+
+// sync loads the owner's stored progress and reviews into session.
+func (h *Handler) sync(ctx context.Context, session *Session) error {
+	progress, err := h.Progress.ListProgress(ctx, session.UserID)
+	if err != nil {
+		return err
+	}
+	reviews, err := h.Progress.ListReviews(ctx, session.UserID)
+	if err != nil {
+		return err
+	}
+	session.Sync(progress, reviews)
+	return nil
+}
+
+// recordServed records that session served selected, if exposures
+// are kept.
+func (h *Handler) recordServed(ctx context.Context, session *Session, selected *SelectedQuestion, q Question, now time.Time) error {
+	if h.Exposures == nil {
+		return nil
+	}
+	return h.Exposures.RecordExposure(ctx, Exposure{
+		UserID:     session.UserID,
+		SessionID:  session.ID,
+		QuestionID: selected.QuestionID,
+		TopicID:    q.TopicID,
+		Purpose:    selected.Purpose,
+		Variant:    selected.Variant,
+		ServedAt:   now,
+	})
+}
+
+// limitReviews keeps reviews out of session's selection once user
+// has none left, counting the answer being graded if it spends one.
+// Otherwise a due review would be served that cannot be answered.
+func (h *Handler) limitReviews(ctx context.Context, user users.User, session *Session, spending QuestionPurpose) error {
+	left, err := h.Entitlements.Remaining(ctx, user, entitlements.KindReview)
+	if err != nil {
+		return err
+	}
+	if spending == PurposeReview {
+		left--
+	}
+	session.SkipReviews = left <= 0
+	return nil
+}
+
+// applyAnswer grades a new answer and saves it. Quota is checked
+// before and charged after the save, so an attempt that fails is not
+// charged and its retry is not charged twice.
+func (h *Handler) applyAnswer(
+	ctx context.Context,
+	user users.User,
+	session *Session,
+	q Question,
+	purpose QuestionPurpose,
+	selectedOption string,
+	now time.Time,
+) (GradedAnswer, error) {
+	kind := quotaKind(purpose)
+	if err := h.Entitlements.Check(ctx, user, kind); err != nil {
+		return GradedAnswer{}, err
+	}
+	if err := h.limitReviews(ctx, user, session, purpose); err != nil {
+		return GradedAnswer{}, err
+	}
+
+	graded, err := session.SubmitServedAnswer(q, selectedOption, now)
+	if err != nil {
+		return GradedAnswer{}, httperr.WithDetails(err, gin.H{"question_id": q.ID})
+	}
+
+	if err := h.Progress.SaveAnswer(ctx, user.ID, AnswerRecord{
+		TopicID:    q.TopicID,
+		QuestionID: q.ID,
+		WasCorrect: graded.WasCorrect,
+		Mastery:    graded.Mastery,
+		AnsweredAt: now,
+		Variant:    session.Variant,
+		SessionID:  session.ID,
+	}, graded.Progress, graded.Review); err != nil {
+		return GradedAnswer{}, err
+	}
+	if err := h.commitRating(ctx, graded.Mastery.Rating); err != nil {
+		return GradedAnswer{}, err
+	}
+	if err := h.Entitlements.Consume(ctx, user, kind); err != nil {
+		return GradedAnswer{}, err
+	}
+	return graded, nil
+}
+
+// commitRating applies the question rating change a saved answer
+// proposed, if the estimator rates questions, and saves the new rating
+// if ratings are kept. A replayed answer never gets here, so no answer
+// is rated twice.
+func (h *Handler) commitRating(ctx context.Context, u RatingUpdate) error {
+	rater, ok := h.Estimator.(QuestionRater)
+	if !ok || u.QuestionID == 0 {
+		return nil
+	}
+	rating := rater.Commit(u)
+	if h.Ratings == nil {
+		return nil
+	}
+	return h.Ratings.SaveRating(ctx, rating)
+}
+
+// replayAnswer finishes an answer saved by an earlier attempt that
+// failed afterwards: the session moves on with the stored outcome, and
+// nothing is saved or charged again.
+func (h *Handler) replayAnswer(
+	ctx context.Context,
+	user users.User,
+	session *Session,
+	q Question,
+	recorded AnswerRecord,
+	selectedOption string,
+	now time.Time,
+) (GradedAnswer, error) {
+	// The earlier attempt already spent the answer's quota.
+	if err := h.limitReviews(ctx, user, session, ""); err != nil {
+		return GradedAnswer{}, err
+	}
+	graded, err := session.SubmitServedAnswer(q, selectedOption, now)
+	if err != nil {
+		return GradedAnswer{}, httperr.WithDetails(err, gin.H{"question_id": q.ID})
+	}
+
+	// The session graded on progress that already held the answer.
+	if err := h.sync(ctx, session); err != nil {
+		return GradedAnswer{}, err
+	}
+	graded.WasCorrect = recorded.WasCorrect
+	graded.Mastery = recorded.Mastery
+	return graded, nil
 }
 
 // ---------------- Handlers ----------------
+//
+// Both handlers run behind auth.RequireUser. Errors are reported with
+// c.Error and rendered by httperr.Middleware.
 
 // POST /quiz/start
-func StartQuiz(c *gin.Context) {
+func (h *Handler) StartQuiz(c *gin.Context) {
+	user, ok := auth.CurrentUser(c)
+	if !ok {
+		c.Error(auth.ErrUnauthenticated)
+		return
+	}
+
+	var req StartQuizRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(httperr.BadRequest(err))
+		return
+	}
+
 	now := time.Now()
 
-	// ---- In-memory questions (Milestone 1) ----
-	questions := []Question{
-		{
-			ID:             1,
-			TopicID:        "articles",
-			Difficulty:     1,
-			Prompt:         "Choose the correct article: ___ apple",
-			Options:        []string{"a", "an", "the"},
-			CorrectAnswer:  "an",
-			Explanation:    "We use 'an' before words that start with a vowel sound.",
-		},
-		{
-			ID:             2,
-			TopicID:        "articles",
-			Difficulty:     2,
-			Prompt:         "Choose the correct article: ___ university",
-			Options:        []string{"a", "an", "the"},
-			CorrectAnswer:  "a",
-			Explanation:    "'University' starts with a 'you' sound, so we use 'a'.",
-		},
-	}
-	// ------------------------------------------
-
-	progress := []TopicProgress{
-		{
-			TopicID:  "articles",
-			Mastery:  40,
-			LastSeen: now,
-		},
-	}
-
-	session := NewSession(questions, progress, nil)
-	sessions[session.ID] = session
+	bank, err := h.Questions.ListByStatus(c.Request.Context(), StatusPublished)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	questions := bank
+
+	// Topics the learner was assigned or asked for are never locked
+	// behind their prerequisites.
+	var exempt []string
+
+	if h.Scope != nil {
+		assigned, ok, err := h.Scope.AssignedTopics(c.Request.Context(), user)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if ok {
+			questions = filterQuestions(questions, func(topicID string) bool {
+				return slices.Contains(assigned, topicID)
+			})
+			if !slices.ContainsFunc(questions, func(q Question) bool { return h.Entitlements.CanAccessTopic(user, q.TopicID) }) {
+				c.Error(httperr.WithDetails(ErrNoAssignedQuestions, gin.H{"topic_ids": assigned}))
+				return
+			}
+			exempt = append(exempt, assigned...)
+		}
+	}
+
+	if len(req.TopicIDs) > 0 {
+		if err := h.Entitlements.CheckTopics(user, req.TopicIDs); err != nil {
+			c.Error(err)
+			return
+		}
+		questions = filterQuestions(questions, func(topicID string) bool {
+			return slices.Contains(req.TopicIDs, topicID)
+		})
+		exempt = append(exempt, req.TopicIDs...)
+	} else {
+		questions = filterQuestions(questions, func(topicID string) bool {
+			return h.Entitlements.CanAccessTopic(user, topicID)
+		})
+	}
+
+	progress, err := h.loadProgress(c.Request.Context(), user.ID, questions)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	reviews, err := h.Progress.ListReviews(c.Request.Context(), user.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	session := NewSession(questions, progress, reviews)
+	session.UserID = user.ID
+	if h.Experiment != nil {
+		session.Variant = h.Experiment.Assign(user.ID).Name
+	}
+	if err := h.prepare(c.Request.Context(), session); err != nil {
+		c.Error(err)
+		return
+	}
+	if err := h.limitReviews(c.Request.Context(), user, session, ""); err != nil {
+		c.Error(err)
+		return
+	}
+	if h.Catalog != nil {
+		session.Curriculum = h.Catalog.Curriculum(h.practicable(user, bank, progress))
+		session.Curriculum.Exempt = exempt
+	}
 
 	selected := session.NextQuestion(now)
 	if selected == nil {
-		c.JSON(http.StatusOK, gin.H{"error": "no questions available"})
+		c.Error(ErrNoQuestionsAvailable)
 		return
 	}
 
-	q := findQuestionByID(session.Questions, selected.QuestionID)
+	// Quota is used when answering; only refuse to start a quiz whose
+	// first question could not be answered.
+	if err := h.Entitlements.Check(c.Request.Context(), user, quotaKind(selected.Purpose)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.Sessions.Put(c.Request.Context(), session); err != nil {
+		c.Error(err)
+		return
+	}
+
+	q, err := findQuestionByID(session.Questions, selected.QuestionID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := h.recordServed(c.Request.Context(), session, selected, q, now); err != nil {
+		c.Error(err)
+		return
+	}
 
 	c.JSON(http.StatusOK, StartQuizResponse{
 		SessionID: session.ID,
@@ -117,54 +499,112 @@ func StartQuiz(c *gin.Context) {
 }
 
 // POST /quiz/answer
-func AnswerQuiz(c *gin.Context) {
+func (h *Handler) AnswerQuiz(c *gin.Context) {
+	user, ok := auth.CurrentUser(c)
+	if !ok {
+		c.Error(auth.ErrUnauthenticated)
+		return
+	}
+
 	var req AnswerQuizRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(httperr.BadRequest(err))
 		return
 	}
 
-	session, ok := sessions[req.SessionID]
-	if !ok {
-		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+	unlock := h.answerLocks.Lock(strconv.FormatUint(user.ID, 10))
+	defer unlock()
+
+	ctx := c.Request.Context()
+
+	session, err := h.Sessions.Get(ctx, req.SessionID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	// Someone else's session is reported as missing, not forbidden,
+	// so session IDs cannot be probed.
+	if session.UserID != user.ID {
+		c.Error(ErrSessionNotFound)
+		return
+	}
+	if err := h.restore(ctx, session); err != nil {
+		c.Error(err)
 		return
 	}
 
 	now := time.Now()
 
-	answered := findQuestionByID(session.Questions, req.QuestionID)
-	wasCorrect := req.SelectedOption == answered.CorrectAnswer
+	// Grade against the question as served. Questions the session did
+	// not serve, and sessions stored before served questions were kept,
+	// fall back to the bank.
+	answered, ok := session.ServedQuestion(req.QuestionID)
+	if !ok {
+		answered, err = h.Questions.GetByID(ctx, req.QuestionID)
+		if errors.Is(err, ErrQuestionNotFound) {
+			c.Error(httperr.WithDetails(err, gin.H{"question_id": req.QuestionID}))
+			return
+		}
+		if err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	purpose, err := session.CheckAnswer(answered, req.SelectedOption)
+	if err != nil {
+		c.Error(httperr.WithDetails(err, gin.H{"question_id": req.QuestionID}))
+		return
+	}
+
+	// The answer is saved before the session, so a retry after a failed
+	// session write finds it already recorded.
+	var graded GradedAnswer
+	recorded, err := h.Progress.FindAnswer(ctx, user.ID, session.ID, answered.ID)
+	switch {
+	case errors.Is(err, ErrAnswerNotFound):
+		graded, err = h.applyAnswer(ctx, user, session, answered, purpose, req.SelectedOption, now)
+	case err == nil:
+		graded, err = h.replayAnswer(ctx, user, session, answered, recorded, req.SelectedOption, now)
+	}
+	if err != nil {
+		c.Error(err)
+		return
+	}
 
-	next, update := session.SubmitAnswer(
-		Answer{
-			QuestionID: req.QuestionID,
-			TopicID:    req.TopicID,
-			WasCorrect: wasCorrect,
-			Difficulty: req.Difficulty,
-		},
-		now,
-	)
+	if err := h.Sessions.Put(ctx, session); err != nil {
+		c.Error(err)
+		return
+	}
 
 	// ---- Finished ----
-	if next == nil {
+	if graded.Next == nil {
 		c.JSON(http.StatusOK, AnswerQuizResponse{
 			Status:      "finished",
-			Mastery:     update,
+			Mastery:     graded.Mastery,
 			Explanation: answered.Explanation,
-			IsCorrect:   wasCorrect,
+			IsCorrect:   graded.WasCorrect,
 		})
 		return
 	}
 
 	// ---- Continue ----
-	nextQ := findQuestionByID(session.Questions, next.QuestionID)
-	resp := toQuestionResponse(nextQ, next.Purpose)
+	nextQ, err := findQuestionByID(session.Questions, graded.Next.QuestionID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := h.recordServed(ctx, session, graded.Next, nextQ, now); err != nil {
+		c.Error(err)
+		return
+	}
+	resp := toQuestionResponse(nextQ, graded.Next.Purpose)
 
 	c.JSON(http.StatusOK, AnswerQuizResponse{
 		Status:       "continue",
 		NextQuestion: &resp,
-		Mastery:      update,
+		Mastery:      graded.Mastery,
 		Explanation:  answered.Explanation,
-		IsCorrect:    wasCorrect,
+		IsCorrect:    graded.WasCorrect,
 	})
 }
@@ -0,0 +1,916 @@
+package quiz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bugii1995/backend/internal/apitest"
+	"github.com/bugii1995/backend/internal/auth"
+	"github.com/bugii1995/backend/internal/entitlements"
+	"github.com/bugii1995/backend/internal/httperr"
+	"github.com/bugii1995/backend/internal/storage"
+	"github.com/bugii1995/backend/internal/users"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// testUserHeader selects the authenticated user in test routers.
+const testUserHeader = "X-Test-User"
+
+// Test users from paidTestUser up have paid accounts; the rest are free.
+const paidTestUser = 100
+
+func newTestRouter(h *Handler) *gin.Engine {
+	r := gin.New()
+	var rules []httperr.Rule
+	rules = append(rules, HTTPErrors...)
+	rules = append(rules, auth.HTTPErrors...)
+	rules = append(rules, entitlements.HTTPErrors...)
+	r.Use(httperr.Middleware(rules...))
+	r.Use(func(c *gin.Context) {
+		id := uint64(1)
+		if v := c.GetHeader(testUserHeader); v != "" {
+			id, _ = strconv.ParseUint(v, 10, 64)
+		}
+		user := users.User{ID: id, AccountType: users.AccountFree}
+		if id >= paidTestUser {
+			user.AccountType = users.AccountPaid
+		}
+		auth.SetCurrentUser(c, user)
+	})
+	r.POST("/quiz/start", h.StartQuiz)
+	r.POST("/quiz/answer", h.AnswerQuiz)
+	return r
+}
+
+func doJSON(t *testing.T, r http.Handler, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	return doJSONAs(t, r, 1, path, body)
+}
+
+func doJSONAs(t *testing.T, r http.Handler, userID uint64, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(testUserHeader, strconv.FormatUint(userID, 10))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// newTestHandler builds a Handler quizzing on questions, with in-memory
+// stores and the default plan. Tests set any other fields they need.
+func newTestHandler(questions ...Question) *Handler {
+	return &Handler{
+		Questions:    NewMemoryQuestionRepository(questions...),
+		Sessions:     NewMemorySessionStore(time.Hour),
+		Progress:     NewMemoryProgressRepository(),
+		Entitlements: entitlements.NewService(entitlements.NewMemoryQuotaRepository(), entitlements.DefaultPlan()),
+	}
+}
+
+// slowSessionStore widens the gap between Get and Put so unserialized
+// read-modify-write cycles would reliably interleave.
+type slowSessionStore struct {
+	SessionStore
+}
+
+func (s slowSessionStore) Get(ctx context.Context, id string) (*Session, error) {
+	session, err := s.SessionStore.Get(ctx, id)
+	time.Sleep(time.Millisecond)
+	return session, err
+}
+
+func TestAnswerQuizConcurrentRequests(t *testing.T) {
+	const (
+		rounds = 10
+		racers = 8
+	)
+
+	db, err := storage.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// The SQLite store returns a fresh *Session per Get, so this also
+	// checks that concurrent answers do not overwrite each other.
+	store, err := NewSQLiteSessionStore(db, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	questions := make([]Question, rounds)
+	for i := range questions {
+		questions[i] = Question{
+			TopicID:       "articles",
+			Difficulty:    2,
+			Options:       []string{"a", "an"},
+			CorrectAnswer: "a",
+		}
+	}
+
+	h := newTestHandler(questions...)
+	h.Sessions = slowSessionStore{store}
+	r := newTestRouter(h)
+
+	w := doJSON(t, r, "/quiz/start", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("start: %d %s", w.Code, w.Body.String())
+	}
+	start := apitest.Decode[StartQuizResponse](t, w)
+	served := start.Question.ID
+
+	// Each round, several tabs answer the same served question at once:
+	// exactly one must win and the rest must see a conflict.
+	for round := 0; round < rounds; round++ {
+		var (
+			wg    sync.WaitGroup
+			mu    sync.Mutex
+			oks   []AnswerQuizResponse
+			codes = make(map[int]int)
+		)
+		for i := 0; i < racers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				w := doJSON(t, r, "/quiz/answer", AnswerQuizRequest{
+					SessionID:      start.SessionID,
+					QuestionID:     served,
+					SelectedOption: "a",
+				})
+
+				mu.Lock()
+				defer mu.Unlock()
+				codes[w.Code]++
+				if w.Code == http.StatusOK {
+					oks = append(oks, apitest.Decode[AnswerQuizResponse](t, w))
+				}
+			}()
+		}
+		wg.Wait()
+
+		if codes[http.StatusOK] != 1 || codes[http.StatusConflict] != racers-1 {
+			t.Fatalf("round %d: expected 1 OK and %d conflicts, got %v", round, racers-1, codes)
+		}
+		if next := oks[0].NextQuestion; next != nil {
+			served = next.ID
+		}
+	}
+
+	session, err := store.Get(context.Background(), start.SessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(session.AskedQuestions) != rounds {
+		t.Fatalf("expected %d asked questions, got %d", rounds, len(session.AskedQuestions))
+	}
+	if streak := session.Progress["articles"].CorrectStreak; streak != rounds {
+		t.Fatalf("expected correct streak %d, got %d", rounds, streak)
+	}
+}
+
+// ---------------- Tampering ----------------
+
+func tamperFixture(t *testing.T) (*Handler, *gin.Engine, StartQuizResponse) {
+	t.Helper()
+
+	h := newTestHandler(
+		Question{ID: 1, TopicID: "articles", Difficulty: 1, Options: []string{"a", "an", "the"}, CorrectAnswer: "an"},
+		Question{ID: 2, TopicID: "articles", Difficulty: 2, Options: []string{"a", "an", "the"}, CorrectAnswer: "a"},
+		Question{ID: 3, TopicID: "articles", Difficulty: 3, Options: []string{"a", "an", "the"}, CorrectAnswer: "the"},
+	)
+	r := newTestRouter(h)
+
+	w := doJSON(t, r, "/quiz/start", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("start: %d %s", w.Code, w.Body.String())
+	}
+	start := apitest.Decode[StartQuizResponse](t, w)
+
+	// Mastery 40 is "progress", which serves the medium question.
+	if start.Question.ID != 2 {
+		t.Fatalf("expected question 2 to be served first, got %d", start.Question.ID)
+	}
+	return h, r, start
+}
+
+type errorBody = httperr.Envelope
+
+func TestAnswerQuizGradesQuestionAsServed(t *testing.T) {
+	h, _, _ := tamperFixture(t)
+	ctx := context.Background()
+
+	db, err := storage.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	h.Sessions, err = NewSQLiteSessionStore(db, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newTestRouter(h)
+
+	start := apitest.Decode[StartQuizResponse](t, doJSON(t, r, "/quiz/start", nil))
+	served, err := h.Questions.GetByID(ctx, start.Question.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An editor rewrites the question while the learner thinks.
+	edited := served
+	edited.TopicID = "conditionals"
+	edited.Options = []string{"if", "when"}
+	edited.CorrectAnswer = "when"
+	edited.Explanation = "edited"
+	if _, err := h.Questions.Save(ctx, edited); err != nil {
+		t.Fatal(err)
+	}
+
+	w := doJSON(t, r, "/quiz/answer", AnswerQuizRequest{SessionID: start.SessionID, QuestionID: served.ID, SelectedOption: served.CorrectAnswer})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected the served options to be accepted, got %d %s", w.Code, w.Body.String())
+	}
+	answered := apitest.Decode[AnswerQuizResponse](t, w)
+	if !answered.IsCorrect || answered.Explanation != served.Explanation {
+		t.Fatalf("expected grading against the served question, got %+v", answered)
+	}
+
+	stored, err := h.Progress.ListProgress(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 1 || stored[0].TopicID != "articles" {
+		t.Fatalf("expected the served topic to move, got %+v", stored)
+	}
+}
+
+func TestAnswerQuizIgnoresClientDifficulty(t *testing.T) {
+	_, r, start := tamperFixture(t)
+
+	w := doJSON(t, r, "/quiz/answer", map[string]any{
+		"session_id":      start.SessionID,
+		"question_id":     start.Question.ID,
+		"selected_option": "a",
+		"difficulty":      3, // question is actually medium
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	got := apitest.Decode[AnswerQuizResponse](t, w)
+
+	want := UpdateMastery(
+		TopicProgress{TopicID: "articles", Mastery: 40},
+		MasteryUpdateInput{WasCorrect: true, Difficulty: 2},
+	)
+	if got.Mastery.Mastery != want.Mastery {
+		t.Fatalf("expected medium-difficulty mastery %v, got %v", want.Mastery, got.Mastery.Mastery)
+	}
+}
+
+func TestAnswerQuizIgnoresClientTopic(t *testing.T) {
+	h, r, start := tamperFixture(t)
+
+	w := doJSON(t, r, "/quiz/answer", map[string]any{
+		"session_id":      start.SessionID,
+		"question_id":     start.Question.ID,
+		"selected_option": "a",
+		"topic_id":        "conditionals",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", w.Code, w.Body.String())
+	}
+
+	session, err := h.Sessions.Get(context.Background(), start.SessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := session.Progress["conditionals"]; ok {
+		t.Fatal("client-supplied topic must not receive progress")
+	}
+	if session.Progress["articles"].CorrectStreak != 1 {
+		t.Fatalf("expected progress on the question's own topic, got %+v", session.Progress["articles"])
+	}
+}
+
+func TestAnswerQuizRejectsTampering(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       func(start StartQuizResponse) map[string]any
+		answerMore bool
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name: "unknown question",
+			body: func(start StartQuizResponse) map[string]any {
+				return map[string]any{"session_id": start.SessionID, "question_id": 999, "selected_option": "a"}
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "question_not_found",
+		},
+		{
+			name: "question never served",
+			body: func(start StartQuizResponse) map[string]any {
+				return map[string]any{"session_id": start.SessionID, "question_id": 3, "selected_option": "the"}
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "question_not_served",
+		},
+		{
+			name: "option not offered",
+			body: func(start StartQuizResponse) map[string]any {
+				return map[string]any{"session_id": start.SessionID, "question_id": start.Question.ID, "selected_option": "some"}
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "invalid_option",
+		},
+		{
+			name: "already answered",
+			body: func(start StartQuizResponse) map[string]any {
+				return map[string]any{"session_id": start.SessionID, "question_id": start.Question.ID, "selected_option": "a"}
+			},
+			answerMore: true,
+			wantStatus: http.StatusConflict,
+			wantCode:   "already_answered",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, r, start := tamperFixture(t)
+
+			if tt.answerMore {
+				w := doJSON(t, r, "/quiz/answer", tt.body(start))
+				if w.Code != http.StatusOK {
+					t.Fatalf("first answer: %d %s", w.Code, w.Body.String())
+				}
+			}
+
+			before, _ := h.Sessions.Get(context.Background(), start.SessionID)
+			asked := len(before.AskedQuestions)
+			progress := before.Progress["articles"]
+
+			w := doJSON(t, r, "/quiz/answer", tt.body(start))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if body := apitest.Decode[errorBody](t, w); body.Code != tt.wantCode || body.Message == "" {
+				t.Fatalf("expected code %q with a message, got %+v", tt.wantCode, body)
+			}
+
+			after, _ := h.Sessions.Get(context.Background(), start.SessionID)
+			if len(after.AskedQuestions) != asked || after.Progress["articles"] != progress {
+				t.Fatal("rejected answer must not change the session")
+			}
+		})
+	}
+}
+
+// ---------------- Error envelope ----------------
+
+func TestStartQuizNoQuestions(t *testing.T) {
+	h := newTestHandler()
+
+	w := doJSON(t, newTestRouter(h), "/quiz/start", nil)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d %s", w.Code, w.Body.String())
+	}
+	if body := apitest.Decode[errorBody](t, w); body.Code != "no_questions_available" {
+		t.Fatalf("unexpected body %+v", body)
+	}
+}
+
+func TestAnswerQuizErrorEnvelope(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       func(start StartQuizResponse) any
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "malformed JSON",
+			body:       func(StartQuizResponse) any { return "not an object" },
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_request",
+		},
+		{
+			name: "unknown session",
+			body: func(start StartQuizResponse) any {
+				return AnswerQuizRequest{SessionID: "missing", QuestionID: start.Question.ID, SelectedOption: "a"}
+			},
+			wantStatus: http.StatusNotFound,
+			wantCode:   "session_not_found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, r, start := tamperFixture(t)
+
+			w := doJSON(t, r, "/quiz/answer", tt.body(start))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if body := apitest.Decode[errorBody](t, w); body.Code != tt.wantCode || body.Message == "" {
+				t.Fatalf("expected code %q, got %+v", tt.wantCode, body)
+			}
+		})
+	}
+}
+
+func TestAnswerQuizUnknownQuestionDetails(t *testing.T) {
+	_, r, start := tamperFixture(t)
+
+	w := doJSON(t, r, "/quiz/answer", AnswerQuizRequest{
+		SessionID:      start.SessionID,
+		QuestionID:     999,
+		SelectedOption: "a",
+	})
+
+	body := apitest.Decode[errorBody](t, w)
+	details, ok := body.Details.(map[string]any)
+	if !ok || details["question_id"] != float64(999) {
+		t.Fatalf("expected question_id in details, got %+v", body)
+	}
+}
+
+func TestAnswerQuizFinishedSession(t *testing.T) {
+	h := newTestHandler(
+		Question{ID: 1, TopicID: "articles", Difficulty: 2, Options: []string{"a", "an"}, CorrectAnswer: "a"},
+	)
+	r := newTestRouter(h)
+
+	start := apitest.Decode[StartQuizResponse](t, doJSON(t, r, "/quiz/start", nil))
+
+	w := doJSON(t, r, "/quiz/answer", AnswerQuizRequest{SessionID: start.SessionID, QuestionID: 1, SelectedOption: "a"})
+	if got := apitest.Decode[AnswerQuizResponse](t, w); got.Status != "finished" {
+		t.Fatalf("expected finished, got %+v", got)
+	}
+
+	// Any further answer to a finished session is a conflict, even for
+	// a question added to the bank after the session started.
+	h.Questions.Save(context.Background(), Question{ID: 2, TopicID: "articles", Difficulty: 2, Options: []string{"a", "an"}, CorrectAnswer: "a"})
+	w = doJSON(t, r, "/quiz/answer", AnswerQuizRequest{SessionID: start.SessionID, QuestionID: 2, SelectedOption: "a"})
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d %s", w.Code, w.Body.String())
+	}
+	if body := apitest.Decode[errorBody](t, w); body.Code != "session_finished" {
+		t.Fatalf("unexpected body %+v", body)
+	}
+}
+
+// ---------------- Ownership ----------------
+
+func TestStartQuizAttachesUser(t *testing.T) {
+	h, r, _ := tamperFixture(t)
+
+	w := doJSONAs(t, r, 42, "/quiz/start", nil)
+	start := apitest.Decode[StartQuizResponse](t, w)
+
+	session, err := h.Sessions.Get(context.Background(), start.SessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.UserID != 42 {
+		t.Fatalf("expected session owned by user 42, got %d", session.UserID)
+	}
+}
+
+func TestAnswerQuizRejectsOtherUsersSession(t *testing.T) {
+	_, r, start := tamperFixture(t)
+
+	w := doJSONAs(t, r, 2, "/quiz/answer", AnswerQuizRequest{
+		SessionID:      start.SessionID,
+		QuestionID:     start.Question.ID,
+		SelectedOption: "a",
+	})
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+// ---------------- Progress ----------------
+
+func TestStartQuizDefaultsProgressPerTopic(t *testing.T) {
+	h := newTestHandler(
+		Question{ID: 1, TopicID: "articles", Difficulty: 2, Options: []string{"a", "an"}, CorrectAnswer: "a"},
+		Question{ID: 2, TopicID: "conditionals", Difficulty: 2, Options: []string{"if", "when"}, CorrectAnswer: "if"},
+	)
+	h.Progress.SaveProgress(context.Background(), 1, TopicProgress{TopicID: "articles", Mastery: 75})
+
+	start := apitest.Decode[StartQuizResponse](t, doJSON(t, newTestRouter(h), "/quiz/start", nil))
+	session, _ := h.Sessions.Get(context.Background(), start.SessionID)
+
+	if got := session.Progress["articles"].Mastery; got != 75 {
+		t.Fatalf("expected stored articles mastery 75, got %v", got)
+	}
+	if got := session.Progress["conditionals"].Mastery; got != InitialMastery {
+		t.Fatalf("expected unseen topic at %v, got %v", InitialMastery, got)
+	}
+}
+
+func TestProgressCarriesAcrossSessions(t *testing.T) {
+	h, r, start := tamperFixture(t)
+	ctx := context.Background()
+
+	w := doJSON(t, r, "/quiz/answer", AnswerQuizRequest{SessionID: start.SessionID, QuestionID: start.Question.ID, SelectedOption: "a"})
+	answered := apitest.Decode[AnswerQuizResponse](t, w)
+
+	stored, err := h.Progress.ListProgress(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 1 || stored[0].Mastery != answered.Mastery.Mastery || stored[0].CorrectStreak != 1 {
+		t.Fatalf("expected answer to be persisted, got %+v", stored)
+	}
+	reviews, err := h.Progress.ListReviews(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reviews) != 1 || reviews[0].Repetitions != 1 || !reviews[0].NextReviewAt.After(time.Now()) {
+		t.Fatalf("expected a scheduled review to be persisted, got %+v", reviews)
+	}
+	history, err := h.Progress.ListAnswers(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].QuestionID != start.Question.ID || history[0].Mastery.Mastery != answered.Mastery.Mastery {
+		t.Fatalf("expected the answer in the history, got %+v", history)
+	}
+
+	// A new session picks up where the last one left off ...
+	next := apitest.Decode[StartQuizResponse](t, doJSON(t, r, "/quiz/start", nil))
+	session, _ := h.Sessions.Get(ctx, next.SessionID)
+	if got := session.Progress["articles"]; got.Mastery != answered.Mastery.Mastery || got.CorrectStreak != 1 {
+		t.Fatalf("expected stored progress in new session, got %+v", got)
+	}
+	if len(session.Reviews) != 1 {
+		t.Fatalf("expected stored reviews in new session, got %+v", session.Reviews)
+	}
+	if shown, ok := session.LastShown[start.Question.ID]; !ok || !shown.Equal(history[0].AnsweredAt) {
+		t.Fatalf("expected the answered question in LastShown, got %+v", session.LastShown)
+	}
+
+	// ... but only for the user who earned it.
+	other := apitest.Decode[StartQuizResponse](t, doJSONAs(t, r, 2, "/quiz/start", nil))
+	session, _ = h.Sessions.Get(ctx, other.SessionID)
+	if got := session.Progress["articles"].Mastery; got != InitialMastery {
+		t.Fatalf("expected user 2 to start at %v, got %v", InitialMastery, got)
+	}
+}
+
+func TestAnswerQuizBuildsOnOtherSessions(t *testing.T) {
+	h, r := entitlementsFixture(t, entitlements.Plan{FreeDailyQuestions: 10})
+	articles := StartQuizRequest{TopicIDs: []string{"articles"}}
+
+	// Two tabs: the first answers twice, then the second once.
+	first := apitest.Decode[StartQuizResponse](t, doJSON(t, r, "/quiz/start", articles))
+	second := apitest.Decode[StartQuizResponse](t, doJSON(t, r, "/quiz/start", articles))
+
+	served := first.Question.ID
+	var answered AnswerQuizResponse
+	for i := 0; i < 2; i++ {
+		answered = apitest.Decode[AnswerQuizResponse](t, doJSON(t, r, "/quiz/answer", AnswerQuizRequest{SessionID: first.SessionID, QuestionID: served, SelectedOption: "a"}))
+		served = answered.NextQuestion.ID
+	}
+
+	w := doJSON(t, r, "/quiz/answer", AnswerQuizRequest{SessionID: second.SessionID, QuestionID: second.Question.ID, SelectedOption: "a"})
+	last := apitest.Decode[AnswerQuizResponse](t, w)
+	if last.Mastery.Mastery <= answered.Mastery.Mastery || last.Mastery.CorrectStreak != 3 {
+		t.Fatalf("expected the second tab to build on %+v, got %+v", answered.Mastery, last.Mastery)
+	}
+
+	stored, err := h.Progress.ListProgress(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 1 || stored[0].Mastery != last.Mastery.Mastery {
+		t.Fatalf("expected %v stored, got %+v", last.Mastery.Mastery, stored)
+	}
+}
+
+// flakySessionStore fails Put while failing is set.
+type flakySessionStore struct {
+	SessionStore
+	failing bool
+}
+
+func (s *flakySessionStore) Put(ctx context.Context, session *Session) error {
+	if s.failing {
+		return errors.New("session store unavailable")
+	}
+	return s.SessionStore.Put(ctx, session)
+}
+
+func TestAnswerQuizRetryAfterFailedSessionWrite(t *testing.T) {
+	db, err := storage.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// The SQLite store hands out a fresh *Session per Get, so the failed
+	// attempt's changes are really lost.
+	store, err := NewSQLiteSessionStore(db, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions := &flakySessionStore{SessionStore: store}
+
+	h, _ := entitlementsFixture(t, entitlements.Plan{FreeDailyQuestions: 2})
+	h.Sessions = sessions
+	r := newTestRouter(h)
+	articles := StartQuizRequest{TopicIDs: []string{"articles"}}
+
+	start := apitest.Decode[StartQuizResponse](t, doJSON(t, r, "/quiz/start", articles))
+	answer := AnswerQuizRequest{SessionID: start.SessionID, QuestionID: start.Question.ID, SelectedOption: "a"}
+
+	sessions.failing = true
+	if w := doJSON(t, r, "/quiz/answer", answer); w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d %s", w.Code, w.Body.String())
+	}
+	sessions.failing = false
+
+	w := doJSON(t, r, "/quiz/answer", answer)
+	if w.Code != http.StatusOK {
+		t.Fatalf("retry: %d %s", w.Code, w.Body.String())
+	}
+	retried := apitest.Decode[AnswerQuizResponse](t, w)
+	if retried.Mastery.CorrectStreak != 1 {
+		t.Fatalf("expected the answer applied once, got %+v", retried.Mastery)
+	}
+
+	ctx := context.Background()
+	stored, err := h.Progress.ListProgress(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 1 || stored[0].Mastery != retried.Mastery.Mastery || stored[0].CorrectStreak != 1 {
+		t.Fatalf("expected the answer stored once, got %+v", stored)
+	}
+	if history, _ := h.Progress.ListAnswers(ctx, 1); len(history) != 1 {
+		t.Fatalf("expected one answer in the history, got %+v", history)
+	}
+
+	// Charged once: the second of two free questions is still allowed.
+	w = doJSON(t, r, "/quiz/answer", AnswerQuizRequest{SessionID: start.SessionID, QuestionID: retried.NextQuestion.ID, SelectedOption: "a"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected the retry not to use quota, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+// ---------------- Entitlements ----------------
+
+func entitlementsFixture(t *testing.T, plan entitlements.Plan) (*Handler, *gin.Engine) {
+	t.Helper()
+
+	h := newTestHandler(
+		Question{ID: 1, TopicID: "articles", Difficulty: 2, Options: []string{"a", "an"}, CorrectAnswer: "a"},
+		Question{ID: 2, TopicID: "articles", Difficulty: 2, Options: []string{"a", "an"}, CorrectAnswer: "a"},
+		Question{ID: 3, TopicID: "articles", Difficulty: 2, Options: []string{"a", "an"}, CorrectAnswer: "a"},
+		Question{ID: 4, TopicID: "conditionals", Difficulty: 2, Options: []string{"if", "when"}, CorrectAnswer: "if"},
+	)
+	h.Entitlements = entitlements.NewService(entitlements.NewMemoryQuotaRepository(), plan)
+	return h, newTestRouter(h)
+}
+
+func TestAnswerQuizQuotaExceeded(t *testing.T) {
+	_, r := entitlementsFixture(t, entitlements.Plan{FreeDailyQuestions: 2})
+	articles := StartQuizRequest{TopicIDs: []string{"articles"}}
+
+	start := apitest.Decode[StartQuizResponse](t, doJSON(t, r, "/quiz/start", articles))
+	served := start.Question.ID
+	for i := 0; i < 2; i++ {
+		w := doJSON(t, r, "/quiz/answer", AnswerQuizRequest{SessionID: start.SessionID, QuestionID: served, SelectedOption: "a"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("answer %d: %d %s", i, w.Code, w.Body.String())
+		}
+		served = apitest.Decode[AnswerQuizResponse](t, w).NextQuestion.ID
+	}
+
+	w := doJSON(t, r, "/quiz/answer", AnswerQuizRequest{SessionID: start.SessionID, QuestionID: served, SelectedOption: "a"})
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d %s", w.Code, w.Body.String())
+	}
+	body := apitest.Decode[errorBody](t, w)
+	details, _ := body.Details.(map[string]any)
+	if body.Code != "quota_exceeded" || details["limit"] != float64(2) || details["resets_at"] == nil {
+		t.Fatalf("unexpected body %+v", body)
+	}
+
+	// The quota is per user and covers new quizzes too.
+	if w := doJSON(t, r, "/quiz/start", articles); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected start to be refused, got %d %s", w.Code, w.Body.String())
+	}
+	if w := doJSONAs(t, r, 2, "/quiz/start", nil); w.Code != http.StatusOK {
+		t.Fatalf("expected another user to start, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAnswerQuizRejectedAnswerKeepsQuota(t *testing.T) {
+	_, r := entitlementsFixture(t, entitlements.Plan{FreeDailyQuestions: 1})
+	articles := StartQuizRequest{TopicIDs: []string{"articles"}}
+
+	start := apitest.Decode[StartQuizResponse](t, doJSON(t, r, "/quiz/start", articles))
+
+	w := doJSON(t, r, "/quiz/answer", AnswerQuizRequest{SessionID: start.SessionID, QuestionID: start.Question.ID, SelectedOption: "the"})
+	if body := apitest.Decode[errorBody](t, w); body.Code != "invalid_option" {
+		t.Fatalf("expected invalid_option, got %+v", body)
+	}
+
+	w = doJSON(t, r, "/quiz/answer", AnswerQuizRequest{SessionID: start.SessionID, QuestionID: start.Question.ID, SelectedOption: "a"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected the rejected answer not to use quota, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestReviewsRespectReviewQuota(t *testing.T) {
+	h, r := entitlementsFixture(t, entitlements.Plan{FreeDailyQuestions: 10, FreeDailyReviews: 1})
+	ctx := context.Background()
+
+	for _, topic := range []string{"articles", "conditionals"} {
+		h.Progress.SaveProgress(ctx, 1, TopicProgress{TopicID: topic, Mastery: 70, LastSeen: time.Now().Add(-time.Hour)})
+		h.Progress.SaveReview(ctx, 1, ReviewItem{TopicID: topic, NextReviewAt: time.Now().Add(-time.Hour), Ease: 2.5})
+	}
+
+	// Both reviews are due, but only one fits the quota.
+	start := apitest.Decode[StartQuizResponse](t, doJSON(t, r, "/quiz/start", nil))
+	if start.Question.Purpose != string(PurposeReview) {
+		t.Fatalf("expected a due review first, got %+v", start.Question)
+	}
+	options := map[int64]string{1: "a", 2: "a", 3: "a", 4: "if"}
+	w := doJSON(t, r, "/quiz/answer", AnswerQuizRequest{SessionID: start.SessionID, QuestionID: start.Question.ID, SelectedOption: options[start.Question.ID]})
+	next := apitest.Decode[AnswerQuizResponse](t, w).NextQuestion
+	if next == nil || next.Purpose == string(PurposeReview) {
+		t.Fatalf("expected no review once the quota is spent, got %+v", next)
+	}
+	if w := doJSON(t, r, "/quiz/answer", AnswerQuizRequest{SessionID: start.SessionID, QuestionID: next.ID, SelectedOption: options[next.ID]}); w.Code != http.StatusOK {
+		t.Fatalf("expected the session to go on, got %d %s", w.Code, w.Body.String())
+	}
+
+	// With the quota gone, new quizzes skip reviews rather than refuse.
+	w = doJSON(t, r, "/quiz/start", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected start without reviews, got %d %s", w.Code, w.Body.String())
+	}
+	if got := apitest.Decode[StartQuizResponse](t, w).Question; got.Purpose == string(PurposeReview) {
+		t.Fatalf("expected no review, got %+v", got)
+	}
+}
+
+func TestPaidAccountsAreUnlimited(t *testing.T) {
+	_, r := entitlementsFixture(t, entitlements.Plan{PaidOnlyTopics: []string{"conditionals"}})
+
+	w := doJSONAs(t, r, paidTestUser, "/quiz/start", StartQuizRequest{TopicIDs: []string{"conditionals"}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected paid start, got %d %s", w.Code, w.Body.String())
+	}
+	start := apitest.Decode[StartQuizResponse](t, w)
+
+	w = doJSONAs(t, r, paidTestUser, "/quiz/answer", AnswerQuizRequest{SessionID: start.SessionID, QuestionID: start.Question.ID, SelectedOption: "if"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected paid answer despite a zero free quota, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandlerWithoutEntitlements(t *testing.T) {
+	h, r := entitlementsFixture(t, entitlements.Plan{})
+	h.Entitlements = nil
+
+	start := apitest.Decode[StartQuizResponse](t, doJSON(t, r, "/quiz/start", StartQuizRequest{TopicIDs: []string{"conditionals"}}))
+	w := doJSON(t, r, "/quiz/answer", AnswerQuizRequest{SessionID: start.SessionID, QuestionID: start.Question.ID, SelectedOption: "if"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected a free account unmetered, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestStartQuizPaidOnlyTopics(t *testing.T) {
+	h, r := entitlementsFixture(t, entitlements.Plan{FreeDailyQuestions: 10, PaidOnlyTopics: []string{"conditionals"}})
+
+	w := doJSON(t, r, "/quiz/start", StartQuizRequest{TopicIDs: []string{"articles", "conditionals"}})
+	if w.Code != http.StatusPaymentRequired {
+		t.Fatalf("expected 402, got %d %s", w.Code, w.Body.String())
+	}
+	body := apitest.Decode[errorBody](t, w)
+	details, _ := body.Details.(map[string]any)
+	if body.Code != "upgrade_required" || len(details["topic_ids"].([]any)) != 1 {
+		t.Fatalf("unexpected body %+v", body)
+	}
+
+	// Without a topic filter free learners just do not see locked topics.
+	start := apitest.Decode[StartQuizResponse](t, doJSON(t, r, "/quiz/start", nil))
+	session, _ := h.Sessions.Get(context.Background(), start.SessionID)
+	for _, q := range session.Questions {
+		if q.TopicID == "conditionals" {
+			t.Fatalf("free session includes paid-only question %+v", q)
+		}
+	}
+}
+
+// ---------------- Topic scope ----------------
+
+// staticScope assigns the same topics to every learner.
+type staticScope []string
+
+func (s staticScope) AssignedTopics(ctx context.Context, user users.User) ([]string, bool, error) {
+	return s, len(s) > 0, nil
+}
+
+func TestStartQuizGatesOnDefaultCatalog(t *testing.T) {
+	catalog, err := NewTopicCatalog(DefaultTopics()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	questions := append(DefaultQuestions(),
+		Question{ID: 10, TopicID: "conditionals", Difficulty: 2, Options: []string{"if", "when"}, CorrectAnswer: "if"},
+		Question{ID: 11, TopicID: "present_continuous", Difficulty: 2, Options: []string{"is", "are"}, CorrectAnswer: "is"},
+		Question{ID: 12, TopicID: "present_simple", Difficulty: 2, Options: []string{"go", "goes"}, CorrectAnswer: "goes"},
+	)
+	newHandler := func(scope TopicScope) (*Handler, http.Handler) {
+		h := newTestHandler(questions...)
+		h.Scope = scope
+		h.Catalog = catalog
+		return h, newTestRouter(h)
+	}
+
+	// conditionals needs present_simple and past_simple; past_simple has
+	// no questions, so only present_simple gates it. Assigned, it is not
+	// gated at all.
+	_, r := newHandler(staticScope{"conditionals"})
+	w := doJSON(t, r, "/quiz/start", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected the assigned topic served, got %d %s", w.Code, w.Body.String())
+	}
+	if got := apitest.Decode[StartQuizResponse](t, w).Question.ID; got != 10 {
+		t.Fatalf("expected the conditionals question, got %d", got)
+	}
+
+	// Asked for, a gated topic is served too.
+	h, r := newHandler(nil)
+	w = doJSON(t, r, "/quiz/start", StartQuizRequest{TopicIDs: []string{"present_continuous"}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected the requested topic served, got %d %s", w.Code, w.Body.String())
+	}
+
+	// Otherwise the gate holds: a new learner is never served
+	// conditionals or present_continuous before present_simple.
+	w = doJSON(t, r, "/quiz/start", nil)
+	session, _ := h.Sessions.Get(context.Background(), apitest.Decode[StartQuizResponse](t, w).SessionID)
+	if locked := session.Curriculum.Prerequisites["conditionals"]; !slices.Equal(locked, []string{"present_simple"}) {
+		t.Fatalf("expected only present_simple to gate conditionals, got %v", locked)
+	}
+	for id := range session.ServedQuestions {
+		if id == 10 || id == 11 {
+			t.Fatalf("expected gated topics to stay locked, got question %d", id)
+		}
+	}
+}
+
+func TestStartQuizRestrictedToAssignedTopics(t *testing.T) {
+	h, r := entitlementsFixture(t, entitlements.DefaultPlan())
+	h.Scope = staticScope{"conditionals"}
+
+	start := apitest.Decode[StartQuizResponse](t, doJSON(t, r, "/quiz/start", nil))
+	session, _ := h.Sessions.Get(context.Background(), start.SessionID)
+
+	if len(session.Questions) != 1 || session.Questions[0].TopicID != "conditionals" {
+		t.Fatalf("expected only assigned questions, got %+v", session.Questions)
+	}
+	if _, ok := session.Progress["articles"]; ok {
+		t.Fatal("unassigned topics must not be in the session")
+	}
+
+	// A topic filter cannot reach outside the assignment.
+	w := doJSON(t, r, "/quiz/start", StartQuizRequest{TopicIDs: []string{"articles"}})
+	if body := apitest.Decode[errorBody](t, w); body.Code != "no_questions_available" {
+		t.Fatalf("expected no_questions_available, got %d %+v", w.Code, body)
+	}
+
+	// An assignment with nothing to practise is reported as such.
+	h.Scope = staticScope{"tenses"}
+	w = doJSON(t, r, "/quiz/start", nil)
+	if body := apitest.Decode[errorBody](t, w); w.Code != http.StatusNotFound || body.Code != "no_assigned_questions" {
+		t.Fatalf("expected no_assigned_questions, got %d %+v", w.Code, body)
+	}
+}
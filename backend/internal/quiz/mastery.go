@@ -5,6 +5,7 @@ import "time"
 //
 // -------- Constants (tunable pedagogy knobs) --------
 //
+// These are the defaults; PedagogyConfig overrides them at runtime.
 
 const (
 	BaseCorrectDelta = 5.0
@@ -36,6 +37,7 @@ const (
 
 // MasteryUpdateInput describes a single answered question.
 type MasteryUpdateInput struct {
+	QuestionID  int64     // for estimators that rate questions
 	WasCorrect  bool
 	Difficulty  int       // 1, 2, 3
 	AnsweredAt  time.Time
@@ -53,26 +55,51 @@ type MasteryUpdateResult struct {
 	WrongStreak   int
 	IsMastered    bool
 	LastSeen      time.Time
+
+	// Event is the mastery transition this update caused, if any. One
+	// update crosses the band at most once.
+	Event MasteryEvent
+
+	// Rating is the question rating change proposed by an estimator
+	// that rates questions; see QuestionRater.
+	Rating RatingUpdate
 }
 
+// MasteryEvent is a change of IsMastered.
+type MasteryEvent string
+
+const (
+	EventBecameMastered MasteryEvent = "became_mastered"
+	EventLostMastery    MasteryEvent = "lost_mastery"
+)
+
 //
 // -------- Public API --------
 //
 
 // UpdateMastery applies correctness, difficulty, streaks, and decay
-// to produce a new mastery state.
+// to produce a new mastery state, with the compiled-in defaults.
 func UpdateMastery(
 	current TopicProgress,
 	input MasteryUpdateInput,
 ) MasteryUpdateResult {
 
-	mastery := applyDecay(current, input.CurrentTime)
+	return DefaultMasteryConfig().Update(current, input)
+}
+
+// Update is UpdateMastery with c's parameters.
+func (c MasteryConfig) Update(
+	current TopicProgress,
+	input MasteryUpdateInput,
+) MasteryUpdateResult {
+
+	mastery := c.applyDecay(current, input.CurrentTime)
 
 	// Base delta
-	delta := baseDelta(input.WasCorrect)
+	delta := c.baseDelta(input.WasCorrect)
 
 	// Difficulty scaling
-	delta *= difficultyMultiplier(input.Difficulty)
+	delta *= c.difficultyMultiplier(input.Difficulty)
 
 	// Update streaks
 	correctStreak, wrongStreak := updateStreaks(
@@ -82,46 +109,70 @@ func UpdateMastery(
 	)
 
 	// Apply streak effects
-	delta += streakBonus(correctStreak)
-	delta -= streakPenalty(wrongStreak)
+	delta += c.streakBonus(correctStreak)
+	delta -= c.streakPenalty(wrongStreak)
 
 	// Apply delta
 	mastery = clamp(mastery+delta, 0, 100)
 
-	// Mastery flags with hysteresis
-	isMastered := mastery >= MasteredThreshold
-	if mastery < UnmasteredHysteresis {
+	return c.result(current, input, mastery, correctStreak, wrongStreak)
+}
+
+//
+// -------- Internal helpers --------
+//
+
+// result builds the update from the new mastery and streaks, setting
+// the mastery flag with hysteresis: a mastered topic stays mastered
+// until it drops below UnmasteredHysteresis, an unmastered one becomes
+// mastered only at MasteredThreshold.
+func (c MasteryConfig) result(
+	current TopicProgress,
+	input MasteryUpdateInput,
+	mastery float64,
+	correctStreak, wrongStreak int,
+) MasteryUpdateResult {
+
+	isMastered := current.IsMastered
+	if mastery >= c.MasteredThreshold {
+		isMastered = true
+	} else if mastery < c.UnmasteredHysteresis {
 		isMastered = false
 	}
 
+	var event MasteryEvent
+	switch {
+	case isMastered && !current.IsMastered:
+		event = EventBecameMastered
+	case !isMastered && current.IsMastered:
+		event = EventLostMastery
+	}
+
 	return MasteryUpdateResult{
 		Mastery:       mastery,
 		CorrectStreak: correctStreak,
 		WrongStreak:   wrongStreak,
 		IsMastered:    isMastered,
 		LastSeen:      input.AnsweredAt,
+		Event:         event,
 	}
 }
 
-//
-// -------- Internal helpers --------
-//
-
-func baseDelta(correct bool) float64 {
+func (c MasteryConfig) baseDelta(correct bool) float64 {
 	if correct {
-		return BaseCorrectDelta
+		return c.BaseCorrectDelta
 	}
-	return BaseWrongDelta
+	return c.BaseWrongDelta
 }
 
-func difficultyMultiplier(difficulty int) float64 {
+func (c MasteryConfig) difficultyMultiplier(difficulty int) float64 {
 	switch difficulty {
 	case 1:
-		return EasyMultiplier
+		return c.EasyMultiplier
 	case 3:
-		return HardMultiplier
+		return c.HardMultiplier
 	default:
-		return MediumMultiplier
+		return c.MediumMultiplier
 	}
 }
 
@@ -132,44 +183,22 @@ func updateStreaks(correctStreak, wrongStreak int, correct bool) (int, int) {
 	return 0, wrongStreak + 1
 }
 
-func streakBonus(correctStreak int) float64 {
-	if correctStreak > 0 && correctStreak%CorrectStreakBonusEvery == 0 {
-		return CorrectStreakBonus
+func (c MasteryConfig) streakBonus(correctStreak int) float64 {
+	if correctStreak > 0 && correctStreak%c.CorrectStreakBonusEvery == 0 {
+		return c.CorrectStreakBonus
 	}
 	return 0
 }
 
-func streakPenalty(wrongStreak int) float64 {
-	if wrongStreak > 0 && wrongStreak%WrongStreakPenaltyEvery == 0 {
-		return WrongStreakPenalty
+func (c MasteryConfig) streakPenalty(wrongStreak int) float64 {
+	if wrongStreak > 0 && wrongStreak%c.WrongStreakPenaltyEvery == 0 {
+		return c.WrongStreakPenalty
 	}
 	return 0
 }
 
-func applyDecay(current TopicProgress, now time.Time) float64 {
-	// If topic has never been seen, do not apply decay
-	if current.LastSeen.IsZero() {
-		return current.Mastery
-	}
-
-	days := int(now.Sub(current.LastSeen).Hours() / 24)
-	mastery := current.Mastery
-
-	if days <= DecayAfterDays {
-		return mastery
-	}
-
-	if days <= DecayHeavyThreshold {
-		mastery -= float64(days-DecayAfterDays) * DecayMediumRate
-	} else {
-		mastery -= float64(DecayHeavyThreshold-DecayAfterDays)*DecayMediumRate +
-			float64(days-DecayHeavyThreshold)*DecayHeavyRate
-	}
-
-	if mastery < MinimumPassiveMastery {
-		return MinimumPassiveMastery
-	}
-	return mastery
+func (c MasteryConfig) applyDecay(current TopicProgress, now time.Time) float64 {
+	return c.ProjectedMastery(current, now)
 }
 
 
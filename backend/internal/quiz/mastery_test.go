@@ -1,6 +1,7 @@
 package quiz
 
 import (
+	"math"
 	"testing"
 	"time"
 )
@@ -119,3 +120,81 @@ func TestUpdateMastery_MasteredFlag(t *testing.T) {
 		t.Fatalf("expected mastery clamped to 100, got %v", result.Mastery)
 	}
 }
+
+func TestUpdateMastery_Hysteresis(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		mastery     float64
+		wasMastered bool
+		lastSeen    time.Time
+		correct     bool
+		wantMastery float64
+		wantFlag    bool
+		wantEvent   MasteryEvent
+	}{
+		// Unmastered: only MasteredThreshold flips the flag.
+		{"reaches threshold", 95, false, now, true, 100, true, EventBecameMastered},
+		{"just below threshold", 94, false, now, true, 99, false, ""},
+		{"inside band, not yet mastered", 92, false, now, true, 97, false, ""},
+		{"below band", 80, false, now, false, 73, false, ""},
+
+		// Mastered: only dropping below UnmasteredHysteresis clears it.
+		{"stays at threshold", 100, true, now, true, 100, true, ""},
+		{"wrong answer inside band", 100, true, now, false, 93, true, ""},
+		{"lands exactly on hysteresis", 97, true, now, false, 90, true, ""},
+		{"drops below hysteresis", 96.5, true, now, false, 89.5, false, EventLostMastery},
+
+		// Decay counts before the answer does.
+		{"decay pushes below band", 100, true, now.AddDate(0, 0, -40), false, 79.1, false, EventLostMastery},
+		{"decay inside band", 100, true, now.AddDate(0, 0, -10), false, 92.1, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UpdateMastery(
+				TopicProgress{TopicID: "articles", Mastery: tt.mastery, IsMastered: tt.wasMastered, LastSeen: tt.lastSeen},
+				MasteryUpdateInput{WasCorrect: tt.correct, Difficulty: 2, AnsweredAt: now, CurrentTime: now},
+			)
+
+			if math.Abs(got.Mastery-tt.wantMastery) > 1e-9 {
+				t.Fatalf("expected mastery %v, got %v", tt.wantMastery, got.Mastery)
+			}
+			if got.IsMastered != tt.wantFlag || got.Event != tt.wantEvent {
+				t.Fatalf("expected mastered=%v event=%q, got mastered=%v event=%q",
+					tt.wantFlag, tt.wantEvent, got.IsMastered, got.Event)
+			}
+		})
+	}
+}
+
+func TestUpdateMastery_RegainNeedsThreshold(t *testing.T) {
+	now := time.Now()
+	p := TopicProgress{TopicID: "articles", Mastery: 100, IsMastered: true}
+
+	apply := func(correct bool) MasteryUpdateResult {
+		r := UpdateMastery(p, MasteryUpdateInput{WasCorrect: correct, Difficulty: 2, AnsweredAt: now, CurrentTime: now})
+		p = TopicProgress{
+			TopicID:       p.TopicID,
+			Mastery:       r.Mastery,
+			CorrectStreak: r.CorrectStreak,
+			WrongStreak:   r.WrongStreak,
+			IsMastered:    r.IsMastered,
+			LastSeen:      r.LastSeen,
+		}
+		return r
+	}
+
+	var events []MasteryEvent
+	for _, correct := range []bool{false, false, true, true, true} {
+		if r := apply(correct); r.Event != "" {
+			events = append(events, r.Event)
+		}
+	}
+
+	// 100 -> 93 -> 83 (lost) -> 88 -> 93 -> 100 (streak bonus, regained)
+	if len(events) != 2 || events[0] != EventLostMastery || events[1] != EventBecameMastered {
+		t.Fatalf("expected lost then regained, got %v (mastery %v)", events, p.Mastery)
+	}
+}
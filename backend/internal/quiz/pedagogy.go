@@ -0,0 +1,293 @@
+package quiz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+//
+// -------- Config --------
+//
+
+// MasteryConfig holds the knobs of MasteryConfig.Update. Its defaults
+// are the constants in mastery.go.
+type MasteryConfig struct {
+	BaseCorrectDelta float64 `json:"base_correct_delta" yaml:"base_correct_delta"`
+	BaseWrongDelta   float64 `json:"base_wrong_delta" yaml:"base_wrong_delta"`
+
+	EasyMultiplier   float64 `json:"easy_multiplier" yaml:"easy_multiplier"`
+	MediumMultiplier float64 `json:"medium_multiplier" yaml:"medium_multiplier"`
+	HardMultiplier   float64 `json:"hard_multiplier" yaml:"hard_multiplier"`
+
+	CorrectStreakBonusEvery int     `json:"correct_streak_bonus_every" yaml:"correct_streak_bonus_every"`
+	CorrectStreakBonus      float64 `json:"correct_streak_bonus" yaml:"correct_streak_bonus"`
+
+	WrongStreakPenaltyEvery int     `json:"wrong_streak_penalty_every" yaml:"wrong_streak_penalty_every"`
+	WrongStreakPenalty      float64 `json:"wrong_streak_penalty" yaml:"wrong_streak_penalty"`
+
+	DecayAfterDays      int     `json:"decay_after_days" yaml:"decay_after_days"`
+	DecayMediumRate     float64 `json:"decay_medium_rate" yaml:"decay_medium_rate"`
+	DecayHeavyRate      float64 `json:"decay_heavy_rate" yaml:"decay_heavy_rate"`
+	DecayHeavyThreshold int     `json:"decay_heavy_threshold" yaml:"decay_heavy_threshold"`
+
+	// DecayModel is "piecewise", which uses the rates above, or
+	// "exponential", which uses DecayHalfLifeDays.
+	DecayModel        string  `json:"decay_model" yaml:"decay_model"`
+	DecayHalfLifeDays float64 `json:"decay_half_life_days" yaml:"decay_half_life_days"`
+
+	MasteredThreshold     float64 `json:"mastered_threshold" yaml:"mastered_threshold"`
+	UnmasteredHysteresis  float64 `json:"unmastered_hysteresis" yaml:"unmastered_hysteresis"`
+	MinimumPassiveMastery float64 `json:"minimum_passive_mastery" yaml:"minimum_passive_mastery"`
+}
+
+// CurriculumConfig holds the knobs of Curriculum gating.
+type CurriculumConfig struct {
+	UnlockMastery float64 `json:"unlock_mastery" yaml:"unlock_mastery"`
+}
+
+// EstimatorConfig holds the mastery flag thresholds of BKTEstimator
+// and EloEstimator, which replace MasteryConfig's.
+type EstimatorConfig struct {
+	MasteredThreshold    float64 `json:"mastered_threshold" yaml:"mastered_threshold"`
+	UnmasteredHysteresis float64 `json:"unmastered_hysteresis" yaml:"unmastered_hysteresis"`
+}
+
+// PedagogyConfig is every tunable of mastery, question selection,
+// curriculum gating and the probabilistic estimators.
+type PedagogyConfig struct {
+	Mastery    MasteryConfig    `json:"mastery" yaml:"mastery"`
+	Selection  Tiers            `json:"selection" yaml:"selection"`
+	Curriculum CurriculumConfig `json:"curriculum" yaml:"curriculum"`
+	Estimator  EstimatorConfig  `json:"estimator" yaml:"estimator"`
+}
+
+func DefaultMasteryConfig() MasteryConfig {
+	return MasteryConfig{
+		BaseCorrectDelta:        BaseCorrectDelta,
+		BaseWrongDelta:          BaseWrongDelta,
+		EasyMultiplier:          EasyMultiplier,
+		MediumMultiplier:        MediumMultiplier,
+		HardMultiplier:          HardMultiplier,
+		CorrectStreakBonusEvery: CorrectStreakBonusEvery,
+		CorrectStreakBonus:      CorrectStreakBonus,
+		WrongStreakPenaltyEvery: WrongStreakPenaltyEvery,
+		WrongStreakPenalty:      WrongStreakPenalty,
+		DecayAfterDays:          DecayAfterDays,
+		DecayMediumRate:         DecayMediumRate,
+		DecayHeavyRate:          DecayHeavyRate,
+		DecayHeavyThreshold:     DecayHeavyThreshold,
+		DecayModel:              DecayModelPiecewise,
+		DecayHalfLifeDays:       DecayHalfLifeDays,
+		MasteredThreshold:       MasteredThreshold,
+		UnmasteredHysteresis:    UnmasteredHysteresis,
+		MinimumPassiveMastery:   MinimumPassiveMastery,
+	}
+}
+
+func DefaultPedagogy() PedagogyConfig {
+	return PedagogyConfig{
+		Mastery:    DefaultMasteryConfig(),
+		Selection:  Tiers{}.withDefaults(),
+		Curriculum: CurriculumConfig{UnlockMastery: DefaultUnlockMastery},
+		Estimator: EstimatorConfig{
+			MasteredThreshold:    EstimatorMasteredThreshold,
+			UnmasteredHysteresis: EstimatorUnmasteredHysteresis,
+		},
+	}
+}
+
+// Validate reports every problem with c as one ErrInvalidPedagogy.
+func (c PedagogyConfig) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	m := c.Mastery
+	check(m.BaseCorrectDelta > 0, "mastery.base_correct_delta must be positive")
+	check(m.BaseWrongDelta < 0, "mastery.base_wrong_delta must be negative")
+	check(m.EasyMultiplier > 0 && m.MediumMultiplier > 0 && m.HardMultiplier > 0,
+		"mastery difficulty multipliers must be positive")
+	check(m.CorrectStreakBonusEvery >= 1, "mastery.correct_streak_bonus_every must be at least 1")
+	check(m.WrongStreakPenaltyEvery >= 1, "mastery.wrong_streak_penalty_every must be at least 1")
+	check(m.CorrectStreakBonus >= 0, "mastery.correct_streak_bonus must not be negative")
+	check(m.WrongStreakPenalty >= 0, "mastery.wrong_streak_penalty must not be negative")
+	check(m.DecayAfterDays >= 0, "mastery.decay_after_days must not be negative")
+	check(m.DecayMediumRate >= 0 && m.DecayHeavyRate >= 0, "mastery decay rates must not be negative")
+	check(m.DecayHeavyThreshold >= m.DecayAfterDays,
+		"mastery.decay_heavy_threshold must not be below decay_after_days")
+	check(m.DecayModel == DecayModelPiecewise || m.DecayModel == DecayModelExponential,
+		"mastery.decay_model must be %s or %s", DecayModelPiecewise, DecayModelExponential)
+	check(m.DecayHalfLifeDays > 0, "mastery.decay_half_life_days must be positive")
+	check(m.MasteredThreshold > 0 && m.MasteredThreshold <= 100, "mastery.mastered_threshold must be in (0, 100]")
+	check(m.UnmasteredHysteresis >= 0 && m.UnmasteredHysteresis < m.MasteredThreshold,
+		"mastery.unmastered_hysteresis must be below mastered_threshold")
+	check(m.MinimumPassiveMastery >= 0 && m.MinimumPassiveMastery < m.MasteredThreshold,
+		"mastery.minimum_passive_mastery must be below mastered_threshold")
+
+	s := c.Selection
+	check(s.ReinforceBelow > 0 && s.ReinforceBelow < s.StretchFrom && s.StretchFrom <= 100,
+		"selection thresholds must satisfy 0 < reinforce_below < stretch_from <= 100")
+	purposes := []QuestionPurpose{PurposeReview, PurposeReinforce, PurposeProgress, PurposeStretch}
+	for _, purpose := range slices.Sorted(maps.Keys(s.Difficulties)) {
+		check(slices.Contains(purposes, purpose), "selection.difficulties.%s is not a purpose", purpose)
+	}
+	for _, purpose := range purposes {
+		difficulties := s.Difficulties[purpose]
+		check(len(difficulties) > 0, "selection.difficulties.%s is missing", purpose)
+		for i, d := range difficulties {
+			check(d >= 1 && d <= 3, "selection.difficulties.%s: %d is not a difficulty", purpose, d)
+			check(!slices.Contains(difficulties[:i], d), "selection.difficulties.%s: %d is repeated", purpose, d)
+		}
+	}
+
+	check(c.Curriculum.UnlockMastery > 0 && c.Curriculum.UnlockMastery <= 100,
+		"curriculum.unlock_mastery must be in (0, 100]")
+
+	e := c.Estimator
+	check(e.MasteredThreshold > 0 && e.MasteredThreshold <= 100, "estimator.mastered_threshold must be in (0, 100]")
+	check(e.UnmasteredHysteresis >= 0 && e.UnmasteredHysteresis < e.MasteredThreshold,
+		"estimator.unmastered_hysteresis must be below mastered_threshold")
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidPedagogy, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// ParsePedagogy reads a config in YAML, or JSON when format is
+// ".json". Fields left out keep their defaults; unknown fields are
+// errors, so typos do not go unnoticed.
+func ParsePedagogy(data []byte, format string) (PedagogyConfig, error) {
+	cfg := DefaultPedagogy()
+
+	// Difficulty lists replace the defaults per purpose rather than
+	// merging element by element.
+	cfg.Selection.Difficulties = nil
+
+	var err error
+	if format == ".json" {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&cfg)
+	} else {
+		err = yaml.UnmarshalWithOptions(data, &cfg, yaml.Strict())
+	}
+	if err != nil {
+		return PedagogyConfig{}, fmt.Errorf("%w: %v", ErrInvalidPedagogy, err)
+	}
+
+	defaults := DefaultDifficulties()
+	if cfg.Selection.Difficulties == nil {
+		cfg.Selection.Difficulties = defaults
+	}
+	for purpose, difficulties := range defaults {
+		if _, ok := cfg.Selection.Difficulties[purpose]; !ok {
+			cfg.Selection.Difficulties[purpose] = difficulties
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return PedagogyConfig{}, err
+	}
+	return cfg, nil
+}
+
+//
+// -------- Hot reload --------
+//
+
+// PedagogyStore holds the current PedagogyConfig and swaps it
+// atomically on reload. Readers take a snapshot with Current and keep
+// using it for the rest of their request.
+type PedagogyStore struct {
+	path    string
+	current atomic.Pointer[PedagogyConfig]
+	modTime time.Time // of the loaded file; only touched by Reload
+}
+
+// OpenPedagogy loads the config at path. An empty path serves the
+// defaults and never reloads.
+func OpenPedagogy(path string) (*PedagogyStore, error) {
+	s := &PedagogyStore{path: path}
+	if path == "" {
+		cfg := DefaultPedagogy()
+		s.current.Store(&cfg)
+		return s, nil
+	}
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Current returns the config in force. Callers must not modify it.
+func (s *PedagogyStore) Current() *PedagogyConfig {
+	return s.current.Load()
+}
+
+// Reload rereads the file if it changed since the last load. An
+// invalid file is reported and the current config kept. Reload must
+// not be called concurrently with itself.
+func (s *PedagogyStore) Reload() (bool, error) {
+	if s.path == "" {
+		return false, nil
+	}
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return false, err
+	}
+	if s.current.Load() != nil && info.ModTime().Equal(s.modTime) {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return false, err
+	}
+	// A broken file is reported once, not on every tick until fixed.
+	s.modTime = info.ModTime()
+
+	cfg, err := ParsePedagogy(data, strings.ToLower(filepath.Ext(s.path)))
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", s.path, err)
+	}
+
+	s.current.Store(&cfg)
+	return true, nil
+}
+
+// ReloadEvery calls s.Reload at each interval until ctx is done.
+func ReloadEvery(ctx context.Context, s *PedagogyStore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := s.Reload()
+			if err != nil {
+				log.Printf("quiz: reload pedagogy: %v", err)
+			} else if reloaded {
+				log.Printf("quiz: reloaded pedagogy from %s", s.path)
+			}
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package quiz
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bugii1995/backend/internal/apitest"
+)
+
+func TestDefaultPedagogyIsValid(t *testing.T) {
+	if err := DefaultPedagogy().Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	current := TopicProgress{TopicID: "articles", Mastery: 55, CorrectStreak: 2}
+	input := MasteryUpdateInput{WasCorrect: true, Difficulty: 3, AnsweredAt: time.Now(), CurrentTime: time.Now()}
+	if got, want := DefaultMasteryConfig().Update(current, input), UpdateMastery(current, input); got != want {
+		t.Fatalf("expected the defaults to match UpdateMastery: %+v vs %+v", got, want)
+	}
+}
+
+func TestParsePedagogy(t *testing.T) {
+	yamlConfig := `
+mastery:
+  base_correct_delta: 8
+  decay_heavy_rate: 1.5
+selection:
+  reinforce_below: 30
+  difficulties:
+    reinforce: [1]
+`
+	jsonConfig := `{
+  "mastery": {"base_correct_delta": 8, "decay_heavy_rate": 1.5},
+  "selection": {"reinforce_below": 30, "difficulties": {"reinforce": [1]}}
+}`
+
+	for format, data := range map[string]string{".yaml": yamlConfig, ".json": jsonConfig} {
+		t.Run(format, func(t *testing.T) {
+			cfg, err := ParsePedagogy([]byte(data), format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if cfg.Mastery.BaseCorrectDelta != 8 || cfg.Mastery.DecayHeavyRate != 1.5 || cfg.Selection.ReinforceBelow != 30 {
+				t.Fatalf("expected the overrides, got %+v", cfg)
+			}
+			// Everything else keeps its default.
+			if cfg.Mastery.BaseWrongDelta != BaseWrongDelta || cfg.Selection.StretchFrom != DefaultStretchFrom {
+				t.Fatalf("expected defaults for the rest, got %+v", cfg)
+			}
+			if got := cfg.Selection.Difficulties[PurposeReinforce]; !slices.Equal(got, []int{1}) {
+				t.Fatalf("expected the reinforce list replaced, got %v", got)
+			}
+			if got := cfg.Selection.Difficulties[PurposeStretch]; !slices.Equal(got, DefaultDifficulties()[PurposeStretch]) {
+				t.Fatalf("expected the default stretch list, got %v", got)
+			}
+		})
+	}
+}
+
+func TestParsePedagogyRejectsBadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		problem string
+	}{
+		{"unknown field", "mastery:\n  base_corect_delta: 8\n", "base_corect_delta"},
+		{"hysteresis above threshold", "mastery:\n  unmastered_hysteresis: 100\n", "unmastered_hysteresis"},
+		{"negative rate", "mastery:\n  decay_medium_rate: -0.1\n", "decay rates"},
+		{"wrong delta positive", "mastery:\n  base_wrong_delta: 2\n", "base_wrong_delta"},
+		{"unknown decay model", "mastery:\n  decay_model: linear\n", "decay_model"},
+		{"zero half-life", "mastery:\n  decay_half_life_days: 0\n", "decay_half_life_days"},
+		{"zero streak period", "mastery:\n  correct_streak_bonus_every: 0\n", "correct_streak_bonus_every"},
+		{"thresholds crossed", "selection:\n  reinforce_below: 85\n", "reinforce_below"},
+		{"bad difficulty", "selection:\n  difficulties:\n    stretch: [3, 4]\n", "4 is not a difficulty"},
+		{"repeated difficulty", "selection:\n  difficulties:\n    review: [2, 2]\n", "2 is repeated"},
+		{"empty difficulties", "selection:\n  difficulties:\n    review: []\n", "review is missing"},
+		{"unknown purpose", "selection:\n  difficulties:\n    reinforse: [1, 2]\n", "reinforse is not a purpose"},
+		{"unlock above 100", "curriculum:\n  unlock_mastery: 120\n", "unlock_mastery"},
+		{"estimator hysteresis above threshold", "estimator:\n  unmastered_hysteresis: 96\n", "estimator.unmastered_hysteresis"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePedagogy([]byte(tt.config), ".yaml")
+			if !errors.Is(err, ErrInvalidPedagogy) || !strings.Contains(err.Error(), tt.problem) {
+				t.Fatalf("expected ErrInvalidPedagogy about %q, got %v", tt.problem, err)
+			}
+		})
+	}
+}
+
+func TestMasteryConfigChangesUpdate(t *testing.T) {
+	cfg := DefaultMasteryConfig()
+	cfg.BaseCorrectDelta = 10
+	cfg.MasteredThreshold = 60
+	cfg.UnmasteredHysteresis = 50
+
+	got := cfg.Update(
+		TopicProgress{TopicID: "articles", Mastery: 55},
+		MasteryUpdateInput{WasCorrect: true, Difficulty: 2, AnsweredAt: time.Now(), CurrentTime: time.Now()},
+	)
+	if got.Mastery != 65 || !got.IsMastered {
+		t.Fatalf("expected 65 and mastered, got %+v", got)
+	}
+}
+
+func TestSessionUsesPedagogy(t *testing.T) {
+	cfg := DefaultPedagogy()
+	cfg.Mastery.BaseCorrectDelta = 10
+	cfg.Selection.ReinforceBelow = 60
+
+	session := NewSession(
+		[]Question{
+			{ID: 1, TopicID: "articles", Difficulty: 2},
+			{ID: 2, TopicID: "articles", Difficulty: 1},
+		},
+		[]TopicProgress{{TopicID: "articles", Mastery: 50}},
+		nil,
+	)
+	session.Pedagogy = &cfg
+
+	first := session.NextQuestion(time.Now())
+	if first == nil || first.Purpose != PurposeReinforce || first.QuestionID != 2 {
+		t.Fatalf("expected 50 to count as weak, got %+v", first)
+	}
+
+	_, update := session.SubmitAnswer(Answer{QuestionID: 2, TopicID: "articles", WasCorrect: true, Difficulty: 1}, time.Now())
+	if update.Mastery != 56 {
+		t.Fatalf("expected 50 + 10 * 0.6, got %v", update.Mastery)
+	}
+}
+
+// writeConfig writes data to path with the given modification time, so
+// reloads do not depend on the file system's timestamp resolution.
+func writeConfig(t *testing.T, path, data string, modTime time.Time) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPedagogyStoreReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pedagogy.yaml")
+	base := time.Now().Add(-time.Hour)
+	writeConfig(t, path, "mastery:\n  base_correct_delta: 6\n", base)
+
+	store, err := OpenPedagogy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := store.Current()
+	if before.Mastery.BaseCorrectDelta != 6 {
+		t.Fatalf("expected the file's config, got %+v", before.Mastery)
+	}
+
+	if reloaded, err := store.Reload(); reloaded || err != nil {
+		t.Fatalf("expected an unchanged file to be skipped, got %v %v", reloaded, err)
+	}
+
+	writeConfig(t, path, "mastery:\n  base_correct_delta: 9\n", base.Add(time.Minute))
+	if reloaded, err := store.Reload(); !reloaded || err != nil {
+		t.Fatalf("expected a reload, got %v %v", reloaded, err)
+	}
+	if got := store.Current().Mastery.BaseCorrectDelta; got != 9 {
+		t.Fatalf("expected the new config, got %v", got)
+	}
+	// Snapshots taken earlier are not changed under their holders.
+	if before.Mastery.BaseCorrectDelta != 6 {
+		t.Fatal("expected the old snapshot to be kept intact")
+	}
+
+	writeConfig(t, path, "mastery:\n  unmastered_hysteresis: 120\n", base.Add(2*time.Minute))
+	if _, err := store.Reload(); !errors.Is(err, ErrInvalidPedagogy) {
+		t.Fatalf("expected the broken file to be reported, got %v", err)
+	}
+	if got := store.Current().Mastery.BaseCorrectDelta; got != 9 {
+		t.Fatalf("expected the last good config to stay, got %v", got)
+	}
+	if _, err := store.Reload(); err != nil {
+		t.Fatalf("expected the broken file to be reported once, got %v", err)
+	}
+}
+
+func TestOpenPedagogyRejectsBrokenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pedagogy.json")
+	writeConfig(t, path, `{"selection": {"stretch_from": 20}}`, time.Now())
+
+	if _, err := OpenPedagogy(path); !errors.Is(err, ErrInvalidPedagogy) {
+		t.Fatalf("expected ErrInvalidPedagogy, got %v", err)
+	}
+
+	store, err := OpenPedagogy("")
+	if err != nil || store.Current().Mastery != DefaultMasteryConfig() {
+		t.Fatalf("expected the defaults without a file, got %v", err)
+	}
+}
+
+func TestAdminGetConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pedagogy.yaml")
+	writeConfig(t, path, "mastery:\n  base_correct_delta: 7\n", time.Now())
+	store, err := OpenPedagogy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := newAdminRouter(&AdminHandler{Questions: NewMemoryQuestionRepository(), Topics: NewTopicSet(), Pedagogy: store})
+	w := doAdmin(t, r, http.MethodGet, "/admin/config", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", w.Code, w.Body.String())
+	}
+
+	got := apitest.Decode[PedagogyConfig](t, w)
+	if got.Mastery.BaseCorrectDelta != 7 || got.Selection.StretchFrom != DefaultStretchFrom ||
+		!slices.Equal(got.Selection.Difficulties[PurposeReinforce], []int{1, 2, 3}) {
+		t.Fatalf("unexpected config %+v", got)
+	}
+}
@@ -0,0 +1,622 @@
+package quiz
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bugii1995/backend/internal/storage"
+)
+
+// InitialMastery is where a learner starts on a topic they have never
+// answered (the old hard-coded StartQuiz value).
+const InitialMastery = 40.0
+
+// AnswerRecord is one graded answer and the mastery it produced.
+type AnswerRecord struct {
+	TopicID    string
+	QuestionID int64
+	WasCorrect bool
+	Mastery    MasteryUpdateResult
+	AnsweredAt time.Time
+
+	// Variant is the experiment variant that served the question.
+	Variant string
+
+	// SessionID is the session the answer was given in; empty for
+	// answers recorded before sessions were tracked.
+	SessionID string
+}
+
+// ProgressRepository keeps each learner's TopicProgress and ReviewItems
+// across sessions, keyed by user and topic, plus their answer history
+// and every question's answer counts.
+//
+// ListProgress and ListReviews return entries ordered by topic ID,
+// ListAnswers in the order they were recorded.
+type ProgressRepository interface {
+	ListProgress(ctx context.Context, userID uint64) ([]TopicProgress, error)
+	SaveProgress(ctx context.Context, userID uint64, p TopicProgress) error
+
+	ListReviews(ctx context.Context, userID uint64) ([]ReviewItem, error)
+	SaveReview(ctx context.Context, userID uint64, r ReviewItem) error
+
+	RecordAnswer(ctx context.Context, userID uint64, a AnswerRecord) error
+	ListAnswers(ctx context.Context, userID uint64) ([]AnswerRecord, error)
+
+	// LastAnswered returns when the user last answered each question,
+	// without loading their whole history.
+	LastAnswered(ctx context.Context, userID uint64) (map[int64]time.Time, error)
+
+	// SaveAnswer saves an answer's progress, review and history record
+	// together, or none of them. An answer is identified by its
+	// SessionID and QuestionID: saving one already recorded fails with
+	// ErrAlreadyAnswered, and FindAnswer returns it, or
+	// ErrAnswerNotFound.
+	SaveAnswer(ctx context.Context, userID uint64, a AnswerRecord, p TopicProgress, r ReviewItem) error
+	FindAnswer(ctx context.Context, userID uint64, sessionID string, questionID int64) (AnswerRecord, error)
+
+	// Every recorded answer also counts toward its question's
+	// QuestionStats, in the same write. ListStats returns them ordered
+	// by question ID; GetStats returns zero counts for a question never
+	// answered. RecountStats rebuilds them from the answer history, in
+	// case they drifted from it.
+	GetStats(ctx context.Context, questionID int64) (QuestionStats, error)
+	ListStats(ctx context.Context) ([]QuestionStats, error)
+	RecountStats(ctx context.Context) error
+}
+
+// ---------------- In-memory ----------------
+
+type progressKey struct {
+	userID  uint64
+	topicID string
+}
+
+type MemoryProgressRepository struct {
+	mu       sync.RWMutex
+	progress map[progressKey]TopicProgress
+	reviews  map[progressKey]ReviewItem
+	answers  map[uint64][]AnswerRecord
+	stats    map[int64]QuestionStats
+}
+
+func NewMemoryProgressRepository() *MemoryProgressRepository {
+	return &MemoryProgressRepository{
+		progress: make(map[progressKey]TopicProgress),
+		reviews:  make(map[progressKey]ReviewItem),
+		answers:  make(map[uint64][]AnswerRecord),
+		stats:    make(map[int64]QuestionStats),
+	}
+}
+
+func (r *MemoryProgressRepository) ListProgress(ctx context.Context, userID uint64) ([]TopicProgress, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]TopicProgress, 0)
+	for key, p := range r.progress {
+		if key.userID == userID {
+			out = append(out, p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].TopicID < out[j].TopicID })
+	return out, nil
+}
+
+func (r *MemoryProgressRepository) SaveProgress(ctx context.Context, userID uint64, p TopicProgress) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.progress[progressKey{userID, p.TopicID}] = p
+	return nil
+}
+
+func (r *MemoryProgressRepository) ListReviews(ctx context.Context, userID uint64) ([]ReviewItem, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]ReviewItem, 0)
+	for key, item := range r.reviews {
+		if key.userID == userID {
+			out = append(out, item)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].TopicID < out[j].TopicID })
+	return out, nil
+}
+
+func (r *MemoryProgressRepository) SaveReview(ctx context.Context, userID uint64, item ReviewItem) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.reviews[progressKey{userID, item.TopicID}] = item
+	return nil
+}
+
+func (r *MemoryProgressRepository) RecordAnswer(ctx context.Context, userID uint64, a AnswerRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.findAnswer(userID, a.SessionID, a.QuestionID); ok {
+		return ErrAlreadyAnswered
+	}
+	r.answers[userID] = append(r.answers[userID], a)
+	countAnswer(r.stats, a)
+	return nil
+}
+
+func (r *MemoryProgressRepository) ListAnswers(ctx context.Context, userID uint64) ([]AnswerRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append(make([]AnswerRecord, 0, len(r.answers[userID])), r.answers[userID]...), nil
+}
+
+func (r *MemoryProgressRepository) LastAnswered(ctx context.Context, userID uint64) (map[int64]time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	last := make(map[int64]time.Time)
+	for _, a := range r.answers[userID] {
+		if a.AnsweredAt.After(last[a.QuestionID]) {
+			last[a.QuestionID] = a.AnsweredAt
+		}
+	}
+	return last, nil
+}
+
+func (r *MemoryProgressRepository) SaveAnswer(ctx context.Context, userID uint64, a AnswerRecord, p TopicProgress, item ReviewItem) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.findAnswer(userID, a.SessionID, a.QuestionID); ok {
+		return ErrAlreadyAnswered
+	}
+	r.progress[progressKey{userID, p.TopicID}] = p
+	r.reviews[progressKey{userID, item.TopicID}] = item
+	r.answers[userID] = append(r.answers[userID], a)
+	countAnswer(r.stats, a)
+	return nil
+}
+
+func (r *MemoryProgressRepository) FindAnswer(ctx context.Context, userID uint64, sessionID string, questionID int64) (AnswerRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if a, ok := r.findAnswer(userID, sessionID, questionID); ok {
+		return a, nil
+	}
+	return AnswerRecord{}, ErrAnswerNotFound
+}
+
+// findAnswer looks up an answer by session and question. r.mu must be
+// held.
+func (r *MemoryProgressRepository) findAnswer(userID uint64, sessionID string, questionID int64) (AnswerRecord, bool) {
+	if sessionID == "" {
+		return AnswerRecord{}, false
+	}
+	for _, a := range r.answers[userID] {
+		if a.SessionID == sessionID && a.QuestionID == questionID {
+			return a, true
+		}
+	}
+	return AnswerRecord{}, false
+}
+
+func (r *MemoryProgressRepository) GetStats(ctx context.Context, questionID int64) (QuestionStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s := r.stats[questionID]
+	s.QuestionID = questionID
+	return s, nil
+}
+
+func (r *MemoryProgressRepository) ListStats(ctx context.Context) ([]QuestionStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]QuestionStats, 0, len(r.stats))
+	for _, s := range r.stats {
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].QuestionID < out[j].QuestionID })
+	return out, nil
+}
+
+func (r *MemoryProgressRepository) RecountStats(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stats = make(map[int64]QuestionStats)
+	for _, answers := range r.answers {
+		for _, a := range answers {
+			countAnswer(r.stats, a)
+		}
+	}
+	return nil
+}
+
+// countAnswer adds a to its question's stats.
+func countAnswer(stats map[int64]QuestionStats, a AnswerRecord) {
+	s := stats[a.QuestionID]
+	s.QuestionID = a.QuestionID
+	s.Attempts++
+	if a.WasCorrect {
+		s.Correct++
+	}
+	stats[a.QuestionID] = s
+}
+
+// ---------------- SQLite ----------------
+
+var progressMigrations = []string{
+	`CREATE TABLE topic_progress (
+		user_id        INTEGER NOT NULL,
+		topic_id       TEXT    NOT NULL,
+		mastery        REAL    NOT NULL,
+		correct_streak INTEGER NOT NULL,
+		wrong_streak   INTEGER NOT NULL,
+		is_mastered    INTEGER NOT NULL,
+		last_seen      INTEGER NOT NULL, -- unix nanoseconds, 0 = never
+		PRIMARY KEY (user_id, topic_id)
+	);
+	CREATE TABLE review_items (
+		user_id        INTEGER NOT NULL,
+		topic_id       TEXT    NOT NULL,
+		next_review_at INTEGER NOT NULL, -- unix nanoseconds
+		PRIMARY KEY (user_id, topic_id)
+	);`,
+	`ALTER TABLE review_items ADD COLUMN ease REAL NOT NULL DEFAULT 2.5;
+	ALTER TABLE review_items ADD COLUMN interval_days INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE review_items ADD COLUMN repetitions INTEGER NOT NULL DEFAULT 0;`,
+	`CREATE TABLE answer_history (
+		id             INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id        INTEGER NOT NULL,
+		topic_id       TEXT    NOT NULL,
+		question_id    INTEGER NOT NULL,
+		was_correct    INTEGER NOT NULL,
+		mastery        REAL    NOT NULL,
+		correct_streak INTEGER NOT NULL,
+		wrong_streak   INTEGER NOT NULL,
+		is_mastered    INTEGER NOT NULL,
+		answered_at    INTEGER NOT NULL -- unix nanoseconds
+	);
+	CREATE INDEX answer_history_user ON answer_history (user_id, id);`,
+	`ALTER TABLE answer_history ADD COLUMN variant TEXT NOT NULL DEFAULT '';`,
+	`ALTER TABLE answer_history ADD COLUMN session_id TEXT NOT NULL DEFAULT '';
+	CREATE UNIQUE INDEX answer_history_session ON answer_history (session_id, question_id)
+		WHERE session_id != '';`,
+	`CREATE INDEX answer_history_last ON answer_history (user_id, question_id, answered_at);`,
+	`CREATE TABLE question_stats (
+		question_id INTEGER PRIMARY KEY,
+		attempts    INTEGER NOT NULL,
+		correct     INTEGER NOT NULL
+	);
+	INSERT INTO question_stats (question_id, attempts, correct)
+		SELECT question_id, COUNT(*), SUM(was_correct) FROM answer_history GROUP BY question_id;`,
+}
+
+// execer is what the save statements need, so they run alone or
+// inside SaveAnswer's transaction.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+type SQLiteProgressRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteProgressRepository(db *sql.DB) (*SQLiteProgressRepository, error) {
+	if err := storage.Migrate(db, "progress", progressMigrations); err != nil {
+		return nil, err
+	}
+	return &SQLiteProgressRepository{db: db}, nil
+}
+
+func (r *SQLiteProgressRepository) ListProgress(ctx context.Context, userID uint64) ([]TopicProgress, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT topic_id, mastery, correct_streak, wrong_streak, is_mastered, last_seen
+		FROM topic_progress WHERE user_id = ? ORDER BY topic_id`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]TopicProgress, 0)
+	for rows.Next() {
+		var (
+			p        TopicProgress
+			lastSeen int64
+		)
+		if err := rows.Scan(
+			&p.TopicID, &p.Mastery, &p.CorrectStreak, &p.WrongStreak, &p.IsMastered, &lastSeen,
+		); err != nil {
+			return nil, err
+		}
+		p.LastSeen = fromUnixNano(lastSeen)
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func (r *SQLiteProgressRepository) SaveProgress(ctx context.Context, userID uint64, p TopicProgress) error {
+	return saveProgress(ctx, r.db, userID, p)
+}
+
+func saveProgress(ctx context.Context, db execer, userID uint64, p TopicProgress) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO topic_progress
+			(user_id, topic_id, mastery, correct_streak, wrong_streak, is_mastered, last_seen)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, topic_id) DO UPDATE SET
+			mastery        = excluded.mastery,
+			correct_streak = excluded.correct_streak,
+			wrong_streak   = excluded.wrong_streak,
+			is_mastered    = excluded.is_mastered,
+			last_seen      = excluded.last_seen`,
+		userID, p.TopicID, p.Mastery, p.CorrectStreak, p.WrongStreak, p.IsMastered, toUnixNano(p.LastSeen),
+	)
+	return err
+}
+
+func (r *SQLiteProgressRepository) ListReviews(ctx context.Context, userID uint64) ([]ReviewItem, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT topic_id, next_review_at, ease, interval_days, repetitions
+		FROM review_items WHERE user_id = ? ORDER BY topic_id`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]ReviewItem, 0)
+	for rows.Next() {
+		var (
+			item         ReviewItem
+			nextReviewAt int64
+		)
+		if err := rows.Scan(
+			&item.TopicID, &nextReviewAt, &item.Ease, &item.IntervalDays, &item.Repetitions,
+		); err != nil {
+			return nil, err
+		}
+		item.NextReviewAt = fromUnixNano(nextReviewAt)
+		out = append(out, item)
+	}
+	return out, rows.Err()
+}
+
+func (r *SQLiteProgressRepository) SaveReview(ctx context.Context, userID uint64, item ReviewItem) error {
+	return saveReview(ctx, r.db, userID, item)
+}
+
+func saveReview(ctx context.Context, db execer, userID uint64, item ReviewItem) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO review_items
+			(user_id, topic_id, next_review_at, ease, interval_days, repetitions)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, topic_id) DO UPDATE SET
+			next_review_at = excluded.next_review_at,
+			ease           = excluded.ease,
+			interval_days  = excluded.interval_days,
+			repetitions    = excluded.repetitions`,
+		userID, item.TopicID, toUnixNano(item.NextReviewAt), item.Ease, item.IntervalDays, item.Repetitions,
+	)
+	return err
+}
+
+func (r *SQLiteProgressRepository) RecordAnswer(ctx context.Context, userID uint64, a AnswerRecord) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := recordAnswer(ctx, tx, userID, a); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// recordAnswer adds a to the history and to its question's stats.
+// Both statements must run in one transaction.
+func recordAnswer(ctx context.Context, db execer, userID uint64, a AnswerRecord) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO answer_history
+			(user_id, topic_id, question_id, was_correct, mastery, correct_streak, wrong_streak, is_mastered, answered_at, variant, session_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		userID, a.TopicID, a.QuestionID, a.WasCorrect,
+		a.Mastery.Mastery, a.Mastery.CorrectStreak, a.Mastery.WrongStreak, a.Mastery.IsMastered,
+		toUnixNano(a.AnsweredAt), a.Variant, a.SessionID,
+	)
+	if storage.IsUniqueViolation(err) {
+		return ErrAlreadyAnswered
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO question_stats (question_id, attempts, correct)
+		VALUES (?, 1, ?)
+		ON CONFLICT (question_id) DO UPDATE SET
+			attempts = attempts + 1,
+			correct  = correct + excluded.correct`,
+		a.QuestionID, a.WasCorrect,
+	)
+	return err
+}
+
+func (r *SQLiteProgressRepository) ListAnswers(ctx context.Context, userID uint64) ([]AnswerRecord, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+answerColumns+`
+		FROM answer_history WHERE user_id = ? ORDER BY id`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]AnswerRecord, 0)
+	for rows.Next() {
+		a, err := scanAnswer(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (r *SQLiteProgressRepository) LastAnswered(ctx context.Context, userID uint64) (map[int64]time.Time, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT question_id, MAX(answered_at)
+		FROM answer_history WHERE user_id = ? GROUP BY question_id`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	last := make(map[int64]time.Time)
+	for rows.Next() {
+		var (
+			questionID int64
+			answeredAt int64
+		)
+		if err := rows.Scan(&questionID, &answeredAt); err != nil {
+			return nil, err
+		}
+		last[questionID] = fromUnixNano(answeredAt)
+	}
+	return last, rows.Err()
+}
+
+func (r *SQLiteProgressRepository) SaveAnswer(ctx context.Context, userID uint64, a AnswerRecord, p TopicProgress, item ReviewItem) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := recordAnswer(ctx, tx, userID, a); err != nil {
+		return err
+	}
+	if err := saveProgress(ctx, tx, userID, p); err != nil {
+		return err
+	}
+	if err := saveReview(ctx, tx, userID, item); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *SQLiteProgressRepository) FindAnswer(ctx context.Context, userID uint64, sessionID string, questionID int64) (AnswerRecord, error) {
+	if sessionID == "" {
+		return AnswerRecord{}, ErrAnswerNotFound
+	}
+	a, err := scanAnswer(r.db.QueryRowContext(ctx, `
+		SELECT `+answerColumns+`
+		FROM answer_history WHERE user_id = ? AND session_id = ? AND question_id = ?`,
+		userID, sessionID, questionID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return AnswerRecord{}, ErrAnswerNotFound
+	}
+	return a, err
+}
+
+const answerColumns = `topic_id, question_id, was_correct, mastery, correct_streak, wrong_streak, is_mastered, answered_at, variant, session_id`
+
+// scanAnswer reads one row of answerColumns.
+func scanAnswer(row interface{ Scan(dest ...any) error }) (AnswerRecord, error) {
+	var (
+		a          AnswerRecord
+		answeredAt int64
+	)
+	if err := row.Scan(
+		&a.TopicID, &a.QuestionID, &a.WasCorrect,
+		&a.Mastery.Mastery, &a.Mastery.CorrectStreak, &a.Mastery.WrongStreak, &a.Mastery.IsMastered,
+		&answeredAt, &a.Variant, &a.SessionID,
+	); err != nil {
+		return AnswerRecord{}, err
+	}
+	a.AnsweredAt = fromUnixNano(answeredAt)
+	a.Mastery.LastSeen = a.AnsweredAt
+	return a, nil
+}
+
+func (r *SQLiteProgressRepository) GetStats(ctx context.Context, questionID int64) (QuestionStats, error) {
+	s := QuestionStats{QuestionID: questionID}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT attempts, correct FROM question_stats WHERE question_id = ?`, questionID,
+	).Scan(&s.Attempts, &s.Correct)
+	if errors.Is(err, sql.ErrNoRows) {
+		return s, nil
+	}
+	return s, err
+}
+
+func (r *SQLiteProgressRepository) RecountStats(ctx context.Context) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM question_stats`); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO question_stats (question_id, attempts, correct)
+		SELECT question_id, COUNT(*), SUM(was_correct)
+		FROM answer_history GROUP BY question_id`); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *SQLiteProgressRepository) ListStats(ctx context.Context) ([]QuestionStats, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT question_id, attempts, correct FROM question_stats ORDER BY question_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]QuestionStats, 0)
+	for rows.Next() {
+		var s QuestionStats
+		if err := rows.Scan(&s.QuestionID, &s.Attempts, &s.Correct); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// toUnixNano stores the zero time as 0 so "never seen" survives a
+// round trip (time.Time{}.UnixNano() is not representable).
+func toUnixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromUnixNano(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
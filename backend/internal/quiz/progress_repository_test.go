@@ -0,0 +1,283 @@
+package quiz
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bugii1995/backend/internal/storage"
+)
+
+func TestMemoryProgressRepository(t *testing.T) {
+	testProgressRepositoryContract(t, func(t *testing.T) ProgressRepository {
+		return NewMemoryProgressRepository()
+	})
+}
+
+func TestSQLiteProgressRepository(t *testing.T) {
+	testProgressRepositoryContract(t, func(t *testing.T) ProgressRepository {
+		db, err := storage.Open(":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		repo, err := NewSQLiteProgressRepository(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return repo
+	})
+}
+
+func TestSQLiteRecountStatsCorrectsDrift(t *testing.T) {
+	ctx := context.Background()
+	db, err := storage.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo, err := NewSQLiteProgressRepository(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.RecordAnswer(ctx, 1, AnswerRecord{TopicID: "articles", QuestionID: 4, WasCorrect: true, AnsweredAt: time.Now()})
+	if _, err := db.Exec(`UPDATE question_stats SET attempts = 7`); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.RecountStats(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := repo.GetStats(ctx, 4); got != (QuestionStats{QuestionID: 4, Attempts: 1, Correct: 1}) {
+		t.Fatalf("expected the counts rebuilt from the history, got %+v", got)
+	}
+}
+
+// testProgressRepositoryContract is run against every ProgressRepository.
+func testProgressRepositoryContract(t *testing.T, newRepo func(t *testing.T) ProgressRepository) {
+	ctx := context.Background()
+	seen := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	t.Run("EmptyForNewUser", func(t *testing.T) {
+		repo := newRepo(t)
+
+		progress, err := repo.ListProgress(ctx, 1)
+		if err != nil || len(progress) != 0 {
+			t.Fatalf("expected no progress, got %v %v", progress, err)
+		}
+		reviews, err := repo.ListReviews(ctx, 1)
+		if err != nil || len(reviews) != 0 {
+			t.Fatalf("expected no reviews, got %v %v", reviews, err)
+		}
+	})
+
+	t.Run("SaveProgressUpserts", func(t *testing.T) {
+		repo := newRepo(t)
+
+		first := TopicProgress{TopicID: "articles", Mastery: 52.5, CorrectStreak: 2, LastSeen: seen}
+		second := TopicProgress{TopicID: "articles", Mastery: 81, CorrectStreak: 5, IsMastered: true, LastSeen: seen.Add(time.Hour)}
+		other := TopicProgress{TopicID: "conditionals", Mastery: InitialMastery, WrongStreak: 1}
+
+		for _, p := range []TopicProgress{first, other, second} {
+			if err := repo.SaveProgress(ctx, 1, p); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		got, err := repo.ListProgress(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("expected 2 topics, got %+v", got)
+		}
+		if !got[0].LastSeen.Equal(second.LastSeen) {
+			t.Fatalf("expected last seen %v, got %v", second.LastSeen, got[0].LastSeen)
+		}
+		got[0].LastSeen = second.LastSeen
+		if got[0] != second {
+			t.Fatalf("expected %+v, got %+v", second, got[0])
+		}
+		if got[1].TopicID != "conditionals" || !got[1].LastSeen.IsZero() || got[1].WrongStreak != 1 {
+			t.Fatalf("expected never-seen conditionals, got %+v", got[1])
+		}
+	})
+
+	t.Run("SaveReviewUpserts", func(t *testing.T) {
+		repo := newRepo(t)
+
+		repo.SaveReview(ctx, 1, ReviewItem{TopicID: "articles", NextReviewAt: seen})
+		want := ReviewItem{TopicID: "articles", NextReviewAt: seen.Add(72 * time.Hour), Ease: 2.36, IntervalDays: 6, Repetitions: 2}
+		repo.SaveReview(ctx, 1, want)
+
+		got, err := repo.ListReviews(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 || !got[0].NextReviewAt.Equal(want.NextReviewAt) {
+			t.Fatalf("expected one rescheduled review, got %+v", got)
+		}
+		got[0].NextReviewAt = want.NextReviewAt
+		if got[0] != want {
+			t.Fatalf("expected %+v, got %+v", want, got[0])
+		}
+	})
+
+	t.Run("AnswersInRecordedOrder", func(t *testing.T) {
+		repo := newRepo(t)
+
+		want := []AnswerRecord{
+			{TopicID: "articles", QuestionID: 2, WasCorrect: true, AnsweredAt: seen,
+				Mastery: MasteryUpdateResult{Mastery: 45, CorrectStreak: 1, LastSeen: seen}},
+			{TopicID: "articles", QuestionID: 3, WasCorrect: false, AnsweredAt: seen.Add(time.Minute),
+				Mastery: MasteryUpdateResult{Mastery: 35.2, WrongStreak: 1, LastSeen: seen.Add(time.Minute)}},
+			{TopicID: "conditionals", QuestionID: 7, WasCorrect: true, AnsweredAt: seen.Add(2 * time.Minute), Variant: "weighted",
+				Mastery: MasteryUpdateResult{Mastery: 100, CorrectStreak: 9, IsMastered: true, LastSeen: seen.Add(2 * time.Minute)}},
+		}
+		for _, a := range want {
+			if err := repo.RecordAnswer(ctx, 1, a); err != nil {
+				t.Fatal(err)
+			}
+		}
+		repo.RecordAnswer(ctx, 2, want[0])
+
+		got, err := repo.ListAnswers(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("expected %d answers, got %+v", len(want), got)
+		}
+		for i := range want {
+			if !got[i].AnsweredAt.Equal(want[i].AnsweredAt) || !got[i].Mastery.LastSeen.Equal(want[i].Mastery.LastSeen) {
+				t.Fatalf("answer %d: expected time %v, got %+v", i, want[i].AnsweredAt, got[i])
+			}
+			got[i].AnsweredAt, got[i].Mastery.LastSeen = want[i].AnsweredAt, want[i].Mastery.LastSeen
+			if got[i] != want[i] {
+				t.Fatalf("answer %d: expected %+v, got %+v", i, want[i], got[i])
+			}
+		}
+	})
+
+	t.Run("LastAnsweredPerQuestion", func(t *testing.T) {
+		repo := newRepo(t)
+
+		repo.RecordAnswer(ctx, 1, AnswerRecord{TopicID: "articles", QuestionID: 2, AnsweredAt: seen.Add(time.Minute)})
+		repo.RecordAnswer(ctx, 1, AnswerRecord{TopicID: "articles", QuestionID: 2, AnsweredAt: seen})
+		repo.RecordAnswer(ctx, 1, AnswerRecord{TopicID: "articles", QuestionID: 3, AnsweredAt: seen})
+		repo.RecordAnswer(ctx, 2, AnswerRecord{TopicID: "articles", QuestionID: 4, AnsweredAt: seen})
+
+		got, err := repo.LastAnswered(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 || !got[2].Equal(seen.Add(time.Minute)) || !got[3].Equal(seen) {
+			t.Fatalf("expected the latest answer per question, got %v", got)
+		}
+	})
+
+	t.Run("SaveAnswerOnce", func(t *testing.T) {
+		repo := newRepo(t)
+
+		answer := AnswerRecord{TopicID: "articles", QuestionID: 2, WasCorrect: true, AnsweredAt: seen, SessionID: "s1",
+			Mastery: MasteryUpdateResult{Mastery: 45, CorrectStreak: 1, LastSeen: seen}}
+		progress := TopicProgress{TopicID: "articles", Mastery: 45, CorrectStreak: 1, LastSeen: seen}
+		review := ReviewItem{TopicID: "articles", NextReviewAt: seen.Add(24 * time.Hour), Ease: 2.5, IntervalDays: 1, Repetitions: 1}
+
+		if _, err := repo.FindAnswer(ctx, 1, "s1", 2); !errors.Is(err, ErrAnswerNotFound) {
+			t.Fatalf("expected ErrAnswerNotFound, got %v", err)
+		}
+		if err := repo.SaveAnswer(ctx, 1, answer, progress, review); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := repo.FindAnswer(ctx, 1, "s1", 2)
+		if err != nil || got.SessionID != "s1" || got.Mastery.Mastery != 45 {
+			t.Fatalf("expected the saved answer, got %+v %v", got, err)
+		}
+		if stored, _ := repo.ListProgress(ctx, 1); len(stored) != 1 || stored[0].Mastery != 45 {
+			t.Fatalf("expected the progress saved, got %+v", stored)
+		}
+		if reviews, _ := repo.ListReviews(ctx, 1); len(reviews) != 1 || reviews[0].Repetitions != 1 {
+			t.Fatalf("expected the review saved, got %+v", reviews)
+		}
+
+		// Saving it again changes nothing.
+		progress.Mastery = 50
+		if err := repo.SaveAnswer(ctx, 1, answer, progress, review); !errors.Is(err, ErrAlreadyAnswered) {
+			t.Fatalf("expected ErrAlreadyAnswered, got %v", err)
+		}
+		if stored, _ := repo.ListProgress(ctx, 1); stored[0].Mastery != 45 {
+			t.Fatalf("expected the first save kept, got %+v", stored)
+		}
+		if history, _ := repo.ListAnswers(ctx, 1); len(history) != 1 {
+			t.Fatalf("expected one recorded answer, got %+v", history)
+		}
+
+		if _, err := repo.FindAnswer(ctx, 2, "s1", 2); !errors.Is(err, ErrAnswerNotFound) {
+			t.Fatalf("expected answers scoped to the user, got %v", err)
+		}
+	})
+
+	t.Run("StatsCountAnswersAcrossUsers", func(t *testing.T) {
+		repo := newRepo(t)
+
+		for userID, correct := range map[uint64][]bool{1: {true, false, true}, 2: {false}} {
+			for _, c := range correct {
+				repo.RecordAnswer(ctx, userID, AnswerRecord{TopicID: "articles", QuestionID: 4, WasCorrect: c, AnsweredAt: seen})
+			}
+		}
+		saved := AnswerRecord{TopicID: "articles", QuestionID: 2, WasCorrect: true, AnsweredAt: seen, SessionID: "s1"}
+		repo.SaveAnswer(ctx, 2, saved, TopicProgress{TopicID: "articles"}, ReviewItem{TopicID: "articles"})
+
+		// A rejected duplicate is not counted.
+		if err := repo.SaveAnswer(ctx, 2, saved, TopicProgress{TopicID: "articles"}, ReviewItem{TopicID: "articles"}); !errors.Is(err, ErrAlreadyAnswered) {
+			t.Fatalf("expected ErrAlreadyAnswered, got %v", err)
+		}
+
+		want := []QuestionStats{{QuestionID: 2, Attempts: 1, Correct: 1}, {QuestionID: 4, Attempts: 4, Correct: 2}}
+		check := func() {
+			t.Helper()
+			got, err := repo.ListStats(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+				t.Fatalf("expected %+v, got %+v", want, got)
+			}
+		}
+		check()
+
+		// Recounting from the history agrees with the running counts.
+		if err := repo.RecountStats(ctx); err != nil {
+			t.Fatal(err)
+		}
+		check()
+
+		if got, err := repo.GetStats(ctx, 4); err != nil || got != want[1] {
+			t.Fatalf("expected %+v, got %+v %v", want[1], got, err)
+		}
+		if got, err := repo.GetStats(ctx, 9); err != nil || got != (QuestionStats{QuestionID: 9}) {
+			t.Fatalf("expected no answers to question 9, got %+v %v", got, err)
+		}
+	})
+
+	t.Run("UsersAreIsolated", func(t *testing.T) {
+		repo := newRepo(t)
+
+		repo.SaveProgress(ctx, 1, TopicProgress{TopicID: "articles", Mastery: 90})
+		repo.SaveProgress(ctx, 2, TopicProgress{TopicID: "articles", Mastery: 10})
+		repo.SaveReview(ctx, 1, ReviewItem{TopicID: "articles", NextReviewAt: seen})
+
+		got, _ := repo.ListProgress(ctx, 2)
+		if len(got) != 1 || got[0].Mastery != 10 {
+			t.Fatalf("expected only user 2's progress, got %+v", got)
+		}
+		if reviews, _ := repo.ListReviews(ctx, 2); len(reviews) != 0 {
+			t.Fatalf("expected no reviews for user 2, got %+v", reviews)
+		}
+	})
+}
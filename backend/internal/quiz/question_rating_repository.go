@@ -0,0 +1,109 @@
+package quiz
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+
+	"github.com/bugii1995/backend/internal/storage"
+)
+
+// QuestionRatingRepository keeps the question ratings an estimator
+// learns, e.g. EloEstimator's, so they survive a restart.
+//
+// A rating only grows its answers, so SaveRating keeps whichever of
+// the stored and the given rating has more; a late save of an older
+// rating changes nothing. ListRatings returns ratings ordered by
+// question ID.
+type QuestionRatingRepository interface {
+	SaveRating(ctx context.Context, r QuestionRating) error
+	ListRatings(ctx context.Context) ([]QuestionRating, error)
+}
+
+// ---------------- In-memory ----------------
+
+type MemoryQuestionRatingRepository struct {
+	mu      sync.RWMutex
+	ratings map[int64]QuestionRating
+}
+
+func NewMemoryQuestionRatingRepository() *MemoryQuestionRatingRepository {
+	return &MemoryQuestionRatingRepository{ratings: make(map[int64]QuestionRating)}
+}
+
+func (r *MemoryQuestionRatingRepository) SaveRating(ctx context.Context, rating QuestionRating) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored, ok := r.ratings[rating.QuestionID]; ok && stored.Answers >= rating.Answers {
+		return nil
+	}
+	r.ratings[rating.QuestionID] = rating
+	return nil
+}
+
+func (r *MemoryQuestionRatingRepository) ListRatings(ctx context.Context) ([]QuestionRating, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]QuestionRating, 0, len(r.ratings))
+	for _, rating := range r.ratings {
+		out = append(out, rating)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].QuestionID < out[j].QuestionID })
+	return out, nil
+}
+
+// ---------------- SQLite ----------------
+
+var questionRatingMigrations = []string{
+	`CREATE TABLE question_ratings (
+		question_id INTEGER PRIMARY KEY,
+		difficulty  REAL    NOT NULL,
+		answers     INTEGER NOT NULL
+	);`,
+}
+
+type SQLiteQuestionRatingRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteQuestionRatingRepository(db *sql.DB) (*SQLiteQuestionRatingRepository, error) {
+	if err := storage.Migrate(db, "question_ratings", questionRatingMigrations); err != nil {
+		return nil, err
+	}
+	return &SQLiteQuestionRatingRepository{db: db}, nil
+}
+
+func (r *SQLiteQuestionRatingRepository) SaveRating(ctx context.Context, rating QuestionRating) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO question_ratings (question_id, difficulty, answers)
+		VALUES (?, ?, ?)
+		ON CONFLICT (question_id) DO UPDATE SET
+			difficulty = excluded.difficulty,
+			answers    = excluded.answers
+		WHERE excluded.answers > question_ratings.answers`,
+		rating.QuestionID, rating.Difficulty, rating.Answers,
+	)
+	return err
+}
+
+func (r *SQLiteQuestionRatingRepository) ListRatings(ctx context.Context) ([]QuestionRating, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT question_id, difficulty, answers FROM question_ratings ORDER BY question_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]QuestionRating, 0)
+	for rows.Next() {
+		var rating QuestionRating
+		if err := rows.Scan(&rating.QuestionID, &rating.Difficulty, &rating.Answers); err != nil {
+			return nil, err
+		}
+		out = append(out, rating)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,72 @@
+package quiz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bugii1995/backend/internal/storage"
+)
+
+func TestMemoryQuestionRatingRepository(t *testing.T) {
+	testQuestionRatingRepositoryContract(t, func(t *testing.T) QuestionRatingRepository {
+		return NewMemoryQuestionRatingRepository()
+	})
+}
+
+func TestSQLiteQuestionRatingRepository(t *testing.T) {
+	testQuestionRatingRepositoryContract(t, func(t *testing.T) QuestionRatingRepository {
+		db, err := storage.Open(":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		repo, err := NewSQLiteQuestionRatingRepository(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return repo
+	})
+}
+
+// testQuestionRatingRepositoryContract is run against every
+// QuestionRatingRepository.
+func testQuestionRatingRepositoryContract(t *testing.T, newRepo func(t *testing.T) QuestionRatingRepository) {
+	ctx := context.Background()
+
+	t.Run("SaveList", func(t *testing.T) {
+		repo := newRepo(t)
+
+		if all, err := repo.ListRatings(ctx); err != nil || len(all) != 0 {
+			t.Fatalf("expected no ratings, got %+v %v", all, err)
+		}
+
+		repo.SaveRating(ctx, QuestionRating{QuestionID: 7, Difficulty: 0.5, Answers: 1})
+		repo.SaveRating(ctx, QuestionRating{QuestionID: 2, Difficulty: -1, Answers: 3})
+		if err := repo.SaveRating(ctx, QuestionRating{QuestionID: 7, Difficulty: 0.25, Answers: 2}); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := repo.ListRatings(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []QuestionRating{{QuestionID: 2, Difficulty: -1, Answers: 3}, {QuestionID: 7, Difficulty: 0.25, Answers: 2}}
+		if len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+			t.Fatalf("expected %+v, got %+v", want, got)
+		}
+	})
+
+	t.Run("KeepsNewerRating", func(t *testing.T) {
+		repo := newRepo(t)
+
+		repo.SaveRating(ctx, QuestionRating{QuestionID: 7, Difficulty: 0.25, Answers: 2})
+		if err := repo.SaveRating(ctx, QuestionRating{QuestionID: 7, Difficulty: 0.5, Answers: 1}); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, _ := repo.ListRatings(ctx); len(got) != 1 || got[0].Answers != 2 {
+			t.Fatalf("expected the rating with more answers kept, got %+v", got)
+		}
+	})
+}
@@ -0,0 +1,260 @@
+package quiz
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/bugii1995/backend/internal/storage"
+)
+
+// QuestionRepository is the question bank.
+//
+// List methods return questions ordered by ID.
+type QuestionRepository interface {
+	List(ctx context.Context) ([]Question, error)
+	ListByTopic(ctx context.Context, topicID string) ([]Question, error)
+	ListByDifficulty(ctx context.Context, difficulty int) ([]Question, error)
+	ListByStatus(ctx context.Context, status QuestionStatus) ([]Question, error)
+	GetByID(ctx context.Context, id int64) (Question, error)
+
+	// Save inserts or replaces a question. A zero ID is assigned
+	// the next free one and an empty status becomes published; the
+	// stored question is returned.
+	Save(ctx context.Context, q Question) (Question, error)
+}
+
+// ---------------- In-memory ----------------
+
+type MemoryQuestionRepository struct {
+	mu        sync.RWMutex
+	questions map[int64]Question
+	nextID    int64
+}
+
+func NewMemoryQuestionRepository(questions ...Question) *MemoryQuestionRepository {
+	r := &MemoryQuestionRepository{
+		questions: make(map[int64]Question),
+		nextID:    1,
+	}
+	for _, q := range questions {
+		r.Save(context.Background(), q)
+	}
+	return r
+}
+
+func (r *MemoryQuestionRepository) List(ctx context.Context) ([]Question, error) {
+	return r.filter(func(Question) bool { return true }), nil
+}
+
+func (r *MemoryQuestionRepository) ListByTopic(ctx context.Context, topicID string) ([]Question, error) {
+	return r.filter(func(q Question) bool { return q.TopicID == topicID }), nil
+}
+
+func (r *MemoryQuestionRepository) ListByDifficulty(ctx context.Context, difficulty int) ([]Question, error) {
+	return r.filter(func(q Question) bool { return q.Difficulty == difficulty }), nil
+}
+
+func (r *MemoryQuestionRepository) ListByStatus(ctx context.Context, status QuestionStatus) ([]Question, error) {
+	return r.filter(func(q Question) bool { return q.Status == status }), nil
+}
+
+func (r *MemoryQuestionRepository) GetByID(ctx context.Context, id int64) (Question, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	q, ok := r.questions[id]
+	if !ok {
+		return Question{}, ErrQuestionNotFound
+	}
+	return cloneQuestion(q), nil
+}
+
+func (r *MemoryQuestionRepository) Save(ctx context.Context, q Question) (Question, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if q.ID == 0 {
+		q.ID = r.nextID
+	}
+	if q.ID >= r.nextID {
+		r.nextID = q.ID + 1
+	}
+	if q.Status == "" {
+		q.Status = StatusPublished
+	}
+
+	q = cloneQuestion(q)
+	r.questions[q.ID] = q
+	return cloneQuestion(q), nil
+}
+
+func (r *MemoryQuestionRepository) filter(keep func(Question) bool) []Question {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]Question, 0)
+	for _, q := range r.questions {
+		if keep(q) {
+			out = append(out, cloneQuestion(q))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+func cloneQuestion(q Question) Question {
+	q.Options = append([]string(nil), q.Options...)
+	return q
+}
+
+// ---------------- SQLite ----------------
+
+var questionMigrations = []string{
+	`CREATE TABLE questions (
+		id             INTEGER PRIMARY KEY,
+		topic_id       TEXT    NOT NULL,
+		difficulty     INTEGER NOT NULL,
+		prompt         TEXT    NOT NULL,
+		options        TEXT    NOT NULL, -- JSON array
+		correct_answer TEXT    NOT NULL,
+		explanation    TEXT    NOT NULL DEFAULT ''
+	);
+	CREATE INDEX questions_topic ON questions (topic_id);
+	CREATE INDEX questions_difficulty ON questions (difficulty);`,
+	`ALTER TABLE questions ADD COLUMN status TEXT NOT NULL DEFAULT 'published';
+	CREATE INDEX questions_status ON questions (status);`,
+}
+
+type SQLiteQuestionRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteQuestionRepository migrates the questions schema and
+// returns a repository backed by db.
+func NewSQLiteQuestionRepository(db *sql.DB) (*SQLiteQuestionRepository, error) {
+	if err := storage.Migrate(db, "questions", questionMigrations); err != nil {
+		return nil, err
+	}
+	return &SQLiteQuestionRepository{db: db}, nil
+}
+
+const questionColumns = `id, topic_id, difficulty, prompt, options, correct_answer, explanation, status`
+
+func (r *SQLiteQuestionRepository) List(ctx context.Context) ([]Question, error) {
+	return r.query(ctx, `SELECT `+questionColumns+` FROM questions ORDER BY id`)
+}
+
+func (r *SQLiteQuestionRepository) ListByTopic(ctx context.Context, topicID string) ([]Question, error) {
+	return r.query(ctx,
+		`SELECT `+questionColumns+` FROM questions WHERE topic_id = ? ORDER BY id`,
+		topicID,
+	)
+}
+
+func (r *SQLiteQuestionRepository) ListByDifficulty(ctx context.Context, difficulty int) ([]Question, error) {
+	return r.query(ctx,
+		`SELECT `+questionColumns+` FROM questions WHERE difficulty = ? ORDER BY id`,
+		difficulty,
+	)
+}
+
+func (r *SQLiteQuestionRepository) ListByStatus(ctx context.Context, status QuestionStatus) ([]Question, error) {
+	return r.query(ctx,
+		`SELECT `+questionColumns+` FROM questions WHERE status = ? ORDER BY id`,
+		status,
+	)
+}
+
+func (r *SQLiteQuestionRepository) GetByID(ctx context.Context, id int64) (Question, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+questionColumns+` FROM questions WHERE id = ?`,
+		id,
+	)
+	q, err := scanQuestion(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Question{}, ErrQuestionNotFound
+	}
+	return q, err
+}
+
+func (r *SQLiteQuestionRepository) Save(ctx context.Context, q Question) (Question, error) {
+	options, err := json.Marshal(q.Options)
+	if err != nil {
+		return Question{}, err
+	}
+
+	var id any
+	if q.ID != 0 {
+		id = q.ID
+	}
+	if q.Status == "" {
+		q.Status = StatusPublished
+	}
+
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO questions (`+questionColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			topic_id       = excluded.topic_id,
+			difficulty     = excluded.difficulty,
+			prompt         = excluded.prompt,
+			options        = excluded.options,
+			correct_answer = excluded.correct_answer,
+			explanation    = excluded.explanation,
+			status         = excluded.status`,
+		id, q.TopicID, q.Difficulty, q.Prompt, string(options), q.CorrectAnswer, q.Explanation, q.Status,
+	)
+	if err != nil {
+		return Question{}, err
+	}
+
+	if q.ID == 0 {
+		if q.ID, err = res.LastInsertId(); err != nil {
+			return Question{}, err
+		}
+	}
+	return cloneQuestion(q), nil
+}
+
+func (r *SQLiteQuestionRepository) query(ctx context.Context, query string, args ...any) ([]Question, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]Question, 0)
+	for rows.Next() {
+		q, err := scanQuestion(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, q)
+	}
+	return out, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanQuestion(row rowScanner) (Question, error) {
+	var (
+		q       Question
+		options string
+	)
+	if err := row.Scan(
+		&q.ID, &q.TopicID, &q.Difficulty, &q.Prompt,
+		&options, &q.CorrectAnswer, &q.Explanation, &q.Status,
+	); err != nil {
+		return Question{}, err
+	}
+	if err := json.Unmarshal([]byte(options), &q.Options); err != nil {
+		return Question{}, err
+	}
+	return q, nil
+}
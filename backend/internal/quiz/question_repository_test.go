@@ -0,0 +1,228 @@
+package quiz
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bugii1995/backend/internal/storage"
+)
+
+func TestMemoryQuestionRepository(t *testing.T) {
+	testQuestionRepositoryContract(t, func(t *testing.T) QuestionRepository {
+		return NewMemoryQuestionRepository()
+	})
+}
+
+func TestSQLiteQuestionRepository(t *testing.T) {
+	testQuestionRepositoryContract(t, func(t *testing.T) QuestionRepository {
+		db, err := storage.Open(":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		repo, err := NewSQLiteQuestionRepository(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return repo
+	})
+}
+
+// testQuestionRepositoryContract is run against every QuestionRepository.
+func testQuestionRepositoryContract(t *testing.T, newRepo func(t *testing.T) QuestionRepository) {
+	ctx := context.Background()
+
+	fixtures := []Question{
+		{TopicID: "articles", Difficulty: 1, Prompt: "a1", Options: []string{"a", "an"}, CorrectAnswer: "an"},
+		{TopicID: "articles", Difficulty: 2, Prompt: "a2", Options: []string{"a", "an"}, CorrectAnswer: "a"},
+		{TopicID: "conditionals", Difficulty: 2, Prompt: "c2", Options: []string{"if", "when"}, CorrectAnswer: "if", Explanation: "why"},
+	}
+
+	seed := func(t *testing.T) QuestionRepository {
+		repo := newRepo(t)
+		for _, q := range fixtures {
+			if _, err := repo.Save(ctx, q); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return repo
+	}
+
+	t.Run("SaveAssignsIDs", func(t *testing.T) {
+		repo := newRepo(t)
+
+		first, err := repo.Save(ctx, fixtures[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := repo.Save(ctx, fixtures[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if first.ID == 0 || second.ID == 0 || first.ID == second.ID {
+			t.Fatalf("expected distinct non-zero IDs, got %d and %d", first.ID, second.ID)
+		}
+	})
+
+	t.Run("SaveKeepsExplicitID", func(t *testing.T) {
+		repo := newRepo(t)
+
+		q := fixtures[0]
+		q.ID = 42
+		saved, err := repo.Save(ctx, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if saved.ID != 42 {
+			t.Fatalf("expected ID 42, got %d", saved.ID)
+		}
+	})
+
+	t.Run("GetByIDRoundTrip", func(t *testing.T) {
+		repo := newRepo(t)
+
+		saved, err := repo.Save(ctx, fixtures[2])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := repo.GetByID(ctx, saved.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.TopicID != "conditionals" || got.Difficulty != 2 ||
+			got.Prompt != "c2" || got.CorrectAnswer != "if" || got.Explanation != "why" {
+			t.Fatalf("unexpected question: %+v", got)
+		}
+		if len(got.Options) != 2 || got.Options[0] != "if" || got.Options[1] != "when" {
+			t.Fatalf("unexpected options: %v", got.Options)
+		}
+	})
+
+	t.Run("GetByIDMissing", func(t *testing.T) {
+		repo := seed(t)
+
+		_, err := repo.GetByID(ctx, 999)
+		if !errors.Is(err, ErrQuestionNotFound) {
+			t.Fatalf("expected ErrQuestionNotFound, got %v", err)
+		}
+	})
+
+	t.Run("SaveReplaces", func(t *testing.T) {
+		repo := newRepo(t)
+
+		saved, err := repo.Save(ctx, fixtures[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		saved.Prompt = "edited"
+		if _, err := repo.Save(ctx, saved); err != nil {
+			t.Fatal(err)
+		}
+
+		all, err := repo.List(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(all) != 1 || all[0].Prompt != "edited" {
+			t.Fatalf("expected one edited question, got %+v", all)
+		}
+	})
+
+	t.Run("ListOrderedByID", func(t *testing.T) {
+		repo := seed(t)
+
+		all, err := repo.List(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(all) != len(fixtures) {
+			t.Fatalf("expected %d questions, got %d", len(fixtures), len(all))
+		}
+		for i := 1; i < len(all); i++ {
+			if all[i-1].ID >= all[i].ID {
+				t.Fatalf("questions not ordered by ID: %d before %d", all[i-1].ID, all[i].ID)
+			}
+		}
+	})
+
+	t.Run("ListByTopic", func(t *testing.T) {
+		repo := seed(t)
+
+		got, err := repo.ListByTopic(ctx, "articles")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("expected 2 articles questions, got %d", len(got))
+		}
+		for _, q := range got {
+			if q.TopicID != "articles" {
+				t.Fatalf("unexpected topic %q", q.TopicID)
+			}
+		}
+
+		none, err := repo.ListByTopic(ctx, "unknown")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(none) != 0 {
+			t.Fatalf("expected no questions, got %d", len(none))
+		}
+	})
+
+	t.Run("ListByDifficulty", func(t *testing.T) {
+		repo := seed(t)
+
+		got, err := repo.ListByDifficulty(ctx, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("expected 2 medium questions, got %d", len(got))
+		}
+		for _, q := range got {
+			if q.Difficulty != 2 {
+				t.Fatalf("unexpected difficulty %d", q.Difficulty)
+			}
+		}
+	})
+
+	t.Run("ListByStatus", func(t *testing.T) {
+		repo := seed(t)
+
+		draft := fixtures[0]
+		draft.Status = StatusDraft
+		draft, err := repo.Save(ctx, draft)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		published, err := repo.ListByStatus(ctx, StatusPublished)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(published) != len(fixtures) {
+			t.Fatalf("expected an empty status to mean published, got %+v", published)
+		}
+
+		drafts, err := repo.ListByStatus(ctx, StatusDraft)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(drafts) != 1 || drafts[0].ID != draft.ID || drafts[0].Status != StatusDraft {
+			t.Fatalf("expected the draft, got %+v", drafts)
+		}
+
+		draft.Status = StatusRetired
+		repo.Save(ctx, draft)
+		if got, _ := repo.GetByID(ctx, draft.ID); got.Status != StatusRetired {
+			t.Fatalf("expected status to be replaced, got %q", got.Status)
+		}
+	})
+}
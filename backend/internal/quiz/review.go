@@ -0,0 +1,105 @@
+package quiz
+
+import (
+	"math"
+	"time"
+)
+
+//
+// -------- Constants (SM-2 scheduling knobs) --------
+//
+
+const (
+	InitialEase = 2.5
+	MinimumEase = 1.3
+
+	FirstReviewInterval  = 1 // days, after the first success or any failure
+	SecondReviewInterval = 6 // days, after the second success in a row
+
+	// Answer quality on SM-2's 0–5 scale. Correct answers score by
+	// difficulty, so only hard questions make a topic's ease grow.
+	QualityWrong       = 2
+	QualityCorrectEasy = 3
+	QualityCorrectMed  = 4
+	QualityCorrectHard = 5
+)
+
+//
+// -------- Public API --------
+//
+
+// ScheduleReview returns current rescheduled after an answer to one
+// of its topic's questions. Pass ReviewItem{TopicID: ...} for a topic
+// that has no review yet.
+//
+// Successes lengthen the interval (1 day, 6 days, then × ease) but
+// only count once the review is due, so answering again early does
+// not push it further out. Any failure resets the interval.
+func ScheduleReview(
+	current ReviewItem,
+	wasCorrect bool,
+	difficulty int,
+	answeredAt time.Time,
+) ReviewItem {
+
+	isNew := current.Repetitions == 0 && current.NextReviewAt.IsZero()
+	if wasCorrect && !isNew && answeredAt.Before(current.NextReviewAt) {
+		return current
+	}
+
+	next := current
+	next.Ease = updateEase(current.Ease, reviewQuality(wasCorrect, difficulty))
+
+	if wasCorrect {
+		next.Repetitions++
+		next.IntervalDays = nextInterval(next.Repetitions, current.IntervalDays, next.Ease)
+	} else {
+		next.Repetitions = 0
+		next.IntervalDays = FirstReviewInterval
+	}
+
+	next.NextReviewAt = answeredAt.AddDate(0, 0, next.IntervalDays)
+	return next
+}
+
+//
+// -------- Internal helpers --------
+//
+
+func reviewQuality(correct bool, difficulty int) int {
+	if !correct {
+		return QualityWrong
+	}
+	switch difficulty {
+	case 1:
+		return QualityCorrectEasy
+	case 3:
+		return QualityCorrectHard
+	default:
+		return QualityCorrectMed
+	}
+}
+
+// updateEase is SM-2's EF' = EF + (0.1 - (5-q)(0.08 + (5-q)0.02)).
+func updateEase(ease float64, quality int) float64 {
+	// Items stored before scheduling existed have no ease yet.
+	if ease == 0 {
+		ease = InitialEase
+	}
+
+	miss := float64(5 - quality)
+	ease += 0.1 - miss*(0.08+miss*0.02)
+
+	return math.Max(ease, MinimumEase)
+}
+
+func nextInterval(repetitions, previousDays int, ease float64) int {
+	switch repetitions {
+	case 1:
+		return FirstReviewInterval
+	case 2:
+		return SecondReviewInterval
+	default:
+		return int(math.Round(float64(previousDays) * ease))
+	}
+}
@@ -0,0 +1,121 @@
+package quiz
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleReview_MultiWeekTimeline(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return start.AddDate(0, 0, n) }
+
+	steps := []struct {
+		name        string
+		at          time.Time
+		correct     bool
+		wantReps    int
+		wantDays    int
+		wantNextDay int
+	}{
+		{"first answer", day(0), true, 1, 1, 1},
+		{"early success does not count", day(0).Add(2 * time.Hour), true, 1, 1, 1},
+		{"second success", day(1), true, 2, 6, 7},
+		{"third success grows by ease", day(7), true, 3, 15, 22},
+		{"lapse resets", day(22), false, 0, 1, 23},
+		{"relearn", day(23), true, 1, 1, 24},
+		{"relearn again", day(24), true, 2, 6, 30},
+		{"lower ease grows slower", day(30), true, 3, 13, 43},
+	}
+
+	item := ReviewItem{TopicID: "articles"}
+	for _, step := range steps {
+		item = ScheduleReview(item, step.correct, 2, step.at)
+
+		if item.Repetitions != step.wantReps || item.IntervalDays != step.wantDays {
+			t.Fatalf("%s: expected %d reps / %d days, got %+v", step.name, step.wantReps, step.wantDays, item)
+		}
+		if !item.NextReviewAt.Equal(day(step.wantNextDay)) {
+			t.Fatalf("%s: expected review on day %d, got %v", step.name, step.wantNextDay, item.NextReviewAt)
+		}
+	}
+}
+
+func TestScheduleReview_LapseAlwaysResets(t *testing.T) {
+	now := time.Now()
+
+	current := ReviewItem{
+		TopicID:      "articles",
+		NextReviewAt: now.AddDate(0, 0, 20),
+		Ease:         2.5,
+		IntervalDays: 30,
+		Repetitions:  4,
+	}
+
+	// Failing before the review is due still resets it.
+	got := ScheduleReview(current, false, 2, now)
+
+	if got.Repetitions != 0 || got.IntervalDays != FirstReviewInterval {
+		t.Fatalf("expected reset, got %+v", got)
+	}
+	if got.Ease >= current.Ease {
+		t.Fatalf("expected ease to drop, got %v", got.Ease)
+	}
+}
+
+func TestScheduleReview_EaseByDifficulty(t *testing.T) {
+	now := time.Now()
+
+	hard := ScheduleReview(ReviewItem{TopicID: "articles"}, true, 3, now)
+	medium := ScheduleReview(ReviewItem{TopicID: "articles"}, true, 2, now)
+	easy := ScheduleReview(ReviewItem{TopicID: "articles"}, true, 1, now)
+
+	if !(hard.Ease > medium.Ease && medium.Ease > easy.Ease) {
+		t.Fatalf("expected hard > medium > easy ease, got %v %v %v", hard.Ease, medium.Ease, easy.Ease)
+	}
+	if medium.Ease != InitialEase {
+		t.Fatalf("expected medium success to keep ease %v, got %v", InitialEase, medium.Ease)
+	}
+}
+
+func TestScheduleReview_EaseFloor(t *testing.T) {
+	now := time.Now()
+
+	item := ReviewItem{TopicID: "articles"}
+	for i := 0; i < 20; i++ {
+		item = ScheduleReview(item, false, 2, now.AddDate(0, 0, i))
+	}
+
+	if item.Ease != MinimumEase {
+		t.Fatalf("expected ease floor %v, got %v", MinimumEase, item.Ease)
+	}
+}
+
+func TestSessionReviewFiresWhenDue(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	questions := []Question{
+		{ID: 1, TopicID: "articles", Difficulty: 2},
+		{ID: 2, TopicID: "articles", Difficulty: 3},
+	}
+	progress := []TopicProgress{{TopicID: "articles", Mastery: 90, LastSeen: start}}
+
+	first := NewSession(questions, progress, nil)
+	first.SubmitAnswer(Answer{QuestionID: 2, TopicID: "articles", WasCorrect: true, Difficulty: 3}, start)
+
+	if len(first.Reviews) != 1 {
+		t.Fatalf("expected answering to schedule a review, got %+v", first.Reviews)
+	}
+
+	// Later the same day nothing is due, so a confident learner is stretched.
+	sameDay := NewSession(questions, progress, first.Reviews)
+	if got := sameDay.NextQuestion(start.Add(time.Hour)); got == nil || got.Purpose != PurposeStretch {
+		t.Fatalf("expected stretch before the review is due, got %+v", got)
+	}
+
+	// Once due, the review takes priority.
+	nextDay := NewSession(questions, progress, first.Reviews)
+	got := nextDay.NextQuestion(start.AddDate(0, 0, 1))
+	if got == nil || got.Purpose != PurposeReview || got.QuestionID != 1 {
+		t.Fatalf("expected the due review, got %+v", got)
+	}
+}
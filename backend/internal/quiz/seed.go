@@ -0,0 +1,85 @@
+package quiz
+
+import "context"
+
+// DefaultQuestions is the starter bank used when storage is empty.
+func DefaultQuestions() []Question {
+	return []Question{
+		{
+			ID:            1,
+			TopicID:       "articles",
+			Difficulty:    1,
+			Prompt:        "Choose the correct article: ___ apple",
+			Options:       []string{"a", "an", "the"},
+			CorrectAnswer: "an",
+			Explanation:   "We use 'an' before words that start with a vowel sound.",
+		},
+		{
+			ID:            2,
+			TopicID:       "articles",
+			Difficulty:    2,
+			Prompt:        "Choose the correct article: ___ university",
+			Options:       []string{"a", "an", "the"},
+			CorrectAnswer: "a",
+			Explanation:   "'University' starts with a 'you' sound, so we use 'a'.",
+		},
+	}
+}
+
+// DefaultTopics is the starter curriculum. Topics without questions
+// yet are there for authors to fill in.
+func DefaultTopics() []Topic {
+	return []Topic{
+		{
+			ID:          "articles",
+			Name:        "Articles",
+			Description: "A, an and the.",
+			CEFRLevel:   LevelA1,
+		},
+		{
+			ID:          "present_simple",
+			Name:        "Present simple",
+			Description: "Habits, facts and routines.",
+			CEFRLevel:   LevelA1,
+		},
+		{
+			ID:            "present_continuous",
+			Name:          "Present continuous",
+			Description:   "What is happening now.",
+			CEFRLevel:     LevelA1,
+			Prerequisites: []string{"present_simple"},
+		},
+		{
+			ID:            "past_simple",
+			Name:          "Past simple",
+			Description:   "Finished actions in the past.",
+			CEFRLevel:     LevelA2,
+			Prerequisites: []string{"present_simple"},
+		},
+		{
+			ID:            "conditionals",
+			Name:          "Conditionals",
+			Description:   "Zero, first and second conditionals.",
+			CEFRLevel:     LevelB1,
+			Prerequisites: []string{"present_simple", "past_simple"},
+		},
+	}
+}
+
+// SeedQuestions saves questions into repo only if the bank is empty.
+func SeedQuestions(ctx context.Context, repo QuestionRepository, questions []Question) error {
+	existing, err := repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return nil
+	}
+
+	for _, q := range questions {
+		if _, err := repo.Save(ctx, q); err != nil {
+			return err
+		}
+	}
+	return nil
+}
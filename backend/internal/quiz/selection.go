@@ -1,6 +1,10 @@
 package quiz
 
-import "time"
+import (
+	"slices"
+	"strings"
+	"time"
+)
 
 //
 // -------- Domain snapshots (DB-agnostic) --------
@@ -17,9 +21,14 @@ type TopicProgress struct {
 }
 
 // ReviewItem represents a scheduled spaced-repetition review.
+// Ease, IntervalDays and Repetitions are SM-2 state, see ScheduleReview.
 type ReviewItem struct {
 	TopicID      string
 	NextReviewAt time.Time
+
+	Ease         float64
+	IntervalDays int
+	Repetitions  int // successes in a row
 }
 
 // Question is lightweight metadata used for selection only.
@@ -32,8 +41,20 @@ type Question struct {
 	Options       []string
 	CorrectAnswer string
 	Explanation   string
+
+	Status QuestionStatus
 }
 
+// QuestionStatus is a question's place in the authoring workflow.
+// Only published questions are quizzed.
+type QuestionStatus string
+
+const (
+	StatusDraft     QuestionStatus = "draft"
+	StatusPublished QuestionStatus = "published"
+	StatusRetired   QuestionStatus = "retired" // kept for history and running sessions
+)
+
 
 //
 // -------- Question purpose (semantic intent) --------
@@ -52,6 +73,7 @@ const (
 type SelectedQuestion struct {
 	QuestionID int64
 	Purpose    QuestionPurpose
+	Variant    string // experiment variant that selected it, if any
 }
 
 //
@@ -67,6 +89,15 @@ type SelectedQuestion struct {
 // 4. Stretch (hard questions for confident topics)
 // 5. Safe fallback
 //
+// Topics below mastery 40 are weak and from 80 confident. Each step
+// prefers a difficulty but falls back to others a topic has (see
+// DefaultDifficulties), and the fallback serves any question left.
+// RuleSelector and WeightedSelector take other settings as Tiers.
+//
+// Within each step topics are tried in RankProgress order and reviews
+// in RankReviews order, so the order of progress and reviews does not
+// matter. Questions are tried in the order given.
+//
 // This function is deterministic and side-effect free.
 func SelectNextQuestion(
 	now time.Time,
@@ -76,71 +107,187 @@ func SelectNextQuestion(
 	recentWrongTopicIDs map[string]bool,
 ) *SelectedQuestion {
 
-	// 1️⃣ Spaced repetition (highest priority)
-	for _, r := range reviews {
-		if !r.NextReviewAt.After(now) {
-			for _, q := range questions {
-				if q.TopicID == r.TopicID && q.Difficulty == 2 {
-					return &SelectedQuestion{
-						QuestionID: q.ID,
-						Purpose:    PurposeReview,
-					}
-				}
+	return RuleSelector{}.Select(SelectionInput{
+		Now:               now,
+		Progress:          RankProgress(progress),
+		Reviews:           RankReviews(reviews),
+		Questions:         questions,
+		RecentWrongTopics: recentWrongTopicIDs,
+	})
+}
+
+//
+// -------- Ranking --------
+//
+
+// RankProgress returns progress sorted by who needs practice most:
+// lowest mastery first, then least recently seen (never seen first),
+// then topic ID.
+func RankProgress(progress []TopicProgress) []TopicProgress {
+	ranked := slices.Clone(progress)
+	slices.SortStableFunc(ranked, func(a, b TopicProgress) int {
+		if a.Mastery != b.Mastery {
+			if a.Mastery < b.Mastery {
+				return -1
 			}
+			return 1
 		}
+		if c := a.LastSeen.Compare(b.LastSeen); c != 0 {
+			return c
+		}
+		return strings.Compare(a.TopicID, b.TopicID)
+	})
+	return ranked
+}
+
+// RankReviews returns reviews sorted most overdue first (earliest
+// NextReviewAt), then by topic ID.
+func RankReviews(reviews []ReviewItem) []ReviewItem {
+	ranked := slices.Clone(reviews)
+	slices.SortStableFunc(ranked, func(a, b ReviewItem) int {
+		if c := a.NextReviewAt.Compare(b.NextReviewAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.TopicID, b.TopicID)
+	})
+	return ranked
+}
+
+// Tiers holds the mastery thresholds between SelectNextQuestion's
+// tiers and the difficulties each purpose accepts, most preferred
+// first. Zero fields mean the defaults.
+type Tiers struct {
+	ReinforceBelow float64 `json:"reinforce_below" yaml:"reinforce_below"` // weaker topics are reinforced
+	StretchFrom    float64 `json:"stretch_from" yaml:"stretch_from"`       // stronger topics are stretched
+
+	Difficulties map[QuestionPurpose][]int `json:"difficulties" yaml:"difficulties"`
+}
+
+const (
+	DefaultReinforceBelow = 40.0
+	DefaultStretchFrom    = 80.0
+)
+
+// DefaultDifficulties prefers easy questions to reinforce, medium ones
+// to review and progress, hard ones to stretch, and falls back to the
+// nearest difficulty when a topic has none.
+func DefaultDifficulties() map[QuestionPurpose][]int {
+	return map[QuestionPurpose][]int{
+		PurposeReview:    {2, 1, 3},
+		PurposeReinforce: {1, 2, 3},
+		PurposeProgress:  {2, 1, 3},
+		PurposeStretch:   {3, 2, 1},
 	}
+}
 
-	// 2️⃣ Reinforce weak or mistake-prone topics
-	for _, p := range progress {
-		if p.Mastery < 40 || recentWrongTopicIDs[p.TopicID] {
-			for _, q := range questions {
-				if q.TopicID == p.TopicID && q.Difficulty == 1 {
-					return &SelectedQuestion{
-						QuestionID: q.ID,
-						Purpose:    PurposeReinforce,
-					}
+// or fills t's zero fields from fallback.
+func (t Tiers) or(fallback Tiers) Tiers {
+	if t.ReinforceBelow == 0 {
+		t.ReinforceBelow = fallback.ReinforceBelow
+	}
+	if t.StretchFrom == 0 {
+		t.StretchFrom = fallback.StretchFrom
+	}
+	if t.Difficulties == nil {
+		t.Difficulties = fallback.Difficulties
+	}
+	return t
+}
+
+func (t Tiers) withDefaults() Tiers {
+	if t.ReinforceBelow == 0 {
+		t.ReinforceBelow = DefaultReinforceBelow
+	}
+	if t.StretchFrom == 0 {
+		t.StretchFrom = DefaultStretchFrom
+	}
+	if t.Difficulties == nil {
+		t.Difficulties = DefaultDifficulties()
+	}
+	return t
+}
+
+// eligible returns the first tier, in SelectNextQuestion's priority
+// order, that has questions, and all of its questions in the order
+// they were found: by in.Reviews or in.Progress, then by in.Questions.
+// The first one is what the rule-based selection serves.
+//
+// Each topic contributes the questions of the first difficulty in
+// t.Difficulties[purpose] that it has. The fallback tier takes every
+// question left, so selection only ends when the bank does.
+func (t Tiers) eligible(in SelectionInput) (QuestionPurpose, []Question) {
+	t = t.withDefaults()
+
+	var tier []Question
+	matching := func(topicID string, purpose QuestionPurpose) {
+		for _, d := range t.Difficulties[purpose] {
+			found := false
+			for _, q := range in.Questions {
+				if q.TopicID == topicID && q.Difficulty == d {
+					tier = append(tier, q)
+					found = true
 				}
 			}
+			if found {
+				return
+			}
 		}
 	}
 
+	// 1️⃣ Spaced repetition (highest priority)
+	for _, r := range in.Reviews {
+		if !r.NextReviewAt.After(in.Now) {
+			matching(r.TopicID, PurposeReview)
+		}
+	}
+	if len(tier) > 0 {
+		return PurposeReview, tier
+	}
+
+	// 2️⃣ Reinforce weak or mistake-prone topics
+	for _, p := range in.Progress {
+		if p.Mastery < t.ReinforceBelow || in.RecentWrongTopics[p.TopicID] {
+			matching(p.TopicID, PurposeReinforce)
+		}
+	}
+	if len(tier) > 0 {
+		return PurposeReinforce, tier
+	}
+
 	// 3️⃣ Normal progression
-	for _, p := range progress {
-		if p.Mastery >= 40 && p.Mastery < 80 {
-			for _, q := range questions {
-				if q.TopicID == p.TopicID && q.Difficulty == 2 {
-					return &SelectedQuestion{
-						QuestionID: q.ID,
-						Purpose:    PurposeProgress,
-					}
-				}
-			}
+	for _, p := range in.Progress {
+		if p.Mastery >= t.ReinforceBelow && p.Mastery < t.StretchFrom {
+			matching(p.TopicID, PurposeProgress)
 		}
 	}
+	if len(tier) > 0 {
+		return PurposeProgress, tier
+	}
 
 	// 4️⃣ Stretch confident users
-	for _, p := range progress {
-		if p.Mastery >= 80 {
-			for _, q := range questions {
-				if q.TopicID == p.TopicID && q.Difficulty == 3 {
-					return &SelectedQuestion{
-						QuestionID: q.ID,
-						Purpose:    PurposeStretch,
-					}
-				}
-			}
+	for _, p := range in.Progress {
+		if p.Mastery >= t.StretchFrom {
+			matching(p.TopicID, PurposeStretch)
 		}
 	}
+	if len(tier) > 0 {
+		return PurposeStretch, tier
+	}
 
-	// 5️⃣ Fallback (never block quiz flow)
-	for _, q := range questions {
-		if q.Difficulty == 2 {
-			return &SelectedQuestion{
-				QuestionID: q.ID,
-				Purpose:    PurposeProgress,
+	// 5️⃣ Fallback (never block quiz flow): any question left, in the
+	// progress difficulty order
+	preferred := t.Difficulties[PurposeProgress]
+	for _, d := range preferred {
+		for _, q := range in.Questions {
+			if q.Difficulty == d {
+				tier = append(tier, q)
 			}
 		}
 	}
-
-	return nil
+	for _, q := range in.Questions {
+		if !slices.Contains(preferred, q.Difficulty) {
+			tier = append(tier, q)
+		}
+	}
+	return PurposeProgress, tier
 }
@@ -1,6 +1,8 @@
 package quiz
 
 import (
+	"math/rand/v2"
+	"slices"
 	"testing"
 	"time"
 )
@@ -136,3 +138,253 @@ func TestSelectNextQuestion_Fallback(t *testing.T) {
 		t.Fatal("expected fallback question")
 	}
 }
+
+func TestRankProgress(t *testing.T) {
+	now := time.Now()
+
+	progress := []TopicProgress{
+		{TopicID: "past_simple", Mastery: 50, LastSeen: now},
+		{TopicID: "articles", Mastery: 70},
+		{TopicID: "conditionals", Mastery: 50, LastSeen: now.Add(-time.Hour)},
+		{TopicID: "idioms", Mastery: 50, LastSeen: now},
+		{TopicID: "present_simple", Mastery: 20, LastSeen: now},
+	}
+
+	var got []string
+	for _, p := range RankProgress(progress) {
+		got = append(got, p.TopicID)
+	}
+
+	want := []string{"present_simple", "conditionals", "idioms", "past_simple", "articles"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestRankReviews(t *testing.T) {
+	now := time.Now()
+
+	reviews := []ReviewItem{
+		{TopicID: "past_simple", NextReviewAt: now.Add(-time.Hour)},
+		{TopicID: "articles", NextReviewAt: now.Add(-48 * time.Hour)},
+		{TopicID: "idioms", NextReviewAt: now.Add(time.Hour)},
+		{TopicID: "conditionals", NextReviewAt: now.Add(-time.Hour)},
+	}
+
+	var got []string
+	for _, r := range RankReviews(reviews) {
+		got = append(got, r.TopicID)
+	}
+
+	want := []string{"articles", "conditionals", "past_simple", "idioms"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestSelectNextQuestion_WeakestTopicFirst(t *testing.T) {
+	now := time.Now()
+
+	progress := []TopicProgress{
+		{TopicID: "articles", Mastery: 35},
+		{TopicID: "past_simple", Mastery: 10},
+	}
+
+	questions := []Question{
+		{ID: 1, TopicID: "articles", Difficulty: 1},
+		{ID: 2, TopicID: "past_simple", Difficulty: 1},
+	}
+
+	result := SelectNextQuestion(now, progress, nil, questions, nil)
+
+	if result == nil || result.QuestionID != 2 {
+		t.Fatalf("expected the weaker topic to be reinforced, got %+v", result)
+	}
+}
+
+// randomSelectionInput builds a bank where several topics compete at
+// every step, so any ordering dependence would show.
+func randomSelectionInput(rng *rand.Rand, now time.Time) ([]TopicProgress, []ReviewItem, []Question, map[string]bool) {
+	topics := []string{"articles", "conditionals", "idioms", "past_simple", "present_simple"}
+
+	var (
+		progress []TopicProgress
+		reviews  []ReviewItem
+		wrong    = make(map[string]bool)
+	)
+	for _, topic := range topics {
+		progress = append(progress, TopicProgress{
+			TopicID: topic,
+			// Few distinct values, so ties are common.
+			Mastery:  float64(rng.IntN(5) * 20),
+			LastSeen: now.Add(-time.Duration(rng.IntN(3)) * time.Hour),
+		})
+		if rng.IntN(2) == 0 {
+			reviews = append(reviews, ReviewItem{
+				TopicID:      topic,
+				NextReviewAt: now.Add(time.Duration(rng.IntN(3)-1) * time.Hour),
+			})
+		}
+		if rng.IntN(4) == 0 {
+			wrong[topic] = true
+		}
+	}
+
+	var questions []Question
+	for id := int64(1); id <= 12; id++ {
+		questions = append(questions, Question{
+			ID:         id,
+			TopicID:    topics[rng.IntN(len(topics))],
+			Difficulty: 1 + rng.IntN(3),
+		})
+	}
+	return progress, reviews, questions, wrong
+}
+
+func TestSelectNextQuestion_IgnoresInputOrder(t *testing.T) {
+	now := time.Now()
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	for i := 0; i < 500; i++ {
+		progress, reviews, questions, wrong := randomSelectionInput(rng, now)
+		want := SelectNextQuestion(now, progress, reviews, questions, wrong)
+
+		for j := 0; j < 5; j++ {
+			rng.Shuffle(len(progress), func(a, b int) { progress[a], progress[b] = progress[b], progress[a] })
+			rng.Shuffle(len(reviews), func(a, b int) { reviews[a], reviews[b] = reviews[b], reviews[a] })
+
+			got := SelectNextQuestion(now, progress, reviews, questions, wrong)
+			if (got == nil) != (want == nil) || (got != nil && *got != *want) {
+				t.Fatalf("case %d: expected %+v after reordering, got %+v", i, want, got)
+			}
+		}
+	}
+}
+
+func TestSessionNextQuestionIsDeterministic(t *testing.T) {
+	now := time.Now()
+	rng := rand.New(rand.NewPCG(3, 4))
+
+	for i := 0; i < 200; i++ {
+		progress, reviews, questions, wrong := randomSelectionInput(rng, now)
+
+		var first *SelectedQuestion
+		for j := 0; j < 5; j++ {
+			session := NewSession(questions, progress, reviews)
+			for topic := range wrong {
+				session.RecentWrongTopics[topic] = true
+			}
+
+			// Each session iterates its Progress map in a new order.
+			got := session.NextQuestion(now)
+			if j == 0 {
+				first = got
+				continue
+			}
+			if (got == nil) != (first == nil) || (got != nil && *got != *first) {
+				t.Fatalf("case %d: expected %+v every time, got %+v", i, first, got)
+			}
+		}
+	}
+}
+
+func TestSelectNextQuestion_DifficultyFallbacks(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		progress  []TopicProgress
+		reviews   []ReviewItem
+		questions []Question
+		want      SelectedQuestion
+	}{
+		{
+			name:      "reinforce prefers easy",
+			progress:  []TopicProgress{{TopicID: "articles", Mastery: 20}},
+			questions: []Question{{ID: 1, TopicID: "articles", Difficulty: 3}, {ID: 2, TopicID: "articles", Difficulty: 2}, {ID: 3, TopicID: "articles", Difficulty: 1}},
+			want:      SelectedQuestion{QuestionID: 3, Purpose: PurposeReinforce},
+		},
+		{
+			name:      "reinforce with only hard questions",
+			progress:  []TopicProgress{{TopicID: "articles", Mastery: 20}},
+			questions: []Question{{ID: 1, TopicID: "articles", Difficulty: 3}},
+			want:      SelectedQuestion{QuestionID: 1, Purpose: PurposeReinforce},
+		},
+		{
+			name:      "review without a medium question",
+			progress:  []TopicProgress{{TopicID: "articles", Mastery: 60}},
+			reviews:   []ReviewItem{{TopicID: "articles", NextReviewAt: now.Add(-time.Hour)}},
+			questions: []Question{{ID: 1, TopicID: "articles", Difficulty: 3}, {ID: 2, TopicID: "articles", Difficulty: 1}},
+			want:      SelectedQuestion{QuestionID: 2, Purpose: PurposeReview},
+		},
+		{
+			name:      "stretch without a hard question",
+			progress:  []TopicProgress{{TopicID: "articles", Mastery: 90}},
+			questions: []Question{{ID: 1, TopicID: "articles", Difficulty: 1}, {ID: 2, TopicID: "articles", Difficulty: 2}},
+			want:      SelectedQuestion{QuestionID: 2, Purpose: PurposeStretch},
+		},
+		{
+			name:      "fallback serves any difficulty",
+			questions: []Question{{ID: 1, TopicID: "misc", Difficulty: 3}},
+			want:      SelectedQuestion{QuestionID: 1, Purpose: PurposeProgress},
+		},
+		{
+			name:      "fallback prefers medium",
+			questions: []Question{{ID: 1, TopicID: "misc", Difficulty: 3}, {ID: 2, TopicID: "misc", Difficulty: 1}, {ID: 3, TopicID: "misc", Difficulty: 2}},
+			want:      SelectedQuestion{QuestionID: 3, Purpose: PurposeProgress},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SelectNextQuestion(now, tt.progress, tt.reviews, tt.questions, nil)
+			if got == nil || *got != tt.want {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRuleSelectorCustomDifficulties(t *testing.T) {
+	in := SelectionInput{
+		Now: time.Now(),
+		Progress: []TopicProgress{
+			{TopicID: "articles", Mastery: 20},
+			{TopicID: "idioms", Mastery: 50},
+		},
+		Questions: []Question{
+			{ID: 1, TopicID: "articles", Difficulty: 3},
+			{ID: 2, TopicID: "idioms", Difficulty: 2},
+		},
+	}
+
+	// Strictly easy reinforcement skips a topic with only hard questions.
+	selector := RuleSelector{Tiers: Tiers{Difficulties: map[QuestionPurpose][]int{
+		PurposeReinforce: {1},
+		PurposeProgress:  {2},
+	}}}
+	if got := selector.Select(in); got == nil || got.QuestionID != 2 || got.Purpose != PurposeProgress {
+		t.Fatalf("expected idioms to progress, got %+v", got)
+	}
+}
+
+func TestSessionServesEveryQuestion(t *testing.T) {
+	now := time.Now()
+
+	var questions []Question
+	for id := int64(1); id <= 9; id++ {
+		questions = append(questions, Question{ID: id, TopicID: "articles", Difficulty: int(id%3) + 1})
+	}
+	session := NewSession(questions, []TopicProgress{{TopicID: "articles", Mastery: InitialMastery}}, nil)
+
+	served := 0
+	next := session.NextQuestion(now)
+	for next != nil {
+		served++
+		next, _ = session.SubmitAnswer(Answer{QuestionID: next.QuestionID, TopicID: "articles", WasCorrect: served%2 == 0, Difficulty: questions[next.QuestionID-1].Difficulty}, now)
+	}
+
+	if served != len(questions) || !session.Finished {
+		t.Fatalf("expected all %d questions before finishing, served %d", len(questions), served)
+	}
+}
@@ -0,0 +1,151 @@
+package quiz
+
+import (
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+//
+// -------- Strategy --------
+//
+
+// SelectionInput is what a Selector decides from. Progress and Reviews
+// are tried in the order given; rank them with RankProgress and
+// RankReviews.
+type SelectionInput struct {
+	Now               time.Time
+	Progress          []TopicProgress
+	Reviews           []ReviewItem
+	Questions         []Question // not yet asked in this session
+	RecentWrongTopics map[string]bool
+
+	// LastShown is when the learner last answered each question, in
+	// any session. Questions missing from it were never shown.
+	LastShown map[int64]time.Time
+
+	// Tiers is the configured selection; a selector's own Tiers take
+	// precedence field by field.
+	Tiers Tiers
+}
+
+// Selector picks the next question to serve, or nil when none is left.
+type Selector interface {
+	Select(in SelectionInput) *SelectedQuestion
+}
+
+// RuleSelector serves the first question of the first non-empty tier,
+// as documented on SelectNextQuestion. It is the default Selector.
+type RuleSelector struct {
+	Tiers Tiers
+}
+
+func (s RuleSelector) Select(in SelectionInput) *SelectedQuestion {
+	purpose, tier := s.Tiers.or(in.Tiers).eligible(in)
+	if len(tier) == 0 {
+		return nil
+	}
+	return &SelectedQuestion{QuestionID: tier[0].ID, Purpose: purpose}
+}
+
+//
+// -------- Weighted random selection --------
+//
+
+const (
+	// OverdueWeightPerDay is added to a review's weight for each day
+	// it is overdue.
+	OverdueWeightPerDay = 0.5
+
+	// WeaknessWeight is the extra weight of a topic at mastery 0,
+	// shrinking linearly to nothing at 100.
+	WeaknessWeight = 2.0
+
+	// StaleWeightPerDay is added for each day since a question was
+	// last shown, up to StaleWeightMaxDays. Never-shown questions get
+	// the maximum.
+	StaleWeightPerDay  = 0.25
+	StaleWeightMaxDays = 28
+)
+
+// WeightedSelector keeps SelectNextQuestion's tiers but picks at
+// random within the chosen tier, so learners do not see the same
+// questions in the same order every session. A question's weight is
+// the product of how overdue its topic's review is, how weak the topic
+// is and how long since the question was shown.
+//
+// It is safe for concurrent use.
+type WeightedSelector struct {
+	Tiers Tiers
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// NewWeightedSelector draws from src; pass a seeded source for
+// reproducible picks.
+func NewWeightedSelector(src rand.Source) *WeightedSelector {
+	return &WeightedSelector{rng: rand.New(src)}
+}
+
+func (s *WeightedSelector) Select(in SelectionInput) *SelectedQuestion {
+	purpose, tier := s.Tiers.or(in.Tiers).eligible(in)
+	if len(tier) == 0 {
+		return nil
+	}
+
+	mastery := make(map[string]float64, len(in.Progress))
+	for _, p := range in.Progress {
+		mastery[p.TopicID] = p.Mastery
+	}
+	dueSince := make(map[string]time.Time, len(in.Reviews))
+	for _, r := range in.Reviews {
+		dueSince[r.TopicID] = r.NextReviewAt
+	}
+
+	weights := make([]float64, len(tier))
+	total := 0.0
+	for i, q := range tier {
+		weights[i] = overdueWeight(in.Now, dueSince[q.TopicID]) *
+			weaknessWeight(mastery[q.TopicID]) *
+			staleWeight(in.Now, in.LastShown[q.ID])
+		total += weights[i]
+	}
+
+	s.mu.Lock()
+	pick := s.rng.Float64() * total
+	s.mu.Unlock()
+
+	for i, w := range weights {
+		pick -= w
+		if pick < 0 {
+			return &SelectedQuestion{QuestionID: tier[i].ID, Purpose: purpose}
+		}
+	}
+	// Rounding can leave pick at exactly 0.
+	return &SelectedQuestion{QuestionID: tier[len(tier)-1].ID, Purpose: purpose}
+}
+
+//
+// -------- Internal helpers --------
+//
+
+func overdueWeight(now, dueAt time.Time) float64 {
+	if dueAt.IsZero() || dueAt.After(now) {
+		return 1
+	}
+	return 1 + OverdueWeightPerDay*now.Sub(dueAt).Hours()/24
+}
+
+func weaknessWeight(mastery float64) float64 {
+	return 1 + WeaknessWeight*(100-clamp(mastery, 0, 100))/100
+}
+
+func staleWeight(now, lastShown time.Time) float64 {
+	days := float64(StaleWeightMaxDays)
+	if !lastShown.IsZero() {
+		days = math.Min(math.Max(now.Sub(lastShown).Hours()/24, 0), days)
+	}
+	return 1 + StaleWeightPerDay*days
+}
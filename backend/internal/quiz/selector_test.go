@@ -0,0 +1,180 @@
+package quiz
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+func rankedInput(rng *rand.Rand, now time.Time) SelectionInput {
+	progress, reviews, questions, wrong := randomSelectionInput(rng, now)
+	return SelectionInput{
+		Now:               now,
+		Progress:          RankProgress(progress),
+		Reviews:           RankReviews(reviews),
+		Questions:         questions,
+		RecentWrongTopics: wrong,
+	}
+}
+
+func TestRuleSelectorMatchesSelectNextQuestion(t *testing.T) {
+	now := time.Now()
+	rng := rand.New(rand.NewPCG(5, 6))
+
+	for i := 0; i < 500; i++ {
+		in := rankedInput(rng, now)
+
+		want := SelectNextQuestion(in.Now, in.Progress, in.Reviews, in.Questions, in.RecentWrongTopics)
+		got := RuleSelector{}.Select(in)
+		if (got == nil) != (want == nil) || (got != nil && *got != *want) {
+			t.Fatalf("case %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+}
+
+func TestWeightedSelectorStaysInTier(t *testing.T) {
+	now := time.Now()
+	rng := rand.New(rand.NewPCG(7, 8))
+	selector := NewWeightedSelector(rand.NewPCG(1, 1))
+
+	for i := 0; i < 500; i++ {
+		in := rankedInput(rng, now)
+
+		purpose, tier := Tiers{}.eligible(in)
+		got := selector.Select(in)
+		if len(tier) == 0 {
+			if got != nil {
+				t.Fatalf("case %d: expected nil, got %+v", i, got)
+			}
+			continue
+		}
+		if got == nil || got.Purpose != purpose || !slices.ContainsFunc(tier, func(q Question) bool { return q.ID == got.QuestionID }) {
+			t.Fatalf("case %d: expected a %s question from %+v, got %+v", i, purpose, tier, got)
+		}
+	}
+}
+
+func TestWeightedSelectorIsReproducible(t *testing.T) {
+	now := time.Now()
+	in := rankedInput(rand.New(rand.NewPCG(9, 10)), now)
+
+	draw := func() []int64 {
+		selector := NewWeightedSelector(rand.NewPCG(42, 42))
+		var picks []int64
+		for i := 0; i < 50; i++ {
+			picks = append(picks, selector.Select(in).QuestionID)
+		}
+		return picks
+	}
+
+	first := draw()
+	if !slices.Equal(first, draw()) {
+		t.Fatal("expected the same seed to give the same picks")
+	}
+
+	seen := make(map[int64]bool)
+	for _, id := range first {
+		seen[id] = true
+	}
+	if _, tier := (Tiers{}).eligible(in); len(tier) > 1 && len(seen) < 2 {
+		t.Fatalf("expected picks to vary across a tier of %d, got %v", len(tier), first)
+	}
+}
+
+// pickShare runs selector on in and returns how often questionID won.
+func pickShare(selector Selector, in SelectionInput, questionID int64) float64 {
+	const draws = 4000
+
+	won := 0
+	for i := 0; i < draws; i++ {
+		if selector.Select(in).QuestionID == questionID {
+			won++
+		}
+	}
+	return float64(won) / draws
+}
+
+func TestWeightedSelectorWeights(t *testing.T) {
+	now := time.Now()
+
+	t.Run("staleness", func(t *testing.T) {
+		in := SelectionInput{
+			Now:       now,
+			Progress:  []TopicProgress{{TopicID: "articles", Mastery: 50}},
+			Questions: []Question{{ID: 1, TopicID: "articles", Difficulty: 2}, {ID: 2, TopicID: "articles", Difficulty: 2}},
+			LastShown: map[int64]time.Time{1: now},
+		}
+
+		// Weights 1 and 1 + 0.25*28 = 8.
+		share := pickShare(NewWeightedSelector(rand.NewPCG(1, 2)), in, 2)
+		if share < 0.85 || share > 0.93 {
+			t.Fatalf("expected the never-shown question about 8/9 of the time, got %.2f", share)
+		}
+	})
+
+	t.Run("weakness", func(t *testing.T) {
+		in := SelectionInput{
+			Now: now,
+			Progress: []TopicProgress{
+				{TopicID: "articles", Mastery: 0},
+				{TopicID: "idioms", Mastery: 100},
+			},
+			RecentWrongTopics: map[string]bool{"articles": true, "idioms": true},
+			Questions:         []Question{{ID: 1, TopicID: "articles", Difficulty: 1}, {ID: 2, TopicID: "idioms", Difficulty: 1}},
+		}
+
+		// Weights 3 and 1.
+		share := pickShare(NewWeightedSelector(rand.NewPCG(3, 4)), in, 1)
+		if share < 0.70 || share > 0.80 {
+			t.Fatalf("expected the weaker topic about 3/4 of the time, got %.2f", share)
+		}
+	})
+
+	t.Run("overdue", func(t *testing.T) {
+		in := SelectionInput{
+			Now: now,
+			Progress: []TopicProgress{
+				{TopicID: "articles", Mastery: 50},
+				{TopicID: "idioms", Mastery: 50},
+			},
+			Reviews: []ReviewItem{
+				{TopicID: "articles", NextReviewAt: now.Add(-6 * 24 * time.Hour)},
+				{TopicID: "idioms", NextReviewAt: now},
+			},
+			Questions: []Question{{ID: 1, TopicID: "articles", Difficulty: 2}, {ID: 2, TopicID: "idioms", Difficulty: 2}},
+		}
+
+		// Weights 1 + 0.5*6 = 4 and 1.
+		share := pickShare(NewWeightedSelector(rand.NewPCG(5, 6)), in, 1)
+		if share < 0.75 || share > 0.85 {
+			t.Fatalf("expected the overdue review about 4/5 of the time, got %.2f", share)
+		}
+	})
+}
+
+func TestSessionUsesSelector(t *testing.T) {
+	now := time.Now()
+
+	questions := []Question{
+		{ID: 1, TopicID: "articles", Difficulty: 2},
+		{ID: 2, TopicID: "articles", Difficulty: 2},
+		{ID: 3, TopicID: "articles", Difficulty: 2},
+	}
+	progress := []TopicProgress{{TopicID: "articles", Mastery: 50}}
+
+	seen := make(map[int64]bool)
+	for i := uint64(0); i < 20; i++ {
+		session := NewSession(questions, progress, nil)
+		session.Selector = NewWeightedSelector(rand.NewPCG(i, i))
+		seen[session.NextQuestion(now).QuestionID] = true
+	}
+	if len(seen) < 2 {
+		t.Fatalf("expected sessions to open on different questions, got %v", seen)
+	}
+
+	// Without a selector, sessions keep the rule-based order.
+	if got := NewSession(questions, progress, nil).NextQuestion(now); got.QuestionID != 1 {
+		t.Fatalf("expected question 1, got %+v", got)
+	}
+}
@@ -1,25 +1,76 @@
 package quiz
 
 import (
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-// Session represents one quiz run (in-memory for now).
+// Session represents one quiz run.
+//
+// Its methods are safe for concurrent use; answers to the same
+// session are applied one at a time.
 type Session struct {
+	mu sync.Mutex
+
 	ID         string
+	UserID     uint64 // owner; 0 for sessions built outside a request
 	StartedAt time.Time
 
 	Progress map[string]TopicProgress // topic_id -> progress
 	Reviews  []ReviewItem
-	Questions []Question
+
+	// Questions is the pool the session serves from. Only QuestionIDs
+	// is stored; Restore rebuilds Questions after loading.
+	Questions   []Question `json:"-"`
+	QuestionIDs []int64
 
 	RecentWrongTopics map[string]bool
 	AskedQuestions    map[int64]bool
+	ServedQuestions   map[int64]bool
+	ServedPurposes    map[int64]QuestionPurpose
+
+	// ServedContent is each served question as the learner saw it, so
+	// answers are graded against that even if the bank is edited
+	// meanwhile. Unlike Questions it is stored.
+	ServedContent map[int64]Question
+
+	// Curriculum, if set, breaks ties between equally weak topics and
+	// keeps locked ones out of selection.
+	Curriculum *Curriculum
+
+	// LastShown is when each question was last answered, across
+	// sessions; see SelectionInput. It is rebuilt from the answer
+	// history rather than stored.
+	LastShown map[int64]time.Time `json:"-"`
+
+	// Selector picks questions; nil means RuleSelector. It is not
+	// stored with the session, so set it again after loading one.
+	// Variant names the experiment variant it came from, if any, and
+	// is recorded on every question served.
+	Selector Selector `json:"-"`
+	Variant  string
+
+	// Pedagogy is the config answers are scored and questions selected
+	// with; nil means DefaultPedagogy. Like Selector it is not stored.
+	Pedagogy *PedagogyConfig `json:"-"`
+
+	// Estimator scores answers; nil means Pedagogy's MasteryConfig.
+	// Like Selector it is not stored.
+	Estimator MasteryEstimator `json:"-"`
+
+	// SkipReviews keeps due reviews out of selection, e.g. once the
+	// learner's review quota is used up. Like Selector it is not stored.
+	SkipReviews bool `json:"-"`
+
+	// Finished is set once no question is left to serve.
+	Finished bool
 }
 
-// Answer represents a user answer submission.
+// Answer represents a graded answer, with topic and difficulty taken
+// from the question itself.
 type Answer struct {
 	QuestionID int64
 	TopicID    string
@@ -38,20 +89,98 @@ func NewSession(
 		progressMap[p.TopicID] = p
 	}
 
+	questionIDs := make([]int64, 0, len(questions))
+	for _, q := range questions {
+		questionIDs = append(questionIDs, q.ID)
+	}
+
 	return &Session{
 		ID:                uuid.NewString(),
 		StartedAt:         time.Now(),
 		Progress:          progressMap,
 		Reviews:           reviews,
 		Questions:         questions,
+		QuestionIDs:       questionIDs,
 		RecentWrongTopics: make(map[string]bool),
 		AskedQuestions:    make(map[int64]bool),
+		ServedQuestions:   make(map[int64]bool),
+		ServedPurposes:    make(map[int64]QuestionPurpose),
+		ServedContent:     make(map[int64]Question),
 	}
 }
 
+// Restore sets Questions after loading a stored session: the
+// questions of bank that are in QuestionIDs, in their original order.
+// Questions no longer in bank, e.g. retired ones, are left out.
+func (s *Session) Restore(bank []Question) {
+	byID := make(map[int64]Question, len(bank))
+	for _, q := range bank {
+		byID[q.ID] = q
+	}
+
+	s.Questions = make([]Question, 0, len(s.QuestionIDs))
+	for _, id := range s.QuestionIDs {
+		if q, ok := byID[id]; ok {
+			s.Questions = append(s.Questions, q)
+		}
+	}
+}
+
+// Sync replaces the session's progress and reviews with the stored
+// ones, so answers given in other sessions since it started are built
+// on rather than overwritten. Topics never stored keep their state.
+func (s *Session) Sync(progress []TopicProgress, reviews []ReviewItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range progress {
+		s.Progress[p.TopicID] = p
+	}
+	for _, r := range reviews {
+		if i := s.reviewIndex(r.TopicID); i >= 0 {
+			s.Reviews[i] = r
+		} else {
+			s.Reviews = append(s.Reviews, r)
+		}
+	}
+}
+
+// ServedQuestion returns question id as this session served it.
+func (s *Session) ServedQuestion(id int64) (Question, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.ServedContent[id]
+	return q, ok
+}
+
+// GradedAnswer is the outcome of SubmitServedAnswer.
+type GradedAnswer struct {
+	WasCorrect bool
+	Mastery    MasteryUpdateResult
+	Next       *SelectedQuestion
+
+	// Progress and Review are the answered topic's state after the
+	// update, ready to be persisted.
+	Progress TopicProgress
+	Review   ReviewItem
+}
+
+// NextQuestion selects the next question and records it as served.
 func (s *Session) NextQuestion(now time.Time) *SelectedQuestion {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.nextQuestion(now)
+}
+
+func (s *Session) nextQuestion(now time.Time) *SelectedQuestion {
+	// Select on mastery as it stands now, so topics left alone long
+	// enough fall back to easier tiers. Stored progress is unchanged.
+	mastery := s.pedagogy().Mastery
 	progressList := make([]TopicProgress, 0, len(s.Progress))
 	for _, p := range s.Progress {
+		p.Mastery = mastery.ProjectedMastery(p, now)
 		progressList = append(progressList, p)
 	}
 
@@ -63,28 +192,154 @@ func (s *Session) NextQuestion(now time.Time) *SelectedQuestion {
 		}
 	}
 
-	return SelectNextQuestion(
+	// Map iteration order is random; rank before anything else. The
+	// curriculum only breaks ties and locks topics; it never puts a
+	// stronger topic ahead of a weaker one.
+	if s.Curriculum != nil {
+		progressList = s.Curriculum.Rank(progressList)
+		available = s.Curriculum.Gate(progressList, available, s.pedagogy().Curriculum.UnlockMastery)
+	} else {
+		progressList = RankProgress(progressList)
+	}
+
+	var selector Selector = RuleSelector{}
+	if s.Selector != nil {
+		selector = s.Selector
+	}
+
+	var reviews []ReviewItem
+	if !s.SkipReviews {
+		reviews = RankReviews(s.Reviews)
+	}
+
+	selected := selector.Select(SelectionInput{
+		Now:               now,
+		Progress:          progressList,
+		Reviews:           reviews,
+		Questions:         available,
+		RecentWrongTopics: s.RecentWrongTopics,
+		LastShown:         s.LastShown,
+		Tiers:             s.pedagogy().Selection,
+	})
+
+	if selected != nil {
+		// Sessions stored before served tracking existed have no map yet.
+		if s.ServedQuestions == nil {
+			s.ServedQuestions = make(map[int64]bool)
+		}
+		if s.ServedPurposes == nil {
+			s.ServedPurposes = make(map[int64]QuestionPurpose)
+		}
+		if s.ServedContent == nil {
+			s.ServedContent = make(map[int64]Question)
+		}
+		selected.Variant = s.Variant
+		s.ServedQuestions[selected.QuestionID] = true
+		s.ServedPurposes[selected.QuestionID] = selected.Purpose
+		for _, q := range available {
+			if q.ID == selected.QuestionID {
+				s.ServedContent[q.ID] = q
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// SubmitServedAnswer grades selectedOption against q and applies it.
+//
+// q must be a question this session served and has not seen answered
+// yet. Topic and difficulty are taken from q, never from the client.
+func (s *Session) SubmitServedAnswer(
+	q Question,
+	selectedOption string,
+	now time.Time,
+) (GradedAnswer, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkAnswer(q, selectedOption); err != nil {
+		return GradedAnswer{}, err
+	}
+
+	wasCorrect := selectedOption == q.CorrectAnswer
+
+	next, update := s.submitAnswer(
+		Answer{
+			QuestionID: q.ID,
+			TopicID:    q.TopicID,
+			WasCorrect: wasCorrect,
+			Difficulty: q.Difficulty,
+		},
 		now,
-		progressList,
-		s.Reviews,
-		available,
-		s.RecentWrongTopics,
 	)
+
+	return GradedAnswer{
+		WasCorrect: wasCorrect,
+		Mastery:    update,
+		Next:       next,
+		Progress:   s.Progress[q.TopicID],
+		Review:     s.Reviews[s.reviewIndex(q.TopicID)],
+	}, nil
 }
 
+// CheckAnswer reports the error SubmitServedAnswer would return,
+// without changing the session. On success it returns the purpose q
+// was served with.
+func (s *Session) CheckAnswer(q Question, selectedOption string) (QuestionPurpose, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkAnswer(q, selectedOption); err != nil {
+		return "", err
+	}
+	return s.ServedPurposes[q.ID], nil
+}
+
+func (s *Session) checkAnswer(q Question, selectedOption string) error {
+	if s.AskedQuestions[q.ID] {
+		return ErrAlreadyAnswered
+	}
+	if s.Finished {
+		return ErrSessionFinished
+	}
+	if !s.ServedQuestions[q.ID] {
+		return ErrQuestionNotServed
+	}
+	if !slices.Contains(q.Options, selectedOption) {
+		return ErrInvalidOption
+	}
+	return nil
+}
+
+// SubmitAnswer applies an already graded answer. Client submissions
+// go through SubmitServedAnswer instead.
 func (s *Session) SubmitAnswer(
 	answer Answer,
 	now time.Time,
 ) (*SelectedQuestion, MasteryUpdateResult) {
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.submitAnswer(answer, now)
+}
+
+func (s *Session) submitAnswer(
+	answer Answer,
+	now time.Time,
+) (*SelectedQuestion, MasteryUpdateResult) {
+
 	// Mark question as asked
 	s.AskedQuestions[answer.QuestionID] = true
 
 	current := s.Progress[answer.TopicID]
 
-	update := UpdateMastery(
+	update := s.estimator().Update(
 		current,
 		MasteryUpdateInput{
+			QuestionID:  answer.QuestionID,
 			WasCorrect:  answer.WasCorrect,
 			Difficulty:  answer.Difficulty,
 			AnsweredAt:  now,
@@ -101,10 +356,51 @@ func (s *Session) SubmitAnswer(
 		LastSeen:      update.LastSeen,
 	}
 
+	s.scheduleReview(answer, now)
+
 	if !answer.WasCorrect {
 		s.RecentWrongTopics[answer.TopicID] = true
 	}
 
-	next := s.NextQuestion(now)
+	next := s.nextQuestion(now)
+	if next == nil {
+		s.Finished = true
+	}
 	return next, update
 }
+
+// scheduleReview reschedules the answered topic's review, creating it
+// on the first answer.
+func (s *Session) scheduleReview(answer Answer, now time.Time) {
+	i := s.reviewIndex(answer.TopicID)
+	if i < 0 {
+		s.Reviews = append(s.Reviews, ReviewItem{TopicID: answer.TopicID})
+		i = len(s.Reviews) - 1
+	}
+
+	s.Reviews[i] = ScheduleReview(s.Reviews[i], answer.WasCorrect, answer.Difficulty, now)
+}
+
+func (s *Session) reviewIndex(topicID string) int {
+	for i, r := range s.Reviews {
+		if r.TopicID == topicID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Session) pedagogy() *PedagogyConfig {
+	if s.Pedagogy == nil {
+		cfg := DefaultPedagogy()
+		return &cfg
+	}
+	return s.Pedagogy
+}
+
+func (s *Session) estimator() MasteryEstimator {
+	if s.Estimator == nil {
+		return s.pedagogy().Mastery
+	}
+	return s.Estimator
+}
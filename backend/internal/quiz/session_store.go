@@ -0,0 +1,225 @@
+package quiz
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bugii1995/backend/internal/storage"
+)
+
+// SessionStore keeps quiz sessions between requests.
+//
+// Sessions expire after a period of inactivity; Put and Touch both
+// restart that period. Expired sessions are reported as not found.
+type SessionStore interface {
+	Get(ctx context.Context, id string) (*Session, error)
+	Put(ctx context.Context, s *Session) error
+	Delete(ctx context.Context, id string) error
+	Touch(ctx context.Context, id string) error
+}
+
+// Sweeper is implemented by stores that can drop expired sessions.
+type Sweeper interface {
+	Sweep(ctx context.Context) error
+}
+
+// SweepEvery calls s.Sweep on every tick until ctx is done.
+func SweepEvery(ctx context.Context, s Sweeper, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Sweep(ctx); err != nil {
+				log.Printf("quiz: sweep sessions: %v", err)
+			}
+		}
+	}
+}
+
+// ---------------- In-memory ----------------
+
+type memorySessionEntry struct {
+	session   *Session
+	expiresAt time.Time
+}
+
+// MemorySessionStore is a process-local SessionStore with TTL eviction.
+type MemorySessionStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]memorySessionEntry
+
+	now func() time.Time
+}
+
+func NewMemorySessionStore(ttl time.Duration) *MemorySessionStore {
+	return &MemorySessionStore{
+		ttl:     ttl,
+		entries: make(map[string]memorySessionEntry),
+		now:     time.Now,
+	}
+}
+
+func (s *MemorySessionStore) Get(ctx context.Context, id string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[id]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	if !s.now().Before(entry.expiresAt) {
+		delete(s.entries, id)
+		return nil, ErrSessionNotFound
+	}
+	return entry.session, nil
+}
+
+func (s *MemorySessionStore) Put(ctx context.Context, session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[session.ID] = memorySessionEntry{
+		session:   session,
+		expiresAt: s.now().Add(s.ttl),
+	}
+	return nil
+}
+
+func (s *MemorySessionStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, id)
+	return nil
+}
+
+func (s *MemorySessionStore) Touch(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[id]
+	if !ok || !s.now().Before(entry.expiresAt) {
+		return ErrSessionNotFound
+	}
+	entry.expiresAt = s.now().Add(s.ttl)
+	s.entries[id] = entry
+	return nil
+}
+
+func (s *MemorySessionStore) Sweep(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for id, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, id)
+		}
+	}
+	return nil
+}
+
+// ---------------- SQLite ----------------
+
+var sessionMigrations = []string{
+	`CREATE TABLE quiz_sessions (
+		id         TEXT    PRIMARY KEY,
+		data       TEXT    NOT NULL, -- JSON-encoded Session
+		expires_at INTEGER NOT NULL  -- unix nanoseconds
+	);
+	CREATE INDEX quiz_sessions_expires_at ON quiz_sessions (expires_at);`,
+}
+
+// SQLiteSessionStore persists sessions so they survive restarts.
+type SQLiteSessionStore struct {
+	db  *sql.DB
+	ttl time.Duration
+
+	now func() time.Time
+}
+
+func NewSQLiteSessionStore(db *sql.DB, ttl time.Duration) (*SQLiteSessionStore, error) {
+	if err := storage.Migrate(db, "quiz_sessions", sessionMigrations); err != nil {
+		return nil, err
+	}
+	return &SQLiteSessionStore{db: db, ttl: ttl, now: time.Now}, nil
+}
+
+func (s *SQLiteSessionStore) Get(ctx context.Context, id string) (*Session, error) {
+	var data string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT data FROM quiz_sessions WHERE id = ? AND expires_at > ?`,
+		id, s.now().UnixNano(),
+	).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var session Session
+	if err := json.Unmarshal([]byte(data), &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (s *SQLiteSessionStore) Put(ctx context.Context, session *Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO quiz_sessions (id, data, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			data       = excluded.data,
+			expires_at = excluded.expires_at`,
+		session.ID, string(data), s.now().Add(s.ttl).UnixNano(),
+	)
+	return err
+}
+
+func (s *SQLiteSessionStore) Delete(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM quiz_sessions WHERE id = ?`, id)
+	return err
+}
+
+func (s *SQLiteSessionStore) Touch(ctx context.Context, id string) error {
+	now := s.now()
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE quiz_sessions SET expires_at = ? WHERE id = ? AND expires_at > ?`,
+		now.Add(s.ttl).UnixNano(), id, now.UnixNano(),
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteSessionStore) Sweep(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx,
+		`DELETE FROM quiz_sessions WHERE expires_at <= ?`,
+		s.now().UnixNano(),
+	)
+	return err
+}
@@ -0,0 +1,312 @@
+package quiz
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bugii1995/backend/internal/storage"
+)
+
+// fakeClock is a manually advanced clock for TTL tests.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) Now() time.Time          { return c.t }
+func (c *fakeClock) Advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func TestMemorySessionStore(t *testing.T) {
+	testSessionStoreContract(t, func(t *testing.T, clock *fakeClock) SessionStore {
+		store := NewMemorySessionStore(time.Hour)
+		store.now = clock.Now
+		return store
+	})
+}
+
+func TestSQLiteSessionStore(t *testing.T) {
+	testSessionStoreContract(t, func(t *testing.T, clock *fakeClock) SessionStore {
+		db, err := storage.Open(":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		store, err := NewSQLiteSessionStore(db, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		store.now = clock.Now
+		return store
+	})
+}
+
+func testSessionStoreContract(t *testing.T, newStore func(t *testing.T, clock *fakeClock) SessionStore) {
+	ctx := context.Background()
+
+	newSession := func() *Session {
+		return NewSession(
+			[]Question{{ID: 1, TopicID: "articles", Difficulty: 2, Options: []string{"a", "an"}}},
+			[]TopicProgress{{TopicID: "articles", Mastery: 40}},
+			nil,
+		)
+	}
+
+	t.Run("PutGet", func(t *testing.T) {
+		clock := &fakeClock{t: time.Now()}
+		store := newStore(t, clock)
+
+		s := newSession()
+		if err := store.Put(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := store.Get(ctx, s.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != s.ID {
+			t.Fatalf("expected session %s, got %s", s.ID, got.ID)
+		}
+	})
+
+	t.Run("GetMissing", func(t *testing.T) {
+		store := newStore(t, &fakeClock{t: time.Now()})
+
+		if _, err := store.Get(ctx, "missing"); !errors.Is(err, ErrSessionNotFound) {
+			t.Fatalf("expected ErrSessionNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		store := newStore(t, &fakeClock{t: time.Now()})
+
+		s := newSession()
+		store.Put(ctx, s)
+		if err := store.Delete(ctx, s.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := store.Get(ctx, s.ID); !errors.Is(err, ErrSessionNotFound) {
+			t.Fatalf("expected ErrSessionNotFound after delete, got %v", err)
+		}
+	})
+
+	t.Run("ExpiresAfterTTL", func(t *testing.T) {
+		clock := &fakeClock{t: time.Now()}
+		store := newStore(t, clock)
+
+		s := newSession()
+		store.Put(ctx, s)
+		clock.Advance(time.Hour)
+
+		if _, err := store.Get(ctx, s.ID); !errors.Is(err, ErrSessionNotFound) {
+			t.Fatalf("expected expired session, got %v", err)
+		}
+	})
+
+	t.Run("TouchExtendsTTL", func(t *testing.T) {
+		clock := &fakeClock{t: time.Now()}
+		store := newStore(t, clock)
+
+		s := newSession()
+		store.Put(ctx, s)
+
+		clock.Advance(50 * time.Minute)
+		if err := store.Touch(ctx, s.ID); err != nil {
+			t.Fatal(err)
+		}
+		clock.Advance(50 * time.Minute)
+
+		if _, err := store.Get(ctx, s.ID); err != nil {
+			t.Fatalf("expected touched session to be alive, got %v", err)
+		}
+	})
+
+	t.Run("TouchMissing", func(t *testing.T) {
+		store := newStore(t, &fakeClock{t: time.Now()})
+
+		if err := store.Touch(ctx, "missing"); !errors.Is(err, ErrSessionNotFound) {
+			t.Fatalf("expected ErrSessionNotFound, got %v", err)
+		}
+	})
+
+	t.Run("SweepDropsExpired", func(t *testing.T) {
+		clock := &fakeClock{t: time.Now()}
+		store := newStore(t, clock)
+
+		s := newSession()
+		store.Put(ctx, s)
+		clock.Advance(2 * time.Hour)
+
+		if err := store.(Sweeper).Sweep(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		// Rewinding the clock shows the entry is really gone.
+		clock.Advance(-2 * time.Hour)
+		if _, err := store.Get(ctx, s.ID); !errors.Is(err, ErrSessionNotFound) {
+			t.Fatalf("expected swept session to be gone, got %v", err)
+		}
+	})
+
+	t.Run("KeepsSessionState", func(t *testing.T) {
+		now := time.Now()
+		clock := &fakeClock{t: now}
+		store := newStore(t, clock)
+
+		s := newSession()
+		s.Reviews = []ReviewItem{{TopicID: "articles", NextReviewAt: now.Add(time.Hour)}}
+		s.SubmitAnswer(Answer{QuestionID: 1, TopicID: "articles", WasCorrect: false, Difficulty: 2}, now)
+		if err := store.Put(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := store.Get(ctx, s.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertSameSessionState(t, s, got)
+	})
+}
+
+func TestSQLiteSessionStoreSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "bonfire.db")
+	now := time.Now()
+
+	db, err := storage.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewSQLiteSessionStore(db, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewSession(
+		[]Question{
+			{ID: 1, TopicID: "articles", Difficulty: 2},
+			{ID: 2, TopicID: "articles", Difficulty: 1},
+		},
+		[]TopicProgress{{TopicID: "articles", Mastery: 50, LastSeen: now}},
+		[]ReviewItem{{TopicID: "articles", NextReviewAt: now.Add(24 * time.Hour)}},
+	)
+	s.SubmitAnswer(Answer{QuestionID: 1, TopicID: "articles", WasCorrect: false, Difficulty: 2}, now)
+
+	if err := store.Put(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	// "Restart": reopen the same file with a fresh store.
+	db, err = storage.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	store, err = NewSQLiteSessionStore(db, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.Get(ctx, s.ID)
+	if err != nil {
+		t.Fatalf("expected session to survive restart: %v", err)
+	}
+	assertSameSessionState(t, s, got)
+
+	// The restored session keeps working once its questions are back.
+	got.Restore(s.Questions)
+	next, _ := got.SubmitAnswer(Answer{QuestionID: 2, TopicID: "articles", WasCorrect: true, Difficulty: 1}, now)
+	if next != nil {
+		t.Fatalf("expected no questions left, got %+v", next)
+	}
+}
+
+func assertSameSessionState(t *testing.T, want, got *Session) {
+	t.Helper()
+
+	if got.ID != want.ID || !got.StartedAt.Equal(want.StartedAt) {
+		t.Fatalf("session identity changed: %+v", got)
+	}
+
+	if len(got.Progress) != len(want.Progress) {
+		t.Fatalf("expected %d progress entries, got %d", len(want.Progress), len(got.Progress))
+	}
+	for topic, p := range want.Progress {
+		g := got.Progress[topic]
+		if g.Mastery != p.Mastery || g.WrongStreak != p.WrongStreak ||
+			g.CorrectStreak != p.CorrectStreak || !g.LastSeen.Equal(p.LastSeen) {
+			t.Fatalf("progress for %s changed: want %+v, got %+v", topic, p, g)
+		}
+	}
+
+	if len(got.Reviews) != len(want.Reviews) {
+		t.Fatalf("expected %d reviews, got %d", len(want.Reviews), len(got.Reviews))
+	}
+	for i := range want.Reviews {
+		if !got.Reviews[i].NextReviewAt.Equal(want.Reviews[i].NextReviewAt) {
+			t.Fatalf("review %d changed", i)
+		}
+	}
+
+	if !slices.Equal(got.QuestionIDs, want.QuestionIDs) {
+		t.Fatalf("expected questions %v, got %v", want.QuestionIDs, got.QuestionIDs)
+	}
+
+	for id := range want.AskedQuestions {
+		if !got.AskedQuestions[id] {
+			t.Fatalf("expected question %d to be marked asked", id)
+		}
+	}
+	for topic := range want.RecentWrongTopics {
+		if !got.RecentWrongTopics[topic] {
+			t.Fatalf("expected topic %s to be marked recently wrong", topic)
+		}
+	}
+}
+
+func TestSQLiteSessionStoreStoresQuestionIDs(t *testing.T) {
+	ctx := context.Background()
+	db, err := storage.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	store, err := NewSQLiteSessionStore(db, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewSession(
+		[]Question{{ID: 4, TopicID: "articles", Difficulty: 2, Prompt: "Choose the article: ___ hour"}},
+		[]TopicProgress{{TopicID: "articles", Mastery: 50}},
+		nil,
+	)
+	s.LastShown = map[int64]time.Time{4: time.Now()}
+	if err := store.Put(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+
+	var data string
+	if err := db.QueryRow(`SELECT data FROM quiz_sessions WHERE id = ?`, s.ID).Scan(&data); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(data, "___ hour") || strings.Contains(data, "LastShown") {
+		t.Fatalf("expected only question IDs stored, got %s", data)
+	}
+
+	got, err := store.Get(ctx, s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Questions) != 0 || !slices.Equal(got.QuestionIDs, []int64{4}) {
+		t.Fatalf("expected question IDs to restore from, got %+v", got)
+	}
+}
@@ -1,6 +1,7 @@
 package quiz
 
 import (
+	"sync"
 	"testing"
 	"time"
 )
@@ -111,3 +112,148 @@ func TestSubmitAnswerTracksWrongTopics(t *testing.T) {
 		t.Fatal("expected topic to be marked as recently wrong")
 	}
 }
+
+func TestSubmitAnswerConcurrent(t *testing.T) {
+	now := time.Now()
+	const answers = 50
+
+	newSession := func() *Session {
+		questions := make([]Question, answers)
+		for i := range questions {
+			questions[i] = Question{ID: int64(i + 1), TopicID: "articles", Difficulty: 2}
+		}
+		return NewSession(
+			questions,
+			[]TopicProgress{{TopicID: "articles", Mastery: 10, LastSeen: now}},
+			nil,
+		)
+	}
+
+	answer := func(id int) Answer {
+		return Answer{QuestionID: int64(id), TopicID: "articles", WasCorrect: true, Difficulty: 2}
+	}
+
+	// Identical correct answers give the same result in any order,
+	// so a sequential run is the reference.
+	want := newSession()
+	for i := 1; i <= answers; i++ {
+		want.SubmitAnswer(answer(i), now)
+	}
+
+	got := newSession()
+	var wg sync.WaitGroup
+	for i := 1; i <= answers; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			got.SubmitAnswer(answer(id), now)
+			got.NextQuestion(now)
+		}(i)
+	}
+	wg.Wait()
+
+	p, w := got.Progress["articles"], want.Progress["articles"]
+	if p.CorrectStreak != answers {
+		t.Fatalf("expected correct streak %d, got %d", answers, p.CorrectStreak)
+	}
+	if p.Mastery != w.Mastery {
+		t.Fatalf("expected mastery %v, got %v", w.Mastery, p.Mastery)
+	}
+	if len(got.AskedQuestions) != answers {
+		t.Fatalf("expected %d asked questions, got %d", answers, len(got.AskedQuestions))
+	}
+}
+
+func TestSessionSelectsOnProjectedMastery(t *testing.T) {
+	lastSeen := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		mastery float64
+		elapsed time.Duration
+		want    QuestionPurpose
+	}{
+		// 81 decays 0.3 a day after DecayAfterDays.
+		{"strong within grace", 81, DecayAfterDays * day, PurposeStretch},
+		{"strong just past grace", 81, (DecayAfterDays + 3) * day, PurposeStretch},
+		{"strong decayed to progress", 81, (DecayAfterDays+3)*day + 12*time.Hour, PurposeProgress},
+
+		// 48 is 41.1 at DecayHeavyThreshold, then loses 0.7 a day.
+		{"middling at heavy threshold", 48, DecayHeavyThreshold * day, PurposeProgress},
+		{"middling a day past threshold", 48, (DecayHeavyThreshold + 1) * day, PurposeProgress},
+		{"middling decayed to reinforce", 48, (DecayHeavyThreshold + 2) * day, PurposeReinforce},
+
+		{"strong after sixty days", 85, 60 * day, PurposeProgress},
+	}
+
+	questions := []Question{
+		{ID: 1, TopicID: "articles", Difficulty: 1},
+		{ID: 2, TopicID: "articles", Difficulty: 2},
+		{ID: 3, TopicID: "articles", Difficulty: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := NewSession(questions, []TopicProgress{{TopicID: "articles", Mastery: tt.mastery, LastSeen: lastSeen}}, nil)
+
+			clock := lastSeen.Add(tt.elapsed)
+			got := session.NextQuestion(clock)
+			if got == nil || got.Purpose != tt.want {
+				t.Fatalf("at %v: expected %s, got %+v", tt.elapsed, tt.want, got)
+			}
+			if session.Progress["articles"].Mastery != tt.mastery {
+				t.Fatalf("expected stored mastery %v to stay, got %v", tt.mastery, session.Progress["articles"].Mastery)
+			}
+		})
+	}
+}
+
+func TestSessionRanksOnProjectedMastery(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	// Stored, articles is the weaker topic; projected to now, the long
+	// unpractised conditionals are.
+	session := NewSession(
+		[]Question{
+			{ID: 1, TopicID: "articles", Difficulty: 2},
+			{ID: 2, TopicID: "conditionals", Difficulty: 2},
+		},
+		[]TopicProgress{
+			{TopicID: "articles", Mastery: 50, LastSeen: now.Add(-day)},
+			{TopicID: "conditionals", Mastery: 60, LastSeen: now.Add(-45 * day)},
+		},
+		nil,
+	)
+
+	if got := session.NextQuestion(now); got == nil || got.QuestionID != 2 {
+		t.Fatalf("expected the decayed topic first, got %+v", got)
+	}
+}
+
+func TestSessionRestore(t *testing.T) {
+	session := NewSession(
+		[]Question{
+			{ID: 3, TopicID: "articles", Difficulty: 2},
+			{ID: 1, TopicID: "articles", Difficulty: 1},
+			{ID: 2, TopicID: "articles", Difficulty: 3},
+		},
+		[]TopicProgress{{TopicID: "articles", Mastery: 50}},
+		nil,
+	)
+	session.Questions = nil
+
+	// Question 2 was retired meanwhile; question 5 was never part of
+	// the session.
+	session.Restore([]Question{
+		{ID: 1, TopicID: "articles", Difficulty: 1, Prompt: "edited"},
+		{ID: 3, TopicID: "articles", Difficulty: 2},
+		{ID: 5, TopicID: "articles", Difficulty: 2},
+	})
+
+	if len(session.Questions) != 2 || session.Questions[0].ID != 3 || session.Questions[1].ID != 1 {
+		t.Fatalf("expected questions 3 and 1 in session order, got %+v", session.Questions)
+	}
+	if session.Questions[1].Prompt != "edited" {
+		t.Fatalf("expected the bank's current question, got %+v", session.Questions[1])
+	}
+}
@@ -0,0 +1,313 @@
+package quiz
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+//
+// -------- Topics --------
+//
+
+// CEFRLevel is a Common European Framework level, A1 (beginner) to C2.
+type CEFRLevel string
+
+const (
+	LevelA1 CEFRLevel = "A1"
+	LevelA2 CEFRLevel = "A2"
+	LevelB1 CEFRLevel = "B1"
+	LevelB2 CEFRLevel = "B2"
+	LevelC1 CEFRLevel = "C1"
+	LevelC2 CEFRLevel = "C2"
+)
+
+var cefrLevels = []CEFRLevel{LevelA1, LevelA2, LevelB1, LevelB2, LevelC1, LevelC2}
+
+// Topic is one grammar point of the curriculum. Prerequisites are the
+// IDs of topics a learner must work up before this one unlocks.
+type Topic struct {
+	ID            string
+	Name          string
+	Description   string
+	CEFRLevel     CEFRLevel
+	Prerequisites []string
+}
+
+// DefaultUnlockMastery is the mastery every prerequisite of a topic
+// needs before the topic unlocks: past the middle of "progress". It is
+// the default of CurriculumConfig.UnlockMastery.
+const DefaultUnlockMastery = 60.0
+
+//
+// -------- Catalog --------
+//
+
+// TopicCatalog is a validated set of topics: IDs are unique, every
+// prerequisite exists and the prerequisite graph has no cycles. It
+// implements TopicLookup.
+type TopicCatalog struct {
+	topics map[string]Topic
+	order  []string
+}
+
+// NewTopicCatalog validates topics and orders them into a curriculum.
+func NewTopicCatalog(topics ...Topic) (*TopicCatalog, error) {
+	byID := make(map[string]Topic, len(topics))
+	for _, t := range topics {
+		if strings.TrimSpace(t.ID) == "" {
+			return nil, fmt.Errorf("%w: topic without an ID", ErrInvalidTopic)
+		}
+		if _, ok := byID[t.ID]; ok {
+			return nil, fmt.Errorf("%w: %s is listed twice", ErrInvalidTopic, t.ID)
+		}
+		if !slices.Contains(cefrLevels, t.CEFRLevel) {
+			return nil, fmt.Errorf("%w: %s has CEFR level %q", ErrInvalidTopic, t.ID, t.CEFRLevel)
+		}
+		byID[t.ID] = t
+	}
+
+	for _, t := range topics {
+		for _, p := range t.Prerequisites {
+			if _, ok := byID[p]; !ok {
+				return nil, fmt.Errorf("%w: %s requires unknown topic %s", ErrInvalidTopic, t.ID, p)
+			}
+		}
+	}
+
+	if cycle := findCycle(byID); cycle != nil {
+		return nil, fmt.Errorf("%w: %s", ErrTopicCycle, strings.Join(cycle, " -> "))
+	}
+
+	return &TopicCatalog{
+		topics: byID,
+		order:  curriculumOrder(byID),
+	}, nil
+}
+
+func (c *TopicCatalog) TopicExists(ctx context.Context, topicID string) (bool, error) {
+	_, ok := c.topics[topicID]
+	return ok, nil
+}
+
+// Get returns the topic with the given ID.
+func (c *TopicCatalog) Get(topicID string) (Topic, bool) {
+	t, ok := c.topics[topicID]
+	return t, ok
+}
+
+// Topics returns every topic in curriculum order.
+func (c *TopicCatalog) Topics() []Topic {
+	out := make([]Topic, 0, len(c.order))
+	for _, id := range c.order {
+		out = append(out, c.topics[id])
+	}
+	return out
+}
+
+// Curriculum is the catalog's ordering and gating, for a Session.
+//
+// Prerequisites that practicable reports false for, e.g. topics without
+// questions yet, are left out: no learner could work them up, so they
+// would lock their dependents for good. A nil practicable keeps all.
+func (c *TopicCatalog) Curriculum(practicable func(topicID string) bool) *Curriculum {
+	prerequisites := make(map[string][]string)
+	for id, t := range c.topics {
+		var kept []string
+		for _, p := range t.Prerequisites {
+			if practicable == nil || practicable(p) {
+				kept = append(kept, p)
+			}
+		}
+		if len(kept) > 0 {
+			prerequisites[id] = kept
+		}
+	}
+	return &Curriculum{
+		Order:         slices.Clone(c.order),
+		Prerequisites: prerequisites,
+	}
+}
+
+//
+// -------- Curriculum --------
+//
+
+// Curriculum breaks ties between a session's topics and keeps locked
+// ones out of selection. It is plain data so it is stored with the
+// session; the unlock threshold comes from the pedagogy config of each
+// request.
+type Curriculum struct {
+	Order         []string            // topic IDs, prerequisites first
+	Prerequisites map[string][]string // topic_id -> prerequisite topic IDs
+
+	// Exempt topics are never locked: the learner was assigned them or
+	// asked for them.
+	Exempt []string
+}
+
+// Unlocked reports whether a learner with the given progress may be
+// quizzed on topicID: every prerequisite has reached unlockMastery.
+// A topic the learner has already answered stays unlocked, so a
+// prerequisite decaying does not take away its reviews.
+func (c *Curriculum) Unlocked(topicID string, progress map[string]TopicProgress, unlockMastery float64) bool {
+	if !progress[topicID].LastSeen.IsZero() || slices.Contains(c.Exempt, topicID) {
+		return true
+	}
+	for _, p := range c.Prerequisites[topicID] {
+		if progress[p].Mastery < unlockMastery {
+			return false
+		}
+	}
+	return true
+}
+
+// Rank is RankProgress with the curriculum breaking mastery ties:
+// among topics equally in need of practice, earlier ones in the
+// curriculum come first, and topics it does not know come last.
+// Mastery always comes first, so the weakest topic is still practised
+// first.
+func (c *Curriculum) Rank(progress []TopicProgress) []TopicProgress {
+	position := make(map[string]int, len(c.Order))
+	for i, id := range c.Order {
+		position[id] = i
+	}
+
+	ranked := RankProgress(progress)
+	slices.SortStableFunc(ranked, func(a, b TopicProgress) int {
+		if a.Mastery != b.Mastery {
+			return cmp.Compare(a.Mastery, b.Mastery)
+		}
+		pa, aKnown := position[a.TopicID]
+		pb, bKnown := position[b.TopicID]
+		if aKnown != bKnown {
+			if aKnown {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(pa, pb)
+	})
+	return ranked
+}
+
+// Gate returns the questions whose topics are unlocked for a learner
+// with the given progress. Topics the curriculum does not know are
+// never locked.
+func (c *Curriculum) Gate(progress []TopicProgress, questions []Question, unlockMastery float64) []Question {
+	byTopic := make(map[string]TopicProgress, len(progress))
+	for _, p := range progress {
+		byTopic[p.TopicID] = p
+	}
+
+	unlocked := make([]Question, 0, len(questions))
+	for _, q := range questions {
+		if c.Unlocked(q.TopicID, byTopic, unlockMastery) {
+			unlocked = append(unlocked, q)
+		}
+	}
+	return unlocked
+}
+
+//
+// -------- Internal helpers --------
+//
+
+// findCycle returns a prerequisite cycle as a path of topic IDs that
+// starts and ends on the same topic, or nil.
+func findCycle(topics map[string]Topic) []string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(topics))
+	var path []string
+
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		state[id] = visiting
+		path = append(path, id)
+		for _, p := range topics[id].Prerequisites {
+			switch state[p] {
+			case visiting:
+				start := slices.Index(path, p)
+				return append(slices.Clone(path[start:]), p)
+			case unvisited:
+				if cycle := visit(p); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = done
+		return nil
+	}
+
+	for _, id := range sortedTopicIDs(topics) {
+		if state[id] == unvisited {
+			if cycle := visit(id); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// curriculumOrder sorts topics so prerequisites come first. Among
+// topics that are ready at the same time, lower CEFR levels come
+// first, then IDs. topics must be acyclic.
+func curriculumOrder(topics map[string]Topic) []string {
+	waiting := make(map[string]int, len(topics))
+	unlocks := make(map[string][]string)
+	for id, t := range topics {
+		waiting[id] = len(t.Prerequisites)
+		for _, p := range t.Prerequisites {
+			unlocks[p] = append(unlocks[p], id)
+		}
+	}
+
+	before := func(a, b string) bool {
+		la := slices.Index(cefrLevels, topics[a].CEFRLevel)
+		lb := slices.Index(cefrLevels, topics[b].CEFRLevel)
+		if la != lb {
+			return la < lb
+		}
+		return a < b
+	}
+
+	var ready []string
+	for _, id := range sortedTopicIDs(topics) {
+		if waiting[id] == 0 {
+			ready = append(ready, id)
+		}
+	}
+
+	order := make([]string, 0, len(topics))
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool { return before(ready[i], ready[j]) })
+		id := ready[0]
+		ready = ready[1:]
+		order = append(order, id)
+
+		for _, next := range unlocks[id] {
+			waiting[next]--
+			if waiting[next] == 0 {
+				ready = append(ready, next)
+			}
+		}
+	}
+	return order
+}
+
+func sortedTopicIDs(topics map[string]Topic) []string {
+	ids := make([]string, 0, len(topics))
+	for id := range topics {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
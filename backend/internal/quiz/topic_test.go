@@ -0,0 +1,271 @@
+package quiz
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func topicIDs(topics []Topic) []string {
+	ids := make([]string, 0, len(topics))
+	for _, t := range topics {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
+
+func TestNewTopicCatalogRejectsBadTopics(t *testing.T) {
+	tests := []struct {
+		name   string
+		topics []Topic
+		want   error
+	}{
+		{"missing ID", []Topic{{CEFRLevel: LevelA1}}, ErrInvalidTopic},
+		{"duplicate ID", []Topic{{ID: "articles", CEFRLevel: LevelA1}, {ID: "articles", CEFRLevel: LevelA2}}, ErrInvalidTopic},
+		{"bad level", []Topic{{ID: "articles", CEFRLevel: "D1"}}, ErrInvalidTopic},
+		{"unknown prerequisite", []Topic{{ID: "articles", CEFRLevel: LevelA1, Prerequisites: []string{"nouns"}}}, ErrInvalidTopic},
+		{"self prerequisite", []Topic{{ID: "articles", CEFRLevel: LevelA1, Prerequisites: []string{"articles"}}}, ErrTopicCycle},
+		{"cycle", []Topic{
+			{ID: "a", CEFRLevel: LevelA1, Prerequisites: []string{"c"}},
+			{ID: "b", CEFRLevel: LevelA1, Prerequisites: []string{"a"}},
+			{ID: "c", CEFRLevel: LevelA1, Prerequisites: []string{"b"}},
+			{ID: "d", CEFRLevel: LevelA1},
+		}, ErrTopicCycle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTopicCatalog(tt.topics...); !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestNewTopicCatalogNamesTheCycle(t *testing.T) {
+	_, err := NewTopicCatalog(
+		Topic{ID: "a", CEFRLevel: LevelA1, Prerequisites: []string{"b"}},
+		Topic{ID: "b", CEFRLevel: LevelA1, Prerequisites: []string{"a"}},
+	)
+	if err == nil || !strings.Contains(err.Error(), "a -> b -> a") {
+		t.Fatalf("expected the cycle in the error, got %v", err)
+	}
+}
+
+func TestTopicCatalogCurriculumOrder(t *testing.T) {
+	catalog, err := NewTopicCatalog(DefaultTopics()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"articles", "present_simple", "present_continuous", "past_simple", "conditionals"}
+	if got := topicIDs(catalog.Topics()); !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	// A lower level goes first once both are ready, whatever the IDs.
+	catalog, err = NewTopicCatalog(
+		Topic{ID: "a_hard", CEFRLevel: LevelB2},
+		Topic{ID: "z_easy", CEFRLevel: LevelA1},
+		Topic{ID: "m_next", CEFRLevel: LevelA1, Prerequisites: []string{"a_hard"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"z_easy", "a_hard", "m_next"}
+	if got := topicIDs(catalog.Topics()); !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestTopicCatalogIsATopicLookup(t *testing.T) {
+	catalog, err := NewTopicCatalog(DefaultTopics()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ValidateQuestion(context.Background(), Question{
+		TopicID:       "past_simple",
+		Difficulty:    1,
+		Prompt:        "Yesterday I ___ to school.",
+		Options:       []string{"go", "went"},
+		CorrectAnswer: "went",
+		Status:        StatusDraft,
+	}, catalog); err != nil {
+		t.Fatalf("expected a catalog topic to be accepted, got %v", err)
+	}
+
+	if ok, _ := catalog.TopicExists(context.Background(), "idioms"); ok {
+		t.Fatal("expected an unknown topic to be reported missing")
+	}
+}
+
+func TestCurriculumUnlocksAtThreshold(t *testing.T) {
+	c := &Curriculum{
+		Prerequisites: map[string][]string{"conditionals": {"present_simple", "past_simple"}},
+	}
+
+	progress := map[string]TopicProgress{
+		"present_simple": {TopicID: "present_simple", Mastery: 75},
+		"past_simple":    {TopicID: "past_simple", Mastery: 59.9},
+	}
+	if c.Unlocked("conditionals", progress, 60) {
+		t.Fatal("expected conditionals to stay locked below the threshold")
+	}
+
+	progress["past_simple"] = TopicProgress{TopicID: "past_simple", Mastery: 60}
+	if !c.Unlocked("conditionals", progress, 60) {
+		t.Fatal("expected conditionals to unlock at the threshold")
+	}
+
+	// Once started, a topic stays open even if a prerequisite slips.
+	progress["past_simple"] = TopicProgress{TopicID: "past_simple", Mastery: 30}
+	progress["conditionals"] = TopicProgress{TopicID: "conditionals", Mastery: 40, LastSeen: time.Now()}
+	if !c.Unlocked("conditionals", progress, 60) {
+		t.Fatal("expected a started topic to stay unlocked")
+	}
+
+	if !c.Unlocked("articles", progress, 60) {
+		t.Fatal("expected a topic without prerequisites to be unlocked")
+	}
+}
+
+func TestCurriculumSkipsImpracticablePrerequisites(t *testing.T) {
+	catalog, err := NewTopicCatalog(
+		Topic{ID: "present_simple", CEFRLevel: LevelA1},
+		Topic{ID: "articles", CEFRLevel: LevelA1},
+		Topic{ID: "conditionals", CEFRLevel: LevelB1, Prerequisites: []string{"present_simple", "articles"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// present_simple has no questions, so only articles gates.
+	c := catalog.Curriculum(func(topicID string) bool { return topicID == "articles" })
+	if got := c.Prerequisites["conditionals"]; !slices.Equal(got, []string{"articles"}) {
+		t.Fatalf("expected only articles to gate, got %v", got)
+	}
+	progress := map[string]TopicProgress{"articles": {TopicID: "articles", Mastery: 70}}
+	if !c.Unlocked("conditionals", progress, DefaultUnlockMastery) {
+		t.Fatal("expected conditionals unlocked without present_simple")
+	}
+
+	progress["articles"] = TopicProgress{TopicID: "articles", Mastery: 30}
+	if c.Unlocked("conditionals", progress, DefaultUnlockMastery) {
+		t.Fatal("expected articles still to gate")
+	}
+	c.Exempt = []string{"conditionals"}
+	if !c.Unlocked("conditionals", progress, DefaultUnlockMastery) {
+		t.Fatal("expected an exempt topic to be unlocked")
+	}
+}
+
+func TestSessionUnlocksAtConfiguredMastery(t *testing.T) {
+	session := NewSession(
+		[]Question{{ID: 1, TopicID: "past_simple", Difficulty: 2}},
+		[]TopicProgress{{TopicID: "present_simple", Mastery: 50}, {TopicID: "past_simple", Mastery: 50}},
+		nil,
+	)
+	session.Curriculum = &Curriculum{
+		Order:         []string{"present_simple", "past_simple"},
+		Prerequisites: map[string][]string{"past_simple": {"present_simple"}},
+	}
+
+	cfg := DefaultPedagogy()
+	cfg.Curriculum.UnlockMastery = 50
+	session.Pedagogy = &cfg
+	if got := session.NextQuestion(time.Now()); got == nil || got.QuestionID != 1 {
+		t.Fatalf("expected past_simple unlocked at 50, got %+v", got)
+	}
+}
+
+func TestSessionFollowsCurriculum(t *testing.T) {
+	catalog, err := NewTopicCatalog(
+		Topic{ID: "present_simple", CEFRLevel: LevelA1},
+		Topic{ID: "past_simple", CEFRLevel: LevelA2, Prerequisites: []string{"present_simple"}},
+		Topic{ID: "idioms", CEFRLevel: LevelB1},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	questions := []Question{
+		{ID: 1, TopicID: "past_simple", Difficulty: 2},
+		{ID: 2, TopicID: "idioms", Difficulty: 2},
+		{ID: 3, TopicID: "present_simple", Difficulty: 2},
+		{ID: 4, TopicID: "present_simple", Difficulty: 2},
+	}
+	progress := []TopicProgress{
+		{TopicID: "idioms", Mastery: 50},
+		{TopicID: "past_simple", Mastery: 50},
+		{TopicID: "present_simple", Mastery: 50},
+	}
+
+	now := time.Now()
+	session := NewSession(questions, progress, nil)
+	session.Curriculum = catalog.Curriculum(nil)
+
+	// Every topic needs the same practice; the curriculum breaks the
+	// tie in favour of the A1 topic and keeps past_simple locked.
+	first := session.NextQuestion(now)
+	if first == nil || first.QuestionID != 3 {
+		t.Fatalf("expected present_simple first, got %+v", first)
+	}
+
+	// A wrong answer leaves present_simple weakest, so it continues.
+	next, _ := session.SubmitAnswer(Answer{QuestionID: 3, TopicID: "present_simple", WasCorrect: false, Difficulty: 2}, now)
+	if next == nil || next.QuestionID != 4 {
+		t.Fatalf("expected present_simple to continue, got %+v", next)
+	}
+}
+
+func TestSessionRanksBeforeCurriculum(t *testing.T) {
+	catalog, err := NewTopicCatalog(
+		Topic{ID: "articles", CEFRLevel: LevelA1},
+		Topic{ID: "present_simple", CEFRLevel: LevelA1},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order := catalog.Curriculum(nil).Order; order[0] != "articles" {
+		t.Fatalf("expected articles first in the curriculum, got %v", order)
+	}
+
+	questions := []Question{
+		{ID: 1, TopicID: "articles", Difficulty: 2},
+		{ID: 2, TopicID: "present_simple", Difficulty: 1},
+	}
+	progress := []TopicProgress{
+		{TopicID: "articles", Mastery: 35},
+		{TopicID: "present_simple", Mastery: 5},
+	}
+
+	session := NewSession(questions, progress, nil)
+	session.Curriculum = catalog.Curriculum(nil)
+
+	// The curriculum lists articles first, but present_simple needs
+	// practice most.
+	first := session.NextQuestion(time.Now())
+	if first == nil || first.QuestionID != 2 {
+		t.Fatalf("expected the weaker present_simple first, got %+v", first)
+	}
+}
+
+func TestSessionCurriculumLocksEverything(t *testing.T) {
+	session := NewSession(
+		[]Question{{ID: 1, TopicID: "past_simple", Difficulty: 2}},
+		[]TopicProgress{{TopicID: "past_simple", Mastery: 50}},
+		nil,
+	)
+	session.Curriculum = &Curriculum{
+		Order:         []string{"present_simple", "past_simple"},
+		Prerequisites: map[string][]string{"past_simple": {"present_simple"}},
+	}
+
+	if got := session.NextQuestion(time.Now()); got != nil {
+		t.Fatalf("expected no question while past_simple is locked, got %+v", got)
+	}
+}
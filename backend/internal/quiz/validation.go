@@ -0,0 +1,87 @@
+package quiz
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/bugii1995/backend/internal/httperr"
+)
+
+// TopicLookup tells whether a topic exists, for validating questions.
+type TopicLookup interface {
+	TopicExists(ctx context.Context, topicID string) (bool, error)
+}
+
+// TopicSet is a fixed TopicLookup.
+type TopicSet map[string]bool
+
+func NewTopicSet(topicIDs ...string) TopicSet {
+	s := make(TopicSet, len(topicIDs))
+	for _, id := range topicIDs {
+		s[id] = true
+	}
+	return s
+}
+
+func (s TopicSet) TopicExists(ctx context.Context, topicID string) (bool, error) {
+	return s[topicID], nil
+}
+
+// ValidateQuestion checks q before it is saved. Problems are returned
+// as ErrInvalidQuestion with a field -> message map as details.
+func ValidateQuestion(ctx context.Context, q Question, topics TopicLookup) error {
+	problems := make(map[string]string)
+
+	if strings.TrimSpace(q.Prompt) == "" {
+		problems["prompt"] = "is required"
+	}
+
+	if q.Difficulty < 1 || q.Difficulty > 3 {
+		problems["difficulty"] = "must be 1 (easy), 2 (medium) or 3 (hard)"
+	}
+
+	switch q.Status {
+	case StatusDraft, StatusPublished, StatusRetired:
+	default:
+		problems["status"] = "must be draft, published or retired"
+	}
+
+	if len(q.Options) < 2 {
+		problems["options"] = "needs at least two options"
+	}
+	seen := make(map[string]bool, len(q.Options))
+	for _, o := range q.Options {
+		key := strings.TrimSpace(o)
+		if key == "" {
+			problems["options"] = "must not be blank"
+			break
+		}
+		if seen[key] {
+			problems["options"] = "must not repeat: " + key
+			break
+		}
+		seen[key] = true
+	}
+
+	if !slices.Contains(q.Options, q.CorrectAnswer) {
+		problems["correct_answer"] = "must be one of the options"
+	}
+
+	if strings.TrimSpace(q.TopicID) == "" {
+		problems["topic_id"] = "is required"
+	} else {
+		exists, err := topics.TopicExists(ctx, q.TopicID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			problems["topic_id"] = "unknown topic: " + q.TopicID
+		}
+	}
+
+	if len(problems) > 0 {
+		return httperr.WithDetails(ErrInvalidQuestion, problems)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package quiz
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bugii1995/backend/internal/httperr"
+)
+
+func validQuestion() Question {
+	return Question{
+		TopicID:       "articles",
+		Difficulty:    2,
+		Prompt:        "Choose the correct article: ___ hour",
+		Options:       []string{"a", "an", "the"},
+		CorrectAnswer: "an",
+		Status:        StatusDraft,
+	}
+}
+
+func TestValidateQuestion(t *testing.T) {
+	topics := NewTopicSet("articles")
+
+	tests := []struct {
+		name   string
+		edit   func(q *Question)
+		fields []string
+	}{
+		{"valid", func(q *Question) {}, nil},
+		{"missing prompt", func(q *Question) { q.Prompt = "  " }, []string{"prompt"}},
+		{"difficulty too low", func(q *Question) { q.Difficulty = 0 }, []string{"difficulty"}},
+		{"difficulty too high", func(q *Question) { q.Difficulty = 4 }, []string{"difficulty"}},
+		{"unknown status", func(q *Question) { q.Status = "archived" }, []string{"status"}},
+		{"one option", func(q *Question) { q.Options = []string{"an"} }, []string{"options"}},
+		{"repeated option", func(q *Question) { q.Options = []string{"a", "an", "an "} }, []string{"options"}},
+		{"blank option", func(q *Question) { q.Options = []string{"a", "an", ""} }, []string{"options"}},
+		{"answer not an option", func(q *Question) { q.CorrectAnswer = "An" }, []string{"correct_answer"}},
+		{"unknown topic", func(q *Question) { q.TopicID = "idioms" }, []string{"topic_id"}},
+		{"missing topic", func(q *Question) { q.TopicID = "" }, []string{"topic_id"}},
+		{"several problems", func(q *Question) { q.Difficulty = 9; q.TopicID = "idioms" }, []string{"difficulty", "topic_id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := validQuestion()
+			tt.edit(&q)
+
+			err := ValidateQuestion(context.Background(), q, topics)
+			if tt.fields == nil {
+				if err != nil {
+					t.Fatalf("expected valid, got %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrInvalidQuestion) {
+				t.Fatalf("expected ErrInvalidQuestion, got %v", err)
+			}
+			_, body := httperr.Resolve(err, HTTPErrors)
+			problems, _ := body.Details.(map[string]string)
+			if len(problems) != len(tt.fields) {
+				t.Fatalf("expected problems with %v, got %v", tt.fields, problems)
+			}
+			for _, field := range tt.fields {
+				if problems[field] == "" {
+					t.Fatalf("expected a problem with %s, got %v", field, problems)
+				}
+			}
+		})
+	}
+}
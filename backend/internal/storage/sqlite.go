@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Open opens (or creates) the SQLite database at path.
+//
+// Use ":memory:" for a throwaway database in tests.
+func Open(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", path+"?_foreign_keys=on&_busy_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows a single writer; one connection also keeps
+	// ":memory:" databases from being split across connections.
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Migrate applies the not-yet-applied steps of a component's schema.
+//
+// Steps are identified by their index, so existing steps must never be
+// edited or reordered — append new ones instead.
+func Migrate(db *sql.DB, component string, steps []string) error {
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			component TEXT    NOT NULL,
+			version   INTEGER NOT NULL,
+			PRIMARY KEY (component, version)
+		)`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	var applied int
+	if err := db.QueryRow(
+		`SELECT COUNT(*) FROM schema_migrations WHERE component = ?`,
+		component,
+	).Scan(&applied); err != nil {
+		return fmt.Errorf("read %s migrations: %w", component, err)
+	}
+
+	for version := applied; version < len(steps); version++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(steps[version]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migrate %s to v%d: %w", component, version+1, err)
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO schema_migrations (component, version) VALUES (?, ?)`,
+			component, version,
+		); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsUniqueViolation reports whether err is a UNIQUE constraint failure.
+func IsUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) &&
+		(sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique ||
+			sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey)
+}
@@ -0,0 +1,29 @@
+package storage
+
+import "testing"
+
+func TestMigrateAppliesNewStepsOnly(t *testing.T) {
+	db, err := Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	steps := []string{`CREATE TABLE things (id INTEGER PRIMARY KEY)`}
+	if err := Migrate(db, "things", steps); err != nil {
+		t.Fatal(err)
+	}
+
+	// Re-running must not recreate the table.
+	steps = append(steps, `ALTER TABLE things ADD COLUMN name TEXT`)
+	if err := Migrate(db, "things", steps); err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(db, "things", steps); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.Exec(`INSERT INTO things (id, name) VALUES (1, 'x')`); err != nil {
+		t.Fatalf("expected name column to exist: %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package users
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const MinPasswordLength = 8
+
+var (
+	ErrWeakPassword    = errors.New("password must be at least 8 characters")
+	ErrPasswordTooLong = errors.New("password must be at most 72 bytes")
+)
+
+// HashPassword returns a bcrypt hash suitable for User.PasswordHash.
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", ErrWeakPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return "", ErrPasswordTooLong
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether password matches hash. Users without
+// a password (empty hash) never match.
+func CheckPassword(hash, password string) bool {
+	if hash == "" {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
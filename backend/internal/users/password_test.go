@@ -0,0 +1,39 @@
+package users
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHashPasswordRoundTrip(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hash == "correct horse" {
+		t.Fatal("expected password to be hashed")
+	}
+	if !CheckPassword(hash, "correct horse") {
+		t.Fatal("expected password to match")
+	}
+	if CheckPassword(hash, "wrong horse") {
+		t.Fatal("expected wrong password to fail")
+	}
+}
+
+func TestHashPasswordLimits(t *testing.T) {
+	if _, err := HashPassword("short"); !errors.Is(err, ErrWeakPassword) {
+		t.Fatalf("expected ErrWeakPassword, got %v", err)
+	}
+	if _, err := HashPassword(strings.Repeat("x", 73)); !errors.Is(err, ErrPasswordTooLong) {
+		t.Fatalf("expected ErrPasswordTooLong, got %v", err)
+	}
+}
+
+func TestCheckPasswordWithoutHash(t *testing.T) {
+	if CheckPassword("", "") {
+		t.Fatal("accounts without a password must never match")
+	}
+}
@@ -0,0 +1,207 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bugii1995/backend/internal/storage"
+)
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrPhoneTaken   = errors.New("phone number already registered")
+)
+
+// UserRepository stores user accounts.
+type UserRepository interface {
+	// Create assigns the ID and timestamps and returns the stored user.
+	Create(ctx context.Context, u User) (User, error)
+	GetByID(ctx context.Context, id uint64) (User, error)
+	GetByPhone(ctx context.Context, phone string) (User, error)
+	// Update replaces everything but ID and CreatedAt.
+	Update(ctx context.Context, u User) (User, error)
+}
+
+// ---------------- In-memory ----------------
+
+type MemoryUserRepository struct {
+	mu      sync.RWMutex
+	users   map[uint64]User
+	byPhone map[string]uint64
+	nextID  uint64
+}
+
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{
+		users:   make(map[uint64]User),
+		byPhone: make(map[string]uint64),
+		nextID:  1,
+	}
+}
+
+func (r *MemoryUserRepository) Create(ctx context.Context, u User) (User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, taken := r.byPhone[u.PhoneNumber]; taken {
+		return User{}, ErrPhoneTaken
+	}
+
+	now := time.Now().Unix()
+	u.ID = r.nextID
+	u.CreatedAt = now
+	u.UpdatedAt = now
+	r.nextID++
+
+	r.users[u.ID] = u
+	r.byPhone[u.PhoneNumber] = u.ID
+	return u, nil
+}
+
+func (r *MemoryUserRepository) GetByID(ctx context.Context, id uint64) (User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.users[id]
+	if !ok {
+		return User{}, ErrUserNotFound
+	}
+	return u, nil
+}
+
+func (r *MemoryUserRepository) GetByPhone(ctx context.Context, phone string) (User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.byPhone[phone]
+	if !ok {
+		return User{}, ErrUserNotFound
+	}
+	return r.users[id], nil
+}
+
+func (r *MemoryUserRepository) Update(ctx context.Context, u User) (User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.users[u.ID]
+	if !ok {
+		return User{}, ErrUserNotFound
+	}
+	if owner, taken := r.byPhone[u.PhoneNumber]; taken && owner != u.ID {
+		return User{}, ErrPhoneTaken
+	}
+
+	delete(r.byPhone, current.PhoneNumber)
+	u.CreatedAt = current.CreatedAt
+	u.UpdatedAt = time.Now().Unix()
+
+	r.users[u.ID] = u
+	r.byPhone[u.PhoneNumber] = u.ID
+	return u, nil
+}
+
+// ---------------- SQLite ----------------
+
+var userMigrations = []string{
+	`CREATE TABLE users (
+		id            INTEGER PRIMARY KEY AUTOINCREMENT,
+		phone_number  TEXT    NOT NULL UNIQUE,
+		password_hash TEXT    NOT NULL DEFAULT '',
+		account_type  TEXT    NOT NULL,
+		role          TEXT    NOT NULL,
+		permission    TEXT    NOT NULL,
+		created_at    INTEGER NOT NULL,
+		updated_at    INTEGER NOT NULL
+	);`,
+}
+
+type SQLiteUserRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteUserRepository(db *sql.DB) (*SQLiteUserRepository, error) {
+	if err := storage.Migrate(db, "users", userMigrations); err != nil {
+		return nil, err
+	}
+	return &SQLiteUserRepository{db: db}, nil
+}
+
+const userColumns = `id, phone_number, password_hash, account_type, role, permission, created_at, updated_at`
+
+func (r *SQLiteUserRepository) Create(ctx context.Context, u User) (User, error) {
+	now := time.Now().Unix()
+	u.CreatedAt = now
+	u.UpdatedAt = now
+
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO users (phone_number, password_hash, account_type, role, permission, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		u.PhoneNumber, u.PasswordHash, u.AccountType, u.Role, u.Permission, u.CreatedAt, u.UpdatedAt,
+	)
+	if storage.IsUniqueViolation(err) {
+		return User{}, ErrPhoneTaken
+	}
+	if err != nil {
+		return User{}, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return User{}, err
+	}
+	u.ID = uint64(id)
+	return u, nil
+}
+
+func (r *SQLiteUserRepository) GetByID(ctx context.Context, id uint64) (User, error) {
+	return r.get(ctx, `SELECT `+userColumns+` FROM users WHERE id = ?`, id)
+}
+
+func (r *SQLiteUserRepository) GetByPhone(ctx context.Context, phone string) (User, error) {
+	return r.get(ctx, `SELECT `+userColumns+` FROM users WHERE phone_number = ?`, phone)
+}
+
+func (r *SQLiteUserRepository) Update(ctx context.Context, u User) (User, error) {
+	current, err := r.GetByID(ctx, u.ID)
+	if err != nil {
+		return User{}, err
+	}
+	u.CreatedAt = current.CreatedAt
+	u.UpdatedAt = time.Now().Unix()
+
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE users SET
+			phone_number  = ?,
+			password_hash = ?,
+			account_type  = ?,
+			role          = ?,
+			permission    = ?,
+			updated_at    = ?
+		WHERE id = ?`,
+		u.PhoneNumber, u.PasswordHash, u.AccountType, u.Role, u.Permission, u.UpdatedAt, u.ID,
+	)
+	if storage.IsUniqueViolation(err) {
+		return User{}, ErrPhoneTaken
+	}
+	if err != nil {
+		return User{}, err
+	}
+	return u, nil
+}
+
+func (r *SQLiteUserRepository) get(ctx context.Context, query string, arg any) (User, error) {
+	var u User
+	err := r.db.QueryRowContext(ctx, query, arg).Scan(
+		&u.ID, &u.PhoneNumber, &u.PasswordHash,
+		&u.AccountType, &u.Role, &u.Permission,
+		&u.CreatedAt, &u.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return User{}, ErrUserNotFound
+	}
+	return u, err
+}
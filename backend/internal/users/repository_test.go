@@ -0,0 +1,143 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bugii1995/backend/internal/storage"
+)
+
+func TestMemoryUserRepository(t *testing.T) {
+	testUserRepositoryContract(t, func(t *testing.T) UserRepository {
+		return NewMemoryUserRepository()
+	})
+}
+
+func TestSQLiteUserRepository(t *testing.T) {
+	testUserRepositoryContract(t, func(t *testing.T) UserRepository {
+		db, err := storage.Open(":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		repo, err := NewSQLiteUserRepository(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return repo
+	})
+}
+
+func testUserRepositoryContract(t *testing.T, newRepo func(t *testing.T) UserRepository) {
+	ctx := context.Background()
+
+	t.Run("CreateAssignsID", func(t *testing.T) {
+		repo := newRepo(t)
+
+		a, err := repo.Create(ctx, NewUser("+97688110001"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := repo.Create(ctx, NewUser("+97688110002"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if a.ID == 0 || b.ID == 0 || a.ID == b.ID {
+			t.Fatalf("expected distinct IDs, got %d and %d", a.ID, b.ID)
+		}
+		if a.CreatedAt == 0 || a.UpdatedAt == 0 {
+			t.Fatal("expected timestamps to be set")
+		}
+	})
+
+	t.Run("CreateRejectsDuplicatePhone", func(t *testing.T) {
+		repo := newRepo(t)
+
+		repo.Create(ctx, NewUser("+97688110001"))
+		if _, err := repo.Create(ctx, NewUser("+97688110001")); !errors.Is(err, ErrPhoneTaken) {
+			t.Fatalf("expected ErrPhoneTaken, got %v", err)
+		}
+	})
+
+	t.Run("GetByIDAndPhone", func(t *testing.T) {
+		repo := newRepo(t)
+
+		u := NewUser("+97688110001")
+		u.PasswordHash = "hash"
+		u.Role = RoleTeacher
+		created, _ := repo.Create(ctx, u)
+
+		byID, err := repo.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		byPhone, err := repo.GetByPhone(ctx, "+97688110001")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, got := range []User{byID, byPhone} {
+			if got != created {
+				t.Fatalf("expected %+v, got %+v", created, got)
+			}
+		}
+	})
+
+	t.Run("GetMissing", func(t *testing.T) {
+		repo := newRepo(t)
+
+		if _, err := repo.GetByID(ctx, 99); !errors.Is(err, ErrUserNotFound) {
+			t.Fatalf("expected ErrUserNotFound, got %v", err)
+		}
+		if _, err := repo.GetByPhone(ctx, "+97600000000"); !errors.Is(err, ErrUserNotFound) {
+			t.Fatalf("expected ErrUserNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repo := newRepo(t)
+
+		created, _ := repo.Create(ctx, NewUser("+97688110001"))
+		created.AccountType = AccountPaid
+		created.PhoneNumber = "+97688110009"
+
+		updated, err := repo.Update(ctx, created)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.CreatedAt != created.CreatedAt {
+			t.Fatal("expected CreatedAt to be kept")
+		}
+
+		got, _ := repo.GetByPhone(ctx, "+97688110009")
+		if got.ID != created.ID || got.AccountType != AccountPaid {
+			t.Fatalf("unexpected user after update: %+v", got)
+		}
+		if _, err := repo.GetByPhone(ctx, "+97688110001"); !errors.Is(err, ErrUserNotFound) {
+			t.Fatalf("expected old phone to be released, got %v", err)
+		}
+	})
+
+	t.Run("UpdateRejectsTakenPhone", func(t *testing.T) {
+		repo := newRepo(t)
+
+		repo.Create(ctx, NewUser("+97688110001"))
+		b, _ := repo.Create(ctx, NewUser("+97688110002"))
+		b.PhoneNumber = "+97688110001"
+
+		if _, err := repo.Update(ctx, b); !errors.Is(err, ErrPhoneTaken) {
+			t.Fatalf("expected ErrPhoneTaken, got %v", err)
+		}
+	})
+
+	t.Run("UpdateMissing", func(t *testing.T) {
+		repo := newRepo(t)
+
+		if _, err := repo.Update(ctx, User{ID: 99}); !errors.Is(err, ErrUserNotFound) {
+			t.Fatalf("expected ErrUserNotFound, got %v", err)
+		}
+	})
+}
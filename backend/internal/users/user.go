@@ -1,6 +1,9 @@
 package users
 
-import "regexp"
+import (
+	"errors"
+	"regexp"
+)
 
 // ---------- Enums ----------
 
@@ -38,8 +41,20 @@ type User struct {
 	UpdatedAt int64 `json:"updated_at"`
 }
 
+// NewUser returns a new free student account for phone.
+func NewUser(phone string) User {
+	return User{
+		PhoneNumber: phone,
+		AccountType: AccountFree,
+		Role:        RoleStudent,
+		Permission:  PermUser,
+	}
+}
+
 // ---------- Validation ----------
 
+var ErrInvalidPhone = errors.New("phone number must look like +976XXXXXXXX")
+
 var mongoliaPhoneRegex = regexp.MustCompile(`^\+976\d{8}$`)
 
 func IsValidMongoliaPhone(phone string) bool {
@@ -1,21 +1,259 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log"
+	mathrand "math/rand/v2"
+	"os"
+	"time"
+
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 
+	"github.com/bugii1995/backend/internal/auth"
+	"github.com/bugii1995/backend/internal/classroom"
+	"github.com/bugii1995/backend/internal/entitlements"
+	"github.com/bugii1995/backend/internal/httperr"
 	"github.com/bugii1995/backend/internal/quiz"
+	"github.com/bugii1995/backend/internal/storage"
+	"github.com/bugii1995/backend/internal/users"
 )
 
 func main() {
+	dbPath := os.Getenv("BONFIRE_DB")
+	if dbPath == "" {
+		dbPath = "bonfire.db"
+	}
+
+	db, err := storage.Open(dbPath)
+	if err != nil {
+		log.Fatalf("open database: %v", err)
+	}
+	defer db.Close()
+
+	questions, err := quiz.NewSQLiteQuestionRepository(db)
+	if err != nil {
+		log.Fatalf("question repository: %v", err)
+	}
+	if err := quiz.SeedQuestions(context.Background(), questions, quiz.DefaultQuestions()); err != nil {
+		log.Fatalf("seed questions: %v", err)
+	}
+
+	topics, err := quiz.NewTopicCatalog(quiz.DefaultTopics()...)
+	if err != nil {
+		log.Fatalf("topic catalog: %v", err)
+	}
+
+	// BONFIRE_PEDAGOGY is a YAML or JSON file; edits are picked up
+	// without a restart.
+	pedagogy, err := quiz.OpenPedagogy(os.Getenv("BONFIRE_PEDAGOGY"))
+	if err != nil {
+		log.Fatalf("pedagogy config: %v", err)
+	}
+	go quiz.ReloadEvery(context.Background(), pedagogy, 30*time.Second)
+
+	sessions, err := quiz.NewSQLiteSessionStore(db, 24*time.Hour)
+	if err != nil {
+		log.Fatalf("session store: %v", err)
+	}
+	go quiz.SweepEvery(context.Background(), sessions, time.Hour)
+
+	progress, err := quiz.NewSQLiteProgressRepository(db)
+	if err != nil {
+		log.Fatalf("progress repository: %v", err)
+	}
+	// Answers are counted as they are saved; the daily recount from the
+	// answer history corrects any drift.
+	go quiz.RecalibrateEvery(context.Background(), progress, 24*time.Hour)
+
+	// BONFIRE_ESTIMATOR picks how answers are scored; see
+	// masteryEstimator.
+	ratings, err := quiz.NewSQLiteQuestionRatingRepository(db)
+	if err != nil {
+		log.Fatalf("question rating repository: %v", err)
+	}
+	estimator, err := masteryEstimator(os.Getenv("BONFIRE_ESTIMATOR"), pedagogy, ratings)
+	if err != nil {
+		log.Fatalf("mastery estimator: %v", err)
+	}
+
+	exposures, err := quiz.NewSQLiteExposureRepository(db)
+	if err != nil {
+		log.Fatalf("exposure repository: %v", err)
+	}
+
+	quotas, err := entitlements.NewSQLiteQuotaRepository(db)
+	if err != nil {
+		log.Fatalf("quota repository: %v", err)
+	}
+
+	userRepo, err := users.NewSQLiteUserRepository(db)
+	if err != nil {
+		log.Fatalf("user repository: %v", err)
+	}
+
+	secret := tokenSecret()
+	tokens := auth.NewTokenIssuer(secret, 30*24*time.Hour)
+	requireUser := auth.RequireUser(tokens, userRepo)
+
+	authHandler := &auth.Handler{
+		Users:  userRepo,
+		Tokens: tokens,
+	}
+	if sms, ok := smsSender(); ok {
+		authHandler.OTP = auth.NewOTPService(auth.NewMemoryOTPStore(), sms, secret)
+	}
+
+	classrooms := classroom.NewService(
+		classroom.NewMemoryClassroomRepository(),
+		classroom.NewMemoryAssignmentRepository(),
+		userRepo,
+		progress,
+		topics,
+		pedagogy,
+	)
+	classroomHandler := &classroom.Handler{Classrooms: classrooms}
+
+	// Half of the learners get randomized selection, to compare
+	// outcomes with the rule-based order.
+	selection, err := quiz.NewExperiment("selection",
+		quiz.Variant{Name: "rules", Buckets: 50, Selector: quiz.RuleSelector{}},
+		quiz.Variant{Name: "weighted", Buckets: 50, Selector: quiz.NewWeightedSelector(mathrand.NewPCG(mathrand.Uint64(), mathrand.Uint64()))},
+	)
+	if err != nil {
+		log.Fatalf("selection experiment: %v", err)
+	}
+
+	quizHandler := &quiz.Handler{
+		Questions: questions,
+		Sessions:  sessions,
+		Progress:  progress,
+
+		Entitlements: entitlements.NewService(quotas, entitlements.DefaultPlan()),
+		Scope:        classrooms,
+		Catalog:      topics,
+		Experiment:   selection,
+		Pedagogy:     pedagogy,
+		Estimator:    estimator,
+		Ratings:      ratings,
+		Exposures:    exposures,
+	}
+
+	adminQuestions := &quiz.AdminHandler{
+		Questions: questions,
+		Topics:    topics,
+		Pedagogy:  pedagogy,
+		Progress:  progress,
+	}
+
 	r := gin.Default()
 	r.SetTrustedProxies(nil)
 
 	// ✅ Allow frontend requests
-	r.Use(cors.Default())
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AllowAllOrigins = true
+	corsConfig.AddAllowHeaders("Authorization")
+	r.Use(cors.New(corsConfig))
+
+	var errorRules []httperr.Rule
+	errorRules = append(errorRules, quiz.HTTPErrors...)
+	errorRules = append(errorRules, auth.HTTPErrors...)
+	errorRules = append(errorRules, entitlements.HTTPErrors...)
+	errorRules = append(errorRules, classroom.HTTPErrors...)
+	r.Use(httperr.Middleware(errorRules...))
+
+	r.POST("/auth/register", authHandler.Register)
+	r.POST("/auth/login", authHandler.Login)
+	if authHandler.OTP != nil {
+		r.POST("/auth/otp/request", authHandler.RequestOTP)
+		r.POST("/auth/otp/verify", authHandler.VerifyOTP)
+	}
+
+	quizRoutes := r.Group("/quiz", requireUser, auth.RequireArea(auth.AreaLearner))
+	quizRoutes.POST("/start", quizHandler.StartQuiz)
+	quizRoutes.POST("/answer", quizHandler.AnswerQuiz)
+
+	classroomRoutes := r.Group("/classrooms", requireUser, auth.RequireArea(auth.AreaLearner))
+	classroomRoutes.GET("", classroomHandler.MyClassrooms)
+	classroomRoutes.POST("/join", classroomHandler.Join)
+	classroomRoutes.GET("/invites", classroomHandler.MyInvites)
+	classroomRoutes.POST("/invites/:id/accept", classroomHandler.AcceptInvite)
+
+	teacherRoutes := r.Group("/teacher", requireUser, auth.RequireArea(auth.AreaTeacher))
+	teacherRoutes.POST("/classrooms", classroomHandler.CreateClassroom)
+	teacherRoutes.GET("/classrooms", classroomHandler.ListClassrooms)
+	teacherRoutes.GET("/classrooms/:id/members", classroomHandler.ListMembers)
+	teacherRoutes.POST("/classrooms/:id/invites", classroomHandler.Invite)
+	teacherRoutes.POST("/classrooms/:id/assignments", classroomHandler.Assign)
+	teacherRoutes.GET("/classrooms/:id/assignments", classroomHandler.ListAssignments)
+	teacherRoutes.GET("/classrooms/:id/assignments/:assignmentID/completion", classroomHandler.Completion)
+	teacherRoutes.GET("/classrooms/:id/report", classroomHandler.ClassReport)
+	teacherRoutes.GET("/classrooms/:id/students/:studentID", classroomHandler.StudentReport)
 
-	r.POST("/quiz/start", quiz.StartQuiz)
-	r.POST("/quiz/answer", quiz.AnswerQuiz)
+	adminRoutes := r.Group("/admin", requireUser, auth.RequireArea(auth.AreaAdmin))
+	adminRoutes.GET("/questions", adminQuestions.ListQuestions)
+	adminRoutes.POST("/questions", adminQuestions.CreateQuestion)
+	adminRoutes.GET("/questions/:id", adminQuestions.GetQuestion)
+	adminRoutes.PUT("/questions/:id", adminQuestions.UpdateQuestion)
+	adminRoutes.DELETE("/questions/:id", adminQuestions.RetireQuestion)
+	adminRoutes.GET("/config", adminQuestions.GetConfig)
+	adminRoutes.GET("/calibration", adminQuestions.ListCalibration)
+	adminRoutes.POST("/calibration/:id/accept", adminQuestions.AcceptCalibration)
 
 	r.Run(":8080")
 }
+
+// tokenSecret reads BONFIRE_TOKEN_SECRET. Without it a random secret is
+// used, so tokens stop working after a restart.
+func tokenSecret() []byte {
+	if secret := os.Getenv("BONFIRE_TOKEN_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+
+	log.Println("BONFIRE_TOKEN_SECRET not set; using a random secret")
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatalf("generate token secret: %v", err)
+	}
+	return secret
+}
+
+// masteryEstimator picks the estimator named by BONFIRE_ESTIMATOR:
+// "bkt" or "elo", or none, meaning the pedagogy config's fixed deltas,
+// when it is empty. The Elo estimator starts from the saved ratings.
+func masteryEstimator(name string, pedagogy *quiz.PedagogyStore, ratings quiz.QuestionRatingRepository) (quiz.MasteryEstimator, error) {
+	switch name {
+	case "":
+		return nil, nil
+	case "bkt":
+		bkt := quiz.DefaultBKTEstimator()
+		bkt.Pedagogy = pedagogy
+		return bkt, nil
+	case "elo":
+		saved, err := ratings.ListRatings(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		elo := quiz.NewEloEstimator()
+		elo.Pedagogy = pedagogy
+		elo.Restore(saved)
+		return elo, nil
+	default:
+		return nil, fmt.Errorf("unknown estimator %q", name)
+	}
+}
+
+// smsSender picks the SMS provider. Only the logging sender exists so
+// far, and it prints one-time codes in clear text, so it is used only
+// when BONFIRE_DEV_SMS=log asks for it. ok is false otherwise, and
+// one-time code login is disabled.
+func smsSender() (sender auth.SMSSender, ok bool) {
+	if os.Getenv("BONFIRE_DEV_SMS") != "log" {
+		log.Println("SMS provider not configured; one-time code login is disabled")
+		return nil, false
+	}
+	log.Println("BONFIRE_DEV_SMS=log: one-time codes are logged, for development only")
+	return auth.LogSMSSender{}, true
+}